@@ -0,0 +1,1176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"go-cli-eth/database"
+	"go-cli-eth/ethereum"
+	"go-cli-eth/indexer"
+	"go-cli-eth/metadata"
+	"go-cli-eth/models"
+	"go-cli-eth/services"
+)
+
+// Exit codes returned by the CLI
+const (
+	exitOK       = 0
+	exitFailure  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+// command is a CLI subcommand such as "owner get"
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// commands lists every subcommand in the order they are shown in the usage text
+var commands = []command{
+	{name: "owner get", summary: "Fetch an NFT owner from the chain and store it", run: runOwnerGet},
+	{name: "owner update", summary: "Refresh the owner of a stored NFT from the chain", run: runOwnerUpdate},
+	{name: "owner refresh", summary: "Refresh owners of a token range or a stored collection in batches", run: runOwnerRefresh},
+	{name: "nft show", summary: "Show a stored NFT", run: runNFTShow},
+	{name: "nft list", summary: "List stored NFTs", run: runNFTList},
+	{name: "nft history", summary: "Show the recorded ownership history of an NFT", run: runNFTHistory},
+	{name: "nft metadata", summary: "Fetch and show the tokenURI metadata of an NFT", run: runNFTMetadata},
+	{name: "balance get", summary: "Fetch an ERC-1155 balance from the chain and store it", run: runBalanceGet},
+	{name: "balance list", summary: "List stored ERC-1155 balances", run: runBalanceList},
+	{name: "balance refresh", summary: "Refresh the stored holders of an ERC-1155 token, discovering new ones from logs", run: runBalanceRefresh},
+	{name: "collection register", summary: "Register a contract as a collection and read its name, symbol and supply", run: runCollectionRegister},
+	{name: "collection list", summary: "List registered collections with tracked token counts", run: runCollectionList},
+	{name: "collection show", summary: "Show a registered collection", run: runCollectionShow},
+	{name: "collection royalties", summary: "Report the ERC-2981 royalty configuration of a registered collection", run: runCollectionRoyalties},
+	{name: "contract show", summary: "Show the ERC-165 interfaces and token standard of a contract", run: runContractShow},
+	{name: "chain list", summary: "List the configured chains", run: runChainList},
+	{name: "rpc status", summary: "Check the RPC endpoints of the configured chains and show their health", run: runRPCStatus},
+	{name: "index", summary: "Index ERC-721 Transfer logs of a contract", run: runIndex},
+	{name: "watch", summary: "Follow Transfer events live and keep owners current", run: runWatch},
+	{name: "serve", summary: "Run the REST API server", run: runServe},
+	{name: "interactive", summary: "Run the interactive menu", run: runInteractive},
+}
+
+// usageError reports invalid command line usage and maps to exitUsage
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+// usageErrorf creates a usageError with a formatted message
+func usageErrorf(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// run dispatches args to the matching subcommand and returns the exit code
+func run(args []string) int {
+	cmd, rest := findCommand(args)
+	if cmd == nil {
+		printUsage()
+		if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			return exitOK
+		}
+		fmt.Fprintf(os.Stderr, "\nunknown command %q\n", strings.Join(args, " "))
+		return exitUsage
+	}
+
+	// Commands stop at the next cancellation point on SIGINT or SIGTERM,
+	// e.g. after the current index batch or before the next RPC request
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := cmd.run(ctx, rest)
+	if err == nil {
+		return exitOK
+	}
+
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if ctx.Err() != nil && errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "Interrupted")
+		return exitFailure
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) || errors.Is(err, services.ErrInvalidAddress) || errors.Is(err, services.ErrInvalidTokenRange) {
+		fmt.Fprintf(os.Stderr, "Run '%s %s -h' for usage.\n", os.Args[0], cmd.name)
+		return exitUsage
+	}
+	if isNotFound(err) {
+		return exitNotFound
+	}
+	return exitFailure
+}
+
+// isNotFound reports whether err means that the requested NFT, history entry
+// or token does not exist
+func isNotFound(err error) bool {
+	return errors.Is(err, services.ErrNotFound) ||
+		errors.Is(err, services.ErrTokenBurned) ||
+		ethereum.IsNonexistentToken(err)
+}
+
+// findCommand returns the command named by the leading words of args and the
+// remaining arguments
+func findCommand(args []string) (*command, []string) {
+	for i := range commands {
+		words := strings.Fields(commands[i].name)
+		if len(args) < len(words) {
+			continue
+		}
+		if strings.Join(args[:len(words)], " ") == commands[i].name {
+			return &commands[i], args[len(words):]
+		}
+	}
+	return nil, nil
+}
+
+// printUsage prints the list of available commands
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for the flags of a command.\n", os.Args[0])
+}
+
+// connectionFlags holds the flags shared by every command that talks to the
+// database and the chain
+type connectionFlags struct {
+	dbURL      string
+	rpcURL     string
+	chainsPath string
+	chain      string
+	output     string
+
+	rpcTimeout time.Duration
+	dbTimeout  time.Duration
+
+	// rpcRetries is how often a request that failed on every endpoint is
+	// retried, and rpcRateLimit the requests per second sent to each
+	// endpoint without a rate limit in the chains file, zero for no limit
+	rpcRetries   int
+	rpcRateLimit float64
+
+	// healthInterval is how often RPC endpoints are probed in the
+	// background, zero disabling the background checks
+	healthInterval time.Duration
+}
+
+// newFlagSet creates a flag set for a subcommand with the shared connection
+// and output flags registered
+func newFlagSet(name string) (*flag.FlagSet, *connectionFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	conn := &connectionFlags{}
+	fs.StringVar(&conn.dbURL, "db", os.Getenv("DATABASE_URL"), "PostgreSQL connection string (env DATABASE_URL)")
+	fs.StringVar(&conn.rpcURL, "rpc", defaultRPCURL, "Ethereum RPC URL used without a chains file (env ETH_RPC_URL)")
+	fs.StringVar(&conn.chainsPath, "chains", os.Getenv("CHAINS_CONFIG"), "chains file with the RPC endpoints of every chain, replacing -rpc (env CHAINS_CONFIG)")
+	fs.StringVar(&conn.chain, "chain", os.Getenv("CHAIN"), "chain ID or name to use (env CHAIN, default the first chain of the chains file)")
+	fs.StringVar(&conn.output, "output", outputTable, "output format: json, table or csv")
+	fs.DurationVar(&conn.rpcTimeout, "rpc-timeout", envDuration("RPC_TIMEOUT", ethereum.DefaultRPCTimeout), "timeout of a single RPC request before it fails over to the next endpoint (env RPC_TIMEOUT)")
+	fs.IntVar(&conn.rpcRetries, "rpc-retries", envInt("RPC_RETRIES", ethereum.DefaultRetryPolicy.MaxRetries), "retries of an RPC request that failed on every endpoint, with exponential backoff (env RPC_RETRIES)")
+	fs.Float64Var(&conn.rpcRateLimit, "rpc-rate-limit", envFloat("RPC_RATE_LIMIT", 0), "requests per second sent to each RPC endpoint without a rate limit in the chains file, 0 for no limit (env RPC_RATE_LIMIT)")
+	fs.DurationVar(&conn.dbTimeout, "db-timeout", envDuration("DB_TIMEOUT", database.DefaultQueryTimeout), "timeout of a single database statement, 0 to disable (env DB_TIMEOUT)")
+	return fs, conn
+}
+
+// parseFlags parses args and validates the shared flags
+func parseFlags(fs *flag.FlagSet, conn *connectionFlags, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{msg: err.Error()}
+	}
+	if fs.NArg() > 0 {
+		return usageErrorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if !isOutputFormat(conn.output) {
+		return usageErrorf("invalid output format %q: must be json, table or csv", conn.output)
+	}
+	if conn.rpcRetries < 0 {
+		return usageErrorf("invalid -rpc-retries %d: must not be negative", conn.rpcRetries)
+	}
+	if conn.rpcRateLimit < 0 {
+		return usageErrorf("invalid -rpc-rate-limit %v: must not be negative", conn.rpcRateLimit)
+	}
+	return nil
+}
+
+// chainRegistry loads the chains file, or returns nil if none is configured
+func (conn *connectionFlags) chainRegistry() (*ethereum.ChainRegistry, error) {
+	if conn.chainsPath == "" {
+		return nil, nil
+	}
+	return ethereum.LoadChainRegistry(conn.chainsPath)
+}
+
+// initDB connects to the database with the configured statement timeout and
+// returns a repository storing NFTs in it
+func (conn *connectionFlags) initDB(ctx context.Context) (*database.Repository, error) {
+	repo, err := database.InitDB(ctx, conn.dbURL, conn.dbTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %v", err)
+	}
+	return repo, nil
+}
+
+// dial creates the client pool of the configured chains, or of the chain -rpc
+// serves without a chains file, and returns it along with the client of the
+// selected chain
+func (conn *connectionFlags) dial(ctx context.Context) (*ethereum.ClientPool, *ethereum.EthereumClient, error) {
+	registry, err := conn.chainRegistry()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pool *ethereum.ClientPool
+	if registry != nil {
+		pool = ethereum.NewClientPool(registry)
+	} else if pool, err = ethereum.DialSingleChain(ctx, conn.rpcURL); err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize Ethereum client: %v", err)
+	}
+	pool.SetRPCTimeout(conn.rpcTimeout)
+	pool.SetRetryPolicy(conn.retryPolicy())
+	pool.SetRateLimit(ethereum.RateLimit{RequestsPerSecond: conn.rpcRateLimit})
+	pool.SetHealthCheckInterval(conn.healthInterval)
+
+	ethClient, err := pool.Client(ctx, conn.chain)
+	if err != nil {
+		pool.Close()
+		if errors.Is(err, ethereum.ErrUnknownChain) {
+			return nil, nil, &usageError{msg: err.Error()}
+		}
+		return nil, nil, fmt.Errorf("failed to initialize Ethereum client: %v", err)
+	}
+	return pool, ethClient, nil
+}
+
+// retryPolicy returns the default retry policy with the configured number of
+// retries
+func (conn *connectionFlags) retryPolicy() ethereum.RetryPolicy {
+	policy := ethereum.DefaultRetryPolicy
+	policy.MaxRetries = conn.rpcRetries
+	return policy
+}
+
+// connect initializes the database and Ethereum clients and returns an NFT
+// service for the selected chain along with a function that releases both
+func (conn *connectionFlags) connect(ctx context.Context) (*services.NFTService, func(), error) {
+	repo, err := conn.initDB(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pool, ethClient, err := conn.dial(ctx)
+	if err != nil {
+		repo.Close()
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		pool.Close()
+		repo.Close()
+	}
+
+	nftService := services.NewNFTService(repo, ethClient)
+	nftService.SetClientPool(pool)
+	nftService.SetENSCacheTTL(envDuration("ENS_CACHE_TTL", services.DefaultENSCacheTTL))
+	nftService.SetMetadataFetcher(metadata.NewFetcher(metadata.Config{
+		IPFSGateway:           os.Getenv("IPFS_GATEWAY"),
+		ArweaveGateway:        os.Getenv("ARWEAVE_GATEWAY"),
+		Timeout:               envDuration("METADATA_TIMEOUT", metadata.DefaultTimeout),
+		AllowPrivateAddresses: envBool("METADATA_ALLOW_PRIVATE", false),
+	}))
+	return nftService, cleanup, nil
+}
+
+// confirmations returns the value of a -confirmations flag if it was set,
+// otherwise the confirmation depth of the chain if it has one
+func confirmations(fs *flag.FlagSet, value uint64, chain ethereum.ChainConfig) uint64 {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		set = set || f.Name == "confirmations"
+	})
+	if !set && chain.Confirmations > 0 {
+		return chain.Confirmations
+	}
+	return value
+}
+
+// envInt returns the integer value of an environment variable, or def if it
+// is unset or invalid
+func envInt(key string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
+
+// envFloat returns the float value of an environment variable, or def if it
+// is unset or invalid
+func envFloat(key string, def float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return v
+	}
+	return def
+}
+
+// envBool returns the boolean value of an environment variable, or def if it
+// is unset or invalid
+func envBool(key string, def bool) bool {
+	if v, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
+
+// envDuration returns the duration value of an environment variable, or def
+// if it is unset or invalid
+func envDuration(key string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return v
+	}
+	return def
+}
+
+// tokenFlags holds the flags identifying a single NFT
+type tokenFlags struct {
+	contract string
+	tokenID  string
+}
+
+// blockFlag registers the -block flag used to pin chain reads
+func blockFlag(fs *flag.FlagSet) *string {
+	return fs.String("block", "", "block number, block hash, latest, safe or finalized (default latest)")
+}
+
+// parseBlock parses the value of the -block flag
+func parseBlock(value string) (ethereum.BlockRef, error) {
+	block, err := ethereum.ParseBlockRef(value)
+	if err != nil {
+		return ethereum.BlockRef{}, &usageError{msg: err.Error()}
+	}
+	return block, nil
+}
+
+// parseAddress validates the address passed to flag name and returns it in
+// checksummed form. Mixed-case addresses whose casing does not match their
+// EIP-55 checksum are accepted with a warning since they are likely mistyped.
+func parseAddress(name, value string) (string, error) {
+	address, err := ethereum.ParseAddress(value)
+	if err != nil {
+		return "", usageErrorf("-%s: %v", name, err)
+	}
+	if !ethereum.HasValidChecksum(value) {
+		fmt.Fprintf(os.Stderr, "Warning: -%s %s does not match its EIP-55 checksum %s\n", name, strings.TrimSpace(value), address.Hex())
+	}
+	return address.Hex(), nil
+}
+
+// parseContract validates a contract flag that accepts a hex address or an
+// ENS name. Addresses are returned checksummed and names normalized; names
+// are resolved by the service.
+func parseContract(name, value string) (string, error) {
+	if ethereum.IsENSName(value) {
+		normalized, err := ethereum.NormalizeENSName(value)
+		if err != nil {
+			return "", usageErrorf("-%s: %v", name, err)
+		}
+		return normalized, nil
+	}
+	return parseAddress(name, value)
+}
+
+// register adds the contract and token flags to fs
+func (t *tokenFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&t.contract, "contract", "", "NFT contract address or ENS name (required)")
+	fs.StringVar(&t.tokenID, "token", "", "token ID in decimal or 0x hex (required)")
+}
+
+// parse validates the flags and returns the parsed token ID
+func (t *tokenFlags) parse() (models.TokenID, error) {
+	if t.contract == "" {
+		return models.TokenID{}, usageErrorf("-contract is required")
+	}
+	if t.tokenID == "" {
+		return models.TokenID{}, usageErrorf("-token is required")
+	}
+
+	contract, err := parseContract("contract", t.contract)
+	if err != nil {
+		return models.TokenID{}, err
+	}
+	t.contract = contract
+
+	tokenID, err := models.ParseTokenID(t.tokenID)
+	if err != nil {
+		return models.TokenID{}, &usageError{msg: err.Error()}
+	}
+	return tokenID, nil
+}
+
+// runOwnerGet implements "owner get"
+func runOwnerGet(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("owner get")
+	var token tokenFlags
+	token.register(fs)
+	blockValue := blockFlag(fs)
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	tokenID, err := token.parse()
+	if err != nil {
+		return err
+	}
+	block, err := parseBlock(*blockValue)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	nft, err := nftService.GetAndStoreOwner(ctx, token.contract, tokenID, block)
+	if err != nil {
+		return err
+	}
+	return printNFTs(os.Stdout, conn.output, []models.NFT{*nft})
+}
+
+// runOwnerUpdate implements "owner update"
+func runOwnerUpdate(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("owner update")
+	var token tokenFlags
+	token.register(fs)
+	blockValue := blockFlag(fs)
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	tokenID, err := token.parse()
+	if err != nil {
+		return err
+	}
+	block, err := parseBlock(*blockValue)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	nft, err := nftService.UpdateOwner(ctx, token.contract, tokenID, block)
+	if err != nil {
+		return err
+	}
+	return printNFTs(os.Stdout, conn.output, []models.NFT{*nft})
+}
+
+// runNFTShow implements "nft show"
+func runNFTShow(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("nft show")
+	var token tokenFlags
+	token.register(fs)
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	tokenID, err := token.parse()
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	nft, err := nftService.GetNFTByTokenID(ctx, token.contract, tokenID)
+	if err != nil {
+		return err
+	}
+	return printNFTs(os.Stdout, conn.output, []models.NFT{*nft})
+}
+
+// runNFTList implements "nft list"
+func runNFTList(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("nft list")
+	contract := fs.String("contract", "", "only list NFTs of this contract (address or ENS name)")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract != "" {
+		checksummed, err := parseContract("contract", *contract)
+		if err != nil {
+			return err
+		}
+		*contract = checksummed
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	nfts, err := nftService.GetAllNFTs(ctx, *contract)
+	if err != nil {
+		return err
+	}
+	return printNFTs(os.Stdout, conn.output, nfts)
+}
+
+// runNFTHistory implements "nft history"
+func runNFTHistory(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("nft history")
+	var token tokenFlags
+	token.register(fs)
+	at := fs.String("at", "", "only show the owner at this time (RFC 3339 or YYYY-MM-DD)")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	tokenID, err := token.parse()
+	if err != nil {
+		return err
+	}
+
+	var atTime time.Time
+	if *at != "" {
+		atTime, err = parseTime(*at)
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if *at != "" {
+		entry, err := nftService.GetOwnerAt(ctx, token.contract, tokenID, atTime)
+		if err != nil {
+			return err
+		}
+		return printHistory(os.Stdout, conn.output, []models.OwnershipHistory{*entry})
+	}
+
+	history, err := nftService.GetOwnershipHistory(ctx, token.contract, tokenID)
+	if err != nil {
+		return err
+	}
+	return printHistory(os.Stdout, conn.output, history)
+}
+
+// runNFTMetadata implements "nft metadata"
+func runNFTMetadata(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("nft metadata")
+	var token tokenFlags
+	token.register(fs)
+	refresh := fs.Bool("refresh", false, "fetch the metadata again instead of using the stored one")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	tokenID, err := token.parse()
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	meta, err := nftService.GetMetadata(ctx, token.contract, tokenID, *refresh)
+	if err != nil {
+		return err
+	}
+	return printMetadata(os.Stdout, conn.output, meta)
+}
+
+// runCollectionRegister implements "collection register"
+func runCollectionRegister(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("collection register")
+	contract := fs.String("contract", "", "NFT contract address or ENS name (required)")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract == "" {
+		return usageErrorf("-contract is required")
+	}
+	contractAddress, err := parseContract("contract", *contract)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	collection, err := nftService.RegisterCollection(ctx, contractAddress)
+	if err != nil {
+		return err
+	}
+	return printCollections(os.Stdout, conn.output, []models.Collection{*collection})
+}
+
+// runCollectionList implements "collection list"
+func runCollectionList(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("collection list")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	collections, err := nftService.GetCollections(ctx)
+	if err != nil {
+		return err
+	}
+	return printCollections(os.Stdout, conn.output, collections)
+}
+
+// runCollectionShow implements "collection show"
+func runCollectionShow(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("collection show")
+	contract := fs.String("contract", "", "NFT contract address or ENS name (required)")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract == "" {
+		return usageErrorf("-contract is required")
+	}
+	contractAddress, err := parseContract("contract", *contract)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	collection, err := nftService.GetCollection(ctx, contractAddress)
+	if err != nil {
+		return err
+	}
+	return printCollections(os.Stdout, conn.output, []models.Collection{*collection})
+}
+
+// runCollectionRoyalties implements "collection royalties"
+func runCollectionRoyalties(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("collection royalties")
+	contract := fs.String("contract", "", "NFT contract address or ENS name (required)")
+	refresh := fs.Bool("refresh", false, "read royaltyInfo for every tracked token instead of using the stored royalties")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract == "" {
+		return usageErrorf("-contract is required")
+	}
+	contractAddress, err := parseContract("contract", *contract)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	report, err := nftService.GetRoyalties(ctx, contractAddress, *refresh)
+	if err != nil {
+		return err
+	}
+	return printRoyaltyReport(os.Stdout, conn.output, report)
+}
+
+// runContractShow implements "contract show"
+func runContractShow(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("contract show")
+	contract := fs.String("contract", "", "contract address or ENS name (required)")
+	refresh := fs.Bool("refresh", false, "probe the interfaces again instead of using the stored ones")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract == "" {
+		return usageErrorf("-contract is required")
+	}
+	contractAddress, err := parseContract("contract", *contract)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	info, err := nftService.InspectContract(ctx, contractAddress, *refresh)
+	if err != nil {
+		return err
+	}
+	return printContract(os.Stdout, conn.output, info)
+}
+
+// runChainList implements "chain list". The chain selected by -chain is
+// marked as the default. Without a chains file the chain -rpc serves is
+// dialed to learn its ID.
+func runChainList(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("chain list")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+
+	registry, err := conn.chainRegistry()
+	if err != nil {
+		return err
+	}
+	if registry == nil {
+		pool, _, err := conn.dial(ctx)
+		if err != nil {
+			return err
+		}
+		defer pool.Close()
+		registry = pool.Registry()
+	}
+
+	selected, err := registry.Lookup(conn.chain)
+	if err != nil {
+		return &usageError{msg: err.Error()}
+	}
+	return printChains(os.Stdout, conn.output, registry.Chains(), selected.ID)
+}
+
+// runRPCStatus implements "rpc status". Every endpoint of the chain selected
+// by -chain, or of every configured chain if -chain is not set, is probed
+// once. The command fails if a chain has no healthy endpoint.
+func runRPCStatus(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("rpc status")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+
+	pool, _, err := conn.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	chains := pool.Registry().Chains()
+	if conn.chain != "" {
+		selected, err := pool.Registry().Lookup(conn.chain)
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		chains = []ethereum.ChainConfig{selected}
+	}
+
+	var statuses []ethereum.ChainStatus
+	var unhealthy []string
+	for _, chain := range chains {
+		client, err := pool.Client(ctx, chain.Name)
+		if err != nil {
+			return err
+		}
+		client.CheckHealth(ctx)
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		status := ethereum.ChainStatus{
+			Chain:     chain,
+			Connected: true,
+			Healthy:   client.Healthy(),
+			Endpoints: client.EndpointStats(),
+		}
+		if !status.Healthy {
+			unhealthy = append(unhealthy, chain.Name)
+		}
+		statuses = append(statuses, status)
+	}
+
+	if err := printRPCStatus(os.Stdout, conn.output, statuses); err != nil {
+		return err
+	}
+	if len(unhealthy) > 0 {
+		return fmt.Errorf("no healthy RPC endpoint for %s", strings.Join(unhealthy, ", "))
+	}
+	return nil
+}
+
+// runBalanceGet implements "balance get"
+func runBalanceGet(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("balance get")
+	var token tokenFlags
+	token.register(fs)
+	holder := fs.String("holder", "", "holder address or ENS name (required)")
+	blockValue := blockFlag(fs)
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	tokenID, err := token.parse()
+	if err != nil {
+		return err
+	}
+	if *holder == "" {
+		return usageErrorf("-holder is required")
+	}
+	holderAddress, err := parseContract("holder", *holder)
+	if err != nil {
+		return err
+	}
+	block, err := parseBlock(*blockValue)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	balance, err := nftService.GetAndStoreBalance(ctx, token.contract, tokenID, holderAddress, block)
+	if err != nil {
+		return err
+	}
+	return printBalances(os.Stdout, conn.output, []models.TokenBalance{*balance})
+}
+
+// runBalanceList implements "balance list"
+func runBalanceList(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("balance list")
+	contract := fs.String("contract", "", "ERC-1155 contract address or ENS name (required)")
+	token := fs.String("token", "", "only list balances of this token ID")
+	holder := fs.String("holder", "", "only list balances of this holder (address or ENS name)")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract == "" {
+		return usageErrorf("-contract is required")
+	}
+	contractAddress, err := parseContract("contract", *contract)
+	if err != nil {
+		return err
+	}
+	var tokenID *models.TokenID
+	if *token != "" {
+		parsed, err := models.ParseTokenID(*token)
+		if err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		tokenID = &parsed
+	}
+	if *holder != "" {
+		if *holder, err = parseContract("holder", *holder); err != nil {
+			return err
+		}
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	balances, err := nftService.GetBalances(ctx, contractAddress, tokenID, *holder)
+	if err != nil {
+		return err
+	}
+	return printBalances(os.Stdout, conn.output, balances)
+}
+
+// runBalanceRefresh implements "balance refresh"
+func runBalanceRefresh(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("balance refresh")
+	var token tokenFlags
+	token.register(fs)
+	fromBlock := fs.Uint64("from-block", 0, "discover holders from TransferSingle and TransferBatch logs since this block (default only stored holders)")
+	batchSize := fs.Int("batch-size", ethereum.DefaultBalanceBatchSize, "number of holders per balanceOfBatch call")
+	blockValue := blockFlag(fs)
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	tokenID, err := token.parse()
+	if err != nil {
+		return err
+	}
+	block, err := parseBlock(*blockValue)
+	if err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	result, err := nftService.RefreshBalances(ctx, token.contract, tokenID, *fromBlock, block, *batchSize)
+	if err != nil {
+		return err
+	}
+	return printBalanceRefreshResult(os.Stdout, conn.output, result)
+}
+
+// parseTime parses an RFC 3339 timestamp or a YYYY-MM-DD date
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 or YYYY-MM-DD", value)
+	}
+	// a bare date means the end of that day
+	return t.Add(24*time.Hour - time.Nanosecond), nil
+}
+
+// runIndex implements "index"
+func runIndex(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("index")
+	contract := fs.String("contract", "", "NFT contract address or ENS name (required)")
+	startBlock := fs.Uint64("start-block", 0, "first block to scan when the contract has no checkpoint yet")
+	toBlock := fs.Uint64("to-block", 0, "last block to scan (default chain head minus confirmations)")
+	batchSize := fs.Uint64("batch-size", indexer.DefaultBatchSize, "number of blocks per log query")
+	confirmationsValue := fs.Uint64("confirmations", indexer.DefaultConfirmations, "number of blocks to stay behind the chain head (default the confirmations of the chain if set)")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract == "" {
+		return usageErrorf("-contract is required")
+	}
+	contractAddress, err := parseContract("contract", *contract)
+	if err != nil {
+		return err
+	}
+
+	repo, err := conn.initDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	pool, ethClient, err := conn.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	if ethereum.IsENSName(contractAddress) {
+		resolved, err := ethClient.ResolveName(ctx, contractAddress)
+		if err != nil {
+			return err
+		}
+		contractAddress = resolved.Hex()
+	}
+
+	ix, err := indexer.NewIndexer(repo.DB(), ethClient, indexer.Config{
+		ContractAddress: contractAddress,
+		StartBlock:      *startBlock,
+		BatchSize:       *batchSize,
+		Confirmations:   confirmations(fs, *confirmationsValue, ethClient.Chain()),
+	})
+	if err != nil {
+		return err
+	}
+
+	var stats indexer.Stats
+	if *toBlock == 0 {
+		stats, err = ix.SyncToHead(ctx)
+	} else {
+		stats, err = ix.Sync(ctx, *toBlock)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printIndexStats(os.Stdout, conn.output, stats)
+}
+
+// runWatch implements "watch"
+func runWatch(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("watch")
+	wsURL := fs.String("ws", os.Getenv("ETH_WS_URL"), "websocket RPC URL used for the subscription (env ETH_WS_URL, default a websocket endpoint of the chain or -rpc)")
+	contracts := fs.String("contracts", "", "comma separated contract addresses (default every tracked contract)")
+	startBlock := fs.Uint64("start-block", 0, "first block to backfill for contracts without a checkpoint (required if any)")
+	batchSize := fs.Uint64("batch-size", indexer.DefaultBatchSize, "number of blocks per backfill log query")
+	confirmationsValue := fs.Uint64("confirmations", indexer.DefaultConfirmations, "number of blocks to stay behind the chain head (default the confirmations of the chain if set)")
+	pollInterval := fs.Duration("poll-interval", indexer.DefaultPollInterval, "how often to index newly confirmed blocks without new logs")
+	maxBackoff := fs.Duration("max-backoff", indexer.DefaultMaxBackoff, "maximum delay between reconnect attempts")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+
+	var contractList []string
+	for _, contract := range strings.Split(*contracts, ",") {
+		if contract = strings.TrimSpace(contract); contract == "" {
+			continue
+		}
+		checksummed, err := parseAddress("contracts", contract)
+		if err != nil {
+			return err
+		}
+		contractList = append(contractList, checksummed)
+	}
+
+	registry, err := conn.chainRegistry()
+	if err != nil {
+		return err
+	}
+	var chain ethereum.ChainConfig
+	if registry != nil {
+		if chain, err = registry.Lookup(conn.chain); err != nil {
+			return &usageError{msg: err.Error()}
+		}
+	}
+	if *wsURL == "" {
+		*wsURL = subscriptionURL(chain, conn.rpcURL)
+	}
+
+	repo, err := conn.initDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+
+	rateLimit, ok := chain.RateLimit(*wsURL)
+	if !ok {
+		rateLimit = ethereum.RateLimit{RequestsPerSecond: conn.rpcRateLimit}
+	}
+
+	watcher := indexer.NewWatcher(repo.DB(), indexer.WatcherConfig{
+		RPCURL:        *wsURL,
+		ChainID:       chain.ID,
+		RPCTimeout:    conn.rpcTimeout,
+		RetryPolicy:   conn.retryPolicy(),
+		RateLimit:     rateLimit,
+		Contracts:     contractList,
+		StartBlock:    *startBlock,
+		BatchSize:     *batchSize,
+		Confirmations: confirmations(fs, *confirmationsValue, chain),
+		PollInterval:  *pollInterval,
+		MaxBackoff:    *maxBackoff,
+	})
+	return watcher.Run(ctx)
+}
+
+// subscriptionURL returns the first websocket endpoint of a configured chain,
+// falling back to its first endpoint, or rpcURL without a chains file
+func subscriptionURL(chain ethereum.ChainConfig, rpcURL string) string {
+	for _, endpoint := range chain.RPCURLs {
+		if strings.HasPrefix(endpoint, "ws://") || strings.HasPrefix(endpoint, "wss://") {
+			return endpoint
+		}
+	}
+	if len(chain.RPCURLs) > 0 {
+		return chain.RPCURLs[0]
+	}
+	return rpcURL
+}
+
+// runOwnerRefresh implements "owner refresh"
+func runOwnerRefresh(ctx context.Context, args []string) error {
+	fs, conn := newFlagSet("owner refresh")
+	contract := fs.String("contract", "", "NFT contract address or ENS name (required)")
+	from := fs.String("from", "", "first token ID of the range to refresh")
+	to := fs.String("to", "", "last token ID of the range to refresh")
+	all := fs.Bool("all", false, "refresh every stored token of the contract instead of a range")
+	batchSize := fs.Int("batch-size", ethereum.DefaultOwnerBatchSize, "number of ownerOf calls per multicall")
+	multicall := fs.String("multicall", os.Getenv("MULTICALL_ADDRESS"), "Multicall3 address (env MULTICALL_ADDRESS, default the multicall_address of the chain or "+ethereum.DefaultMulticallAddress+")")
+	rpcBatchLimit := fs.Int("rpc-batch-limit", envInt("RPC_BATCH_LIMIT", ethereum.DefaultRPCBatchLimit), "maximum requests per JSON-RPC batch when Multicall3 is unavailable (env RPC_BATCH_LIMIT)")
+	blockValue := blockFlag(fs)
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+	if *contract == "" {
+		return usageErrorf("-contract is required")
+	}
+	contractAddress, err := parseContract("contract", *contract)
+	if err != nil {
+		return err
+	}
+	if *multicall != "" {
+		if *multicall, err = parseAddress("multicall", *multicall); err != nil {
+			return err
+		}
+	}
+	if *all == (*from != "" || *to != "") {
+		return usageErrorf("either -all or -from and -to are required")
+	}
+	block, err := parseBlock(*blockValue)
+	if err != nil {
+		return err
+	}
+
+	var fromID, toID models.TokenID
+	if !*all {
+		if *from == "" || *to == "" {
+			return usageErrorf("-from and -to are both required")
+		}
+		if fromID, err = models.ParseTokenID(*from); err != nil {
+			return &usageError{msg: err.Error()}
+		}
+		if toID, err = models.ParseTokenID(*to); err != nil {
+			return &usageError{msg: err.Error()}
+		}
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if *multicall != "" {
+		if err := nftService.SetMulticallAddress(*multicall); err != nil {
+			return err
+		}
+	}
+	nftService.SetRPCBatchLimit(*rpcBatchLimit)
+
+	var result *services.RefreshResult
+	if *all {
+		result, err = nftService.RefreshCollection(ctx, contractAddress, block, *batchSize)
+	} else {
+		result, err = nftService.RefreshRange(ctx, contractAddress, fromID, toID, block, *batchSize)
+	}
+	if err != nil {
+		return err
+	}
+
+	return printRefreshResult(os.Stdout, conn.output, result)
+}
@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"go-cli-eth/models"
 
@@ -12,42 +14,118 @@ import (
 	"gorm.io/gorm/logger"
 )
 
-var DB *gorm.DB
-
-// InitDB initializes the database connection
-func InitDB(connectionString string) error {
-	var err error
+// InitDB opens the database (see Open) and returns a repository storing NFTs
+// in it. The repository owns the connection pool; close it with
+// Repository.Close.
+func InitDB(ctx context.Context, connectionString string, queryTimeout time.Duration) (*Repository, error) {
+	db, err := Open(ctx, connectionString, queryTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return NewRepository(db), nil
+}
 
+// Open connects to a PostgreSQL database and migrates its schema. ctx bounds
+// connecting and migrating; the connection check is also bounded by
+// queryTimeout, as is every statement run afterwards. A zero queryTimeout
+// leaves statements bounded only by their context.
+func Open(ctx context.Context, connectionString string, queryTimeout time.Duration) (*gorm.DB, error) {
 	// If no connection string provided, try to get from environment
 	if connectionString == "" {
 		connectionString = os.Getenv("DATABASE_URL")
 		if connectionString == "" {
-			return fmt.Errorf("database connection string not provided")
+			return nil, fmt.Errorf("database connection string not provided")
 		}
 	}
 
-	// Configure GORM logger
+	// Configure GORM logger. Logs go to stderr so that command output on
+	// stdout can be piped into other tools.
 	config := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		DisableAutomaticPing: true,
+		Logger: logger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), logger.Config{
+			SlowThreshold: 200 * time.Millisecond,
+			LogLevel:      logger.Info,
+			Colorful:      true,
+		}),
 	}
 
 	// Connect to PostgreSQL
-	DB, err = gorm.Open(postgres.Open(connectionString), config)
+	conn, err := gorm.Open(postgres.Open(connectionString), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	if err := ping(ctx, conn, queryTimeout); err != nil {
+		closeDB(conn)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := migrate(conn.WithContext(ctx)); err != nil {
+		closeDB(conn)
+		return nil, err
+	}
+
+	// Statements run after the migrations are bounded by the query timeout
+	if err := registerQueryTimeout(conn, queryTimeout); err != nil {
+		closeDB(conn)
+		return nil, err
+	}
+
+	log.Println("Database connected and migrated successfully")
+	return conn, nil
+}
+
+// migrate upgrades tables created by older versions and auto-migrates the
+// schema
+func migrate(db *gorm.DB) error {
+
+	// Upgrade tables created by older versions before auto-migrating
+	err := migrateLegacyNFTs(db)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %v", err)
+		return err
 	}
 
+	err = migrateTokenIDColumn(db)
+	if err != nil {
+		return err
+	}
+
+	// History entries written before they referenced their transfer event
+	// are linked once the column exists
+	linkHistory := db.Migrator().HasTable(&models.OwnershipHistory{}) &&
+		!db.Migrator().HasColumn(&models.OwnershipHistory{}, "transfer_event_id")
+
 	// Auto-migrate the schema
-	err = DB.AutoMigrate(&models.NFT{})
+	err = db.AutoMigrate(
+		&models.NFT{},
+		&models.OwnershipHistory{},
+		&models.TransferEvent{},
+		&models.IndexerCheckpoint{},
+		&models.ProcessedBlock{},
+		&models.ENSName{},
+		&models.Contract{},
+		&models.TokenBalance{},
+		&models.NFTMetadata{},
+		&models.Collection{},
+		&models.Royalty{},
+	)
 	if err != nil {
-		return fmt.Errorf("failed to migrate database: %v", err)
+		return fmt.Errorf("failed to migrate database: %w", err)
 	}
 
-	log.Println("Database connected and migrated successfully")
-	return nil
+	if linkHistory {
+		if err := linkHistoryTransferEvents(db); err != nil {
+			return err
+		}
+	}
+
+	return checksumStoredOwners(db)
 }
 
-// GetDB returns the database instance
-func GetDB() *gorm.DB {
-	return DB
+// closeDB closes the connection pool of db
+func closeDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database pool: %v", err)
+	}
+
+	return sqlDB.Close()
 }
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// defaultLegacyChainID is used to backfill rows created before the chain ID
+// was part of the NFT identity. Override with LEGACY_CHAIN_ID.
+const defaultLegacyChainID = 1
+
+// migrateLegacyNFTs upgrades an nfts table keyed on token_id alone to the
+// composite (chain_id, contract_address, token_id) primary key, backfilling
+// the new columns for existing rows from LEGACY_CHAIN_ID and
+// LEGACY_CONTRACT_ADDRESS. The contract address is required if the table has
+// rows. It is a no-op for fresh databases and for tables that have already
+// been migrated.
+func migrateLegacyNFTs(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&models.NFT{}) || migrator.HasColumn(&models.NFT{}, "contract_address") {
+		return nil
+	}
+
+	var rows int64
+	if err := db.Table("nfts").Count(&rows).Error; err != nil {
+		return fmt.Errorf("failed to count legacy NFTs: %v", err)
+	}
+
+	chainID := uint64(defaultLegacyChainID)
+	if v := os.Getenv("LEGACY_CHAIN_ID"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid LEGACY_CHAIN_ID %q: %v", v, err)
+		}
+		chainID = parsed
+	}
+
+	// the service looks NFTs up by checksummed address, so backfill that form
+	contract := os.Getenv("LEGACY_CONTRACT_ADDRESS")
+	switch {
+	case contract == "" && rows > 0:
+		return fmt.Errorf("LEGACY_CONTRACT_ADDRESS is required to migrate %d existing NFT(s) to the (chain_id, contract_address, token_id) identity", rows)
+	case contract == "":
+		// an empty table needs no backfill value
+		contract = common.Address{}.Hex()
+	case !common.IsHexAddress(contract):
+		return fmt.Errorf("invalid LEGACY_CONTRACT_ADDRESS %q: expected a 20 byte hex address", contract)
+	default:
+		contract = common.HexToAddress(contract).Hex()
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			fmt.Sprintf("ALTER TABLE nfts ADD COLUMN chain_id BIGINT NOT NULL DEFAULT %d", chainID),
+			"ALTER TABLE nfts ADD COLUMN contract_address VARCHAR(42) NOT NULL DEFAULT " + quoteLiteral(contract),
+			"ALTER TABLE nfts ALTER COLUMN chain_id DROP DEFAULT",
+			"ALTER TABLE nfts ALTER COLUMN contract_address DROP DEFAULT",
+			"ALTER TABLE nfts DROP CONSTRAINT IF EXISTS nfts_pkey",
+			"ALTER TABLE nfts ADD PRIMARY KEY (chain_id, contract_address, token_id)",
+		}
+		for _, stmt := range statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("failed to migrate legacy nfts table: %v", err)
+			}
+		}
+
+		log.Printf("Migrated legacy nfts table to (chain_id, contract_address, token_id) identity")
+		return nil
+	})
+}
+
+// quoteLiteral quotes s as a SQL string literal.
+func quoteLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// migrateTokenIDColumn widens a BIGINT token_id column created by older
+// versions to NUMERIC(78,0) so that full uint256 token IDs can be stored.
+func migrateTokenIDColumn(db *gorm.DB) error {
+	columnTypes, err := db.Migrator().ColumnTypes(&models.NFT{})
+	if err != nil {
+		// the table does not exist yet and will be created by AutoMigrate
+		return nil
+	}
+
+	for _, column := range columnTypes {
+		if column.Name() != "token_id" || strings.EqualFold(column.DatabaseTypeName(), "numeric") {
+			continue
+		}
+
+		err = db.Exec("ALTER TABLE nfts ALTER COLUMN token_id TYPE NUMERIC(78,0) USING token_id::NUMERIC(78,0)").Error
+		if err != nil {
+			return fmt.Errorf("failed to migrate token_id column: %v", err)
+		}
+		log.Printf("Migrated nfts.token_id to NUMERIC(78,0)")
+	}
+
+	return nil
+}
+
+// linkHistoryTransferEvents points ownership history entries the indexer
+// wrote before they referenced their transfer event at the matching event, so
+// that reorg rollbacks can tell them apart from entries of ownerOf reads.
+func linkHistoryTransferEvents(db *gorm.DB) error {
+	result := db.Exec(`UPDATE ownership_history AS h SET transfer_event_id = e.id
+		FROM transfer_events AS e
+		WHERE h.transfer_event_id IS NULL
+			AND e.chain_id = h.chain_id
+			AND e.contract_address = h.contract_address
+			AND e.token_id = h.token_id
+			AND e.block_number = h.block_number
+			AND e.to_address = h.new_owner`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to link ownership history to transfer events: %v", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Linked %d ownership history entries to their transfer events", result.RowsAffected)
+	}
+	return nil
+}
+
+// ownerColumns are the columns holding owner addresses
+var ownerColumns = []struct {
+	table  string
+	column string
+}{
+	{"nfts", "owner"},
+	{"ownership_history", "previous_owner"},
+	{"ownership_history", "new_owner"},
+}
+
+// checksumStoredOwners rewrites owner addresses that were stored all
+// lowercase or all uppercase, e.g. by manual inserts, to their EIP-55
+// checksummed form so that lookups by owner are case-consistent.
+func checksumStoredOwners(db *gorm.DB) error {
+	for _, c := range ownerColumns {
+		var owners []string
+		err := db.Table(c.table).
+			Distinct(c.column).
+			Where(fmt.Sprintf("%[1]s = LOWER(%[1]s) OR SUBSTRING(%[1]s, 3) = UPPER(SUBSTRING(%[1]s, 3))", c.column)).
+			Pluck(c.column, &owners).Error
+		if err != nil {
+			return fmt.Errorf("failed to find unchecksummed %s.%s values: %v", c.table, c.column, err)
+		}
+
+		for _, owner := range owners {
+			if !common.IsHexAddress(owner) {
+				continue
+			}
+			checksummed := common.HexToAddress(owner).Hex()
+			if checksummed == owner {
+				continue
+			}
+
+			result := db.Table(c.table).Where(c.column+" = ?", owner).Update(c.column, checksummed)
+			if result.Error != nil {
+				return fmt.Errorf("failed to checksum %s.%s: %v", c.table, c.column, result.Error)
+			}
+			log.Printf("Checksummed %d %s.%s value(s) %s to %s", result.RowsAffected, c.table, c.column, owner, checksummed)
+		}
+	}
+
+	return nil
+}
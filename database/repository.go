@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-cli-eth/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNotFound is returned when a requested record is not stored
+	ErrNotFound = errors.New("not found")
+
+	// ErrStaleBlock is returned when an owner read at a block older than the
+	// stored one would overwrite the newer snapshot
+	ErrStaleBlock = errors.New("stale block")
+)
+
+// Repository stores NFTs in a GORM database. Every change of owner it stores
+// is appended to the ownership history of the NFT in the same transaction.
+type Repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a repository storing NFTs in db
+func NewRepository(db *gorm.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// DB returns the database of the repository, which also holds the records
+// other than NFTs
+func (r *Repository) DB() *gorm.DB {
+	return r.db
+}
+
+// Close closes the connection pool of the database
+func (r *Repository) Close() error {
+	return closeDB(r.db)
+}
+
+// GetNFT returns a stored NFT, or ErrNotFound if it is not stored
+func (r *Repository) GetNFT(ctx context.Context, chainID uint64, contractAddress string, tokenID models.TokenID) (*models.NFT, error) {
+	var nft models.NFT
+	err := whereNFT(r.db.WithContext(ctx), chainID, contractAddress, tokenID).First(&nft).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NFT: %v", err)
+	}
+	return &nft, nil
+}
+
+// ListNFTs returns the stored NFTs of a chain ordered by contract and token
+// ID. If contractAddress is not empty only NFTs of that contract are returned.
+func (r *Repository) ListNFTs(ctx context.Context, chainID uint64, contractAddress string) ([]models.NFT, error) {
+	query := r.db.WithContext(ctx).Where("chain_id = ?", chainID)
+	if contractAddress != "" {
+		query = query.Where("contract_address = ?", contractAddress)
+	}
+
+	var nfts []models.NFT
+	if err := query.Order("contract_address, token_id").Find(&nfts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get NFTs: %v", err)
+	}
+	return nfts, nil
+}
+
+// UpsertNFT stores the owner of nft read at nft.BlockNumber. NFTs that are not
+// stored yet are created; stored ones get the new owner and block unless they
+// were read at a newer block. created reports whether the NFT was created and
+// changed whether the owner of a stored NFT changed. nft is set to the stored
+// record.
+func (r *Repository) UpsertNFT(ctx context.Context, nft *models.NFT) (created bool, changed bool, err error) {
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored models.NFT
+		err := whereNFT(tx, nft.ChainID, nft.ContractAddress, nft.TokenID).First(&stored).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			now := time.Now()
+			nft.CreatedAt = now
+			nft.UpdatedAt = now
+			if err := tx.Create(nft).Error; err != nil {
+				return err
+			}
+			created = true
+			return recordOwnershipChange(tx, nft, "", nft.BlockNumber)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Never overwrite a newer snapshot with an older one
+		if nft.BlockNumber < stored.BlockNumber || nft.Owner == stored.Owner && nft.BlockNumber == stored.BlockNumber {
+			*nft = stored
+			return nil
+		}
+
+		previousOwner := stored.Owner
+		stored.Owner = nft.Owner
+		stored.BlockNumber = nft.BlockNumber
+		stored.UpdatedAt = time.Now()
+		if err := tx.Save(&stored).Error; err != nil {
+			return err
+		}
+		*nft = stored
+
+		if previousOwner == nft.Owner {
+			return nil
+		}
+		changed = true
+		return recordOwnershipChange(tx, nft, previousOwner, nft.BlockNumber)
+	})
+	if err != nil {
+		return false, false, fmt.Errorf("failed to store NFT: %v", err)
+	}
+	return created, changed, nil
+}
+
+// UpdateOwner sets the owner of a stored NFT to owner read at blockNumber and
+// returns the updated NFT along with its previous owner. It returns
+// ErrNotFound if the NFT is not stored and an error wrapping ErrStaleBlock if
+// the stored owner was read at a newer block.
+func (r *Repository) UpdateOwner(ctx context.Context, chainID uint64, contractAddress string, tokenID models.TokenID, owner string, blockNumber uint64) (*models.NFT, string, error) {
+	var nft models.NFT
+	var previousOwner string
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := whereNFT(tx, chainID, contractAddress, tokenID).First(&nft).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to find NFT: %v", err)
+		}
+
+		// Never overwrite a newer snapshot with an older one
+		if blockNumber < nft.BlockNumber {
+			return fmt.Errorf("%w: block %d is older than the stored block %d", ErrStaleBlock, blockNumber, nft.BlockNumber)
+		}
+
+		previousOwner = nft.Owner
+		nft.Owner = owner
+		nft.BlockNumber = blockNumber
+		nft.UpdatedAt = time.Now()
+		if err := tx.Save(&nft).Error; err != nil {
+			return fmt.Errorf("failed to update NFT: %v", err)
+		}
+		if previousOwner == owner {
+			return nil
+		}
+		if err := recordOwnershipChange(tx, &nft, previousOwner, blockNumber); err != nil {
+			return fmt.Errorf("failed to update NFT: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &nft, previousOwner, nil
+}
+
+// recordOwnershipChange appends an ownership history entry for nft
+func recordOwnershipChange(tx *gorm.DB, nft *models.NFT, previousOwner string, blockNumber uint64) error {
+	return tx.Create(&models.OwnershipHistory{
+		ChainID:         nft.ChainID,
+		ContractAddress: nft.ContractAddress,
+		TokenID:         nft.TokenID,
+		PreviousOwner:   previousOwner,
+		NewOwner:        nft.Owner,
+		BlockNumber:     blockNumber,
+		ObservedAt:      nft.UpdatedAt,
+	}).Error
+}
+
+// whereNFT scopes a query to a single NFT identified by chain, contract and token
+func whereNFT(db *gorm.DB, chainID uint64, contractAddress string, tokenID models.TokenID) *gorm.DB {
+	return db.Where("chain_id = ? AND contract_address = ? AND token_id = ?", chainID, contractAddress, tokenID)
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"go-cli-eth/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testContract = "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+	alice        = "0x1111111111111111111111111111111111111111"
+	bob          = "0x2222222222222222222222222222222222222222"
+)
+
+// openTestRepository opens a repository on a fresh SQLite database
+func openTestRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "repository.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.NFT{}, &models.OwnershipHistory{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	t.Cleanup(func() { closeDB(db) })
+	return NewRepository(db)
+}
+
+// history returns every stored ownership history entry, oldest first
+func history(t *testing.T, repo *Repository) []models.OwnershipHistory {
+	t.Helper()
+
+	var entries []models.OwnershipHistory
+	if err := repo.DB().Order("id").Find(&entries).Error; err != nil {
+		t.Fatalf("failed to get history: %v", err)
+	}
+	return entries
+}
+
+func TestRepositoryUpsertNFT(t *testing.T) {
+	repo := openTestRepository(t)
+	ctx := context.Background()
+	tokenID := models.TokenIDFromUint64(1)
+
+	tests := []struct {
+		name        string
+		owner       string
+		block       uint64
+		wantCreated bool
+		wantChanged bool
+		wantOwner   string
+		wantBlock   uint64
+	}{
+		{name: "create", owner: alice, block: 10, wantCreated: true, wantOwner: alice, wantBlock: 10},
+		{name: "same owner", owner: alice, block: 12, wantOwner: alice, wantBlock: 12},
+		{name: "new owner", owner: bob, block: 15, wantChanged: true, wantOwner: bob, wantBlock: 15},
+		{name: "older block", owner: alice, block: 11, wantOwner: bob, wantBlock: 15},
+	}
+
+	for _, tt := range tests {
+		nft := models.NFT{ChainID: 1, ContractAddress: testContract, TokenID: tokenID, Owner: tt.owner, BlockNumber: tt.block}
+		created, changed, err := repo.UpsertNFT(ctx, &nft)
+		if err != nil {
+			t.Fatalf("%s: UpsertNFT() error: %v", tt.name, err)
+		}
+		if created != tt.wantCreated || changed != tt.wantChanged {
+			t.Errorf("%s: UpsertNFT() = %v, %v, want %v, %v", tt.name, created, changed, tt.wantCreated, tt.wantChanged)
+		}
+		if nft.Owner != tt.wantOwner || nft.BlockNumber != tt.wantBlock {
+			t.Errorf("%s: stored NFT = %s at %d, want %s at %d", tt.name, nft.Owner, nft.BlockNumber, tt.wantOwner, tt.wantBlock)
+		}
+	}
+
+	entries := history(t, repo)
+	if len(entries) != 2 {
+		t.Fatalf("history has %d entries, want 2", len(entries))
+	}
+	if entries[1].PreviousOwner != alice || entries[1].NewOwner != bob || entries[1].BlockNumber != 15 {
+		t.Errorf("last history entry = %+v, want alice to bob at block 15", entries[1])
+	}
+}
+
+func TestRepositoryUpdateOwner(t *testing.T) {
+	repo := openTestRepository(t)
+	ctx := context.Background()
+	tokenID := models.TokenIDFromUint64(1)
+
+	if _, _, err := repo.UpdateOwner(ctx, 1, testContract, tokenID, bob, 10); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UpdateOwner() of a missing NFT error = %v, want ErrNotFound", err)
+	}
+
+	nft := models.NFT{ChainID: 1, ContractAddress: testContract, TokenID: tokenID, Owner: alice, BlockNumber: 10}
+	if _, _, err := repo.UpsertNFT(ctx, &nft); err != nil {
+		t.Fatalf("UpsertNFT() error: %v", err)
+	}
+
+	if _, _, err := repo.UpdateOwner(ctx, 1, testContract, tokenID, bob, 9); !errors.Is(err, ErrStaleBlock) {
+		t.Fatalf("UpdateOwner() at an older block error = %v, want ErrStaleBlock", err)
+	}
+
+	updated, previousOwner, err := repo.UpdateOwner(ctx, 1, testContract, tokenID, bob, 12)
+	if err != nil {
+		t.Fatalf("UpdateOwner() error: %v", err)
+	}
+	if previousOwner != alice || updated.Owner != bob || updated.BlockNumber != 12 {
+		t.Errorf("UpdateOwner() = %s at %d from %s, want bob at 12 from alice", updated.Owner, updated.BlockNumber, previousOwner)
+	}
+
+	stored, err := repo.GetNFT(ctx, 1, testContract, tokenID)
+	if err != nil {
+		t.Fatalf("GetNFT() error: %v", err)
+	}
+	if stored.Owner != bob {
+		t.Errorf("stored owner = %s, want %s", stored.Owner, bob)
+	}
+	if got := len(history(t, repo)); got != 2 {
+		t.Errorf("history has %d entries, want 2", got)
+	}
+}
+
+func TestRepositoryListNFTs(t *testing.T) {
+	repo := openTestRepository(t)
+	ctx := context.Background()
+	other := "0x0000000000000000000000000000000000000001"
+
+	for _, nft := range []models.NFT{
+		{ChainID: 1, ContractAddress: testContract, TokenID: models.TokenIDFromUint64(2), Owner: alice},
+		{ChainID: 1, ContractAddress: testContract, TokenID: models.TokenIDFromUint64(1), Owner: alice},
+		{ChainID: 1, ContractAddress: other, TokenID: models.TokenIDFromUint64(1), Owner: bob},
+		{ChainID: 137, ContractAddress: testContract, TokenID: models.TokenIDFromUint64(1), Owner: bob},
+	} {
+		if _, _, err := repo.UpsertNFT(ctx, &nft); err != nil {
+			t.Fatalf("UpsertNFT() error: %v", err)
+		}
+	}
+
+	nfts, err := repo.ListNFTs(ctx, 1, "")
+	if err != nil {
+		t.Fatalf("ListNFTs() error: %v", err)
+	}
+	if len(nfts) != 3 || nfts[0].ContractAddress != other {
+		t.Errorf("ListNFTs() = %+v, want the 3 NFTs of chain 1 ordered by contract", nfts)
+	}
+
+	nfts, err = repo.ListNFTs(ctx, 1, testContract)
+	if err != nil {
+		t.Fatalf("ListNFTs() error: %v", err)
+	}
+	if len(nfts) != 2 || nfts[0].TokenID.String() != "1" {
+		t.Errorf("ListNFTs(contract) = %+v, want tokens 1 and 2", nfts)
+	}
+
+	if _, err := repo.GetNFT(ctx, 5, testContract, models.TokenIDFromUint64(1)); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetNFT() on another chain error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultQueryTimeout is how long a single statement may run by default
+const DefaultQueryTimeout = 30 * time.Second
+
+// queryTimeoutKey stores the statement's own context while the timeout applies
+const queryTimeoutKey = "database:query_timeout"
+
+// statementTimeout is the context a statement ran with before its timeout
+// was applied, restored once the statement finished
+type statementTimeout struct {
+	parent context.Context
+	cancel context.CancelFunc
+}
+
+// registerQueryTimeout bounds every create, query, update, delete and raw
+// statement of db by timeout, on top of the context the statement runs with.
+// Zero disables the timeout; statements are then only bounded by their
+// context. Row and Rows are not bounded since their result is read after the
+// statement returns.
+func registerQueryTimeout(db *gorm.DB, timeout time.Duration) error {
+	start := startTimeout(timeout)
+	callbacks := db.Callback()
+	errs := []error{
+		callbacks.Create().Before("*").Register("database:start_timeout", start),
+		callbacks.Create().After("*").Register("database:stop_timeout", stopTimeout),
+		callbacks.Query().Before("*").Register("database:start_timeout", start),
+		callbacks.Query().After("*").Register("database:stop_timeout", stopTimeout),
+		callbacks.Update().Before("*").Register("database:start_timeout", start),
+		callbacks.Update().After("*").Register("database:stop_timeout", stopTimeout),
+		callbacks.Delete().Before("*").Register("database:start_timeout", start),
+		callbacks.Delete().After("*").Register("database:stop_timeout", stopTimeout),
+		callbacks.Raw().Before("*").Register("database:start_timeout", start),
+		callbacks.Raw().After("*").Register("database:stop_timeout", stopTimeout),
+	}
+	for _, err := range errs {
+		if err != nil {
+			return fmt.Errorf("failed to register query timeout: %v", err)
+		}
+	}
+	return nil
+}
+
+// startTimeout returns a callback applying timeout to the context of a
+// statement
+func startTimeout(timeout time.Duration) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if timeout <= 0 {
+			return
+		}
+
+		parent := db.Statement.Context
+		ctx, cancel := context.WithTimeout(parent, timeout)
+		db.InstanceSet(queryTimeoutKey, statementTimeout{parent: parent, cancel: cancel})
+		db.Statement.Context = ctx
+	}
+}
+
+// stopTimeout releases the timeout of a statement and restores its context,
+// so that a reused statement does not run with an expired one
+func stopTimeout(db *gorm.DB) {
+	value, ok := db.InstanceGet(queryTimeoutKey)
+	if !ok {
+		return
+	}
+	if timeout, ok := value.(statementTimeout); ok {
+		timeout.cancel()
+		db.Statement.Context = timeout.parent
+		db.InstanceSet(queryTimeoutKey, nil)
+	}
+}
+
+// ping checks the connection, bounded by ctx and timeout
+func ping(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return sqlDB.PingContext(ctx)
+}
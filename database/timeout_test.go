@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-cli-eth/models"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTimeoutDB opens a fresh SQLite database with the query timeout registered
+func openTimeoutDB(t *testing.T, timeout time.Duration) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "timeout.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.NFT{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	if err := registerQueryTimeout(db, timeout); err != nil {
+		t.Fatalf("registerQueryTimeout() error: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestQueryTimeout(t *testing.T) {
+	db := openTimeoutDB(t, time.Nanosecond)
+
+	var count int64
+	err := db.Model(&models.NFT{}).Count(&count).Error
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Count() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestQueryTimeoutReusedStatement(t *testing.T) {
+	db := openTimeoutDB(t, time.Minute)
+
+	// a statement reused after its timeout was released must not run with
+	// the cancelled context
+	query := db.WithContext(context.Background()).Model(&models.NFT{}).Where("chain_id = ?", 1)
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		t.Fatalf("Count() error: %v", err)
+	}
+	var nfts []models.NFT
+	if err := query.Find(&nfts).Error; err != nil {
+		t.Fatalf("Find() after Count() error: %v", err)
+	}
+}
+
+func TestQueryTimeoutDisabled(t *testing.T) {
+	db := openTimeoutDB(t, 0)
+
+	var count int64
+	if err := db.Model(&models.NFT{}).Count(&count).Error; err != nil {
+		t.Fatalf("Count() error: %v", err)
+	}
+}
+
+func TestQueryTimeoutPerDatabase(t *testing.T) {
+	expired := openTimeoutDB(t, time.Nanosecond)
+	bounded := openTimeoutDB(t, time.Minute)
+
+	// each database keeps the timeout it was opened with
+	var count int64
+	if err := bounded.Model(&models.NFT{}).Count(&count).Error; err != nil {
+		t.Fatalf("Count() with a one minute timeout error: %v", err)
+	}
+	if err := expired.Model(&models.NFT{}).Count(&count).Error; !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Count() with a one nanosecond timeout error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,1635 @@
+// Code generated by swaggo/swag. DO NOT EDIT.
+
+package docs
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/api/balances": {
+            "get": {
+                "description": "Retrieves the stored holders of an ERC-1155 contract, optionally narrowed to a token and a holder",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Balance"
+                ],
+                "summary": "Get ERC-1155 balances",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract address or ENS name",
+                        "name": "contract_address",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token ID (decimal or 0x-prefixed hex)",
+                        "name": "token_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Holder address or ENS name",
+                        "name": "holder",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.BalanceListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_token_id, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Reads the balance a holder has of an ERC-1155 token at an optional block and stores it. A zero balance removes the stored holder.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Balance"
+                ],
+                "summary": "Get and store ERC-1155 balance from blockchain",
+                "parameters": [
+                    {
+                        "description": "Get balance request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetBalanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.BalanceResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_token_id, invalid_block, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "stale_block",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "not_erc1155 or call_reverted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limited",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chains": {
+            "get": {
+                "description": "Lists the chains the API can read from. Requests select a chain with the chain parameter and use the default chain without it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chain"
+                ],
+                "summary": "Get configured chains",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ChainListResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collections": {
+            "get": {
+                "description": "Retrieves all registered collections with the number of tokens tracked for each",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Collection"
+                ],
+                "summary": "Get all collections",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CollectionListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Registers a contract as a collection, reading its name, symbol, contractURI and, for ERC-721 Enumerable contracts, totalSupply. Registering it again refreshes them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Collection"
+                ],
+                "summary": "Register a collection",
+                "parameters": [
+                    {
+                        "description": "Register collection request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterCollectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.CollectionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "not_erc721 (no code or another standard)",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limited",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/collections/{address}": {
+            "get": {
+                "description": "Retrieves a registered collection with the number of tokens tracked for it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Collection"
+                ],
+                "summary": "Get a collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract address or ENS name",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.CollectionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/contracts/{address}": {
+            "get": {
+                "description": "Returns the interfaces a contract advertises through ERC-165 and its token standard. They are probed on first use and stored.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contract"
+                ],
+                "summary": "Get contract interfaces",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract address or ENS name",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Probe the interfaces again",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ContractResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "not_erc721 (no code at the address)",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limited",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/nft": {
+            "get": {
+                "description": "Retrieves all NFT records from the database, optionally filtered by contract",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NFT"
+                ],
+                "summary": "Get all NFTs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract address or ENS name",
+                        "name": "contract_address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.NFTListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/nft/owner": {
+            "put": {
+                "description": "Updates an existing NFT record with blockchain data at an optional block",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NFT"
+                ],
+                "summary": "Update NFT owner data",
+                "parameters": [
+                    {
+                        "description": "Update owner request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateOwnerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.NFTResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_token_id, invalid_block, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found or token_nonexistent",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "stale_block",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "token_burned",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "not_erc721 or call_reverted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limited",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Fetches the owner of an NFT from the blockchain at an optional block and stores it in the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NFT"
+                ],
+                "summary": "Get and store NFT owner from blockchain",
+                "parameters": [
+                    {
+                        "description": "Get owner request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GetOwnerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.NFTResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_token_id, invalid_block, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "token_nonexistent",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "token_burned",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "not_erc721 or call_reverted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limited",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/nft/{token_id}": {
+            "get": {
+                "description": "Retrieves an NFT record of a contract from the database by token ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NFT"
+                ],
+                "summary": "Get NFT by token ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID (decimal or 0x-prefixed hex)",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contract address or ENS name",
+                        "name": "contract_address",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.NFTResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_token_id, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "not_found",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/nft/{token_id}/history": {
+            "get": {
+                "description": "Retrieves the recorded ownership changes of an NFT, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NFT"
+                ],
+                "summary": "Get NFT ownership history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID (decimal or 0x-prefixed hex)",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contract address or ENS name",
+                        "name": "contract_address",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.OwnershipHistoryListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_token_id, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/nft/{token_id}/metadata": {
+            "get": {
+                "description": "Returns the metadata document of a token with its name, description, image and attributes. It is fetched through tokenURI (ERC-721) or uri (ERC-1155) on first use and stored.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "NFT"
+                ],
+                "summary": "Get NFT metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID (decimal or 0x-prefixed hex)",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contract address or ENS name",
+                        "name": "contract_address",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Fetch the metadata again",
+                        "name": "refresh",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chain ID or name (default the default chain)",
+                        "name": "chain",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.MetadataResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, invalid_token_id, invalid_address or unknown_chain",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "token_nonexistent",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "not_erc721, call_reverted or invalid_metadata",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "rate_limited",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "internal_error",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "chain_unavailable or metadata_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns the health status of the API and of the RPC endpoints of every chain. The status is degraded, with a 503 response, when a connected chain has no healthy endpoint.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.HealthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dto.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.HealthResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.AttributeResponse": {
+            "type": "object",
+            "properties": {
+                "display_type": {
+                    "type": "string",
+                    "example": "number"
+                },
+                "trait_type": {
+                    "type": "string",
+                    "example": "Fur"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "Brown"
+                }
+            }
+        },
+        "dto.BalanceListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BalanceResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Balances retrieved successfully"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.BalanceResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string",
+                    "example": "3"
+                },
+                "block_number": {
+                    "type": "integer",
+                    "example": 18000000
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0x76BE3b62873462d2142405439777e971754E8E77"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "holder": {
+                    "type": "string",
+                    "example": "0x1234567890123456789012345678901234567890"
+                },
+                "token_id": {
+                    "type": "string",
+                    "example": "10570"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.ChainHealthResponse": {
+            "type": "object",
+            "properties": {
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "connected": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.EndpointStatusResponse"
+                    }
+                },
+                "healthy": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ethereum"
+                },
+                "selection": {
+                    "type": "string",
+                    "example": "priority"
+                }
+            }
+        },
+        "dto.ChainListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChainResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Chains retrieved successfully"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.ChainResponse": {
+            "type": "object",
+            "properties": {
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "confirmations": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "default": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "endpoints": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "multicall_address": {
+                    "type": "string",
+                    "example": "0xcA11bde05977b3631167028862bE2a173976CA11"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "ethereum"
+                }
+            }
+        },
+        "dto.CollectionListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CollectionResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Collections retrieved successfully"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.CollectionResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "contract_uri": {
+                    "type": "string",
+                    "example": ""
+                },
+                "name": {
+                    "type": "string",
+                    "example": "BoredApeYachtClub"
+                },
+                "registered_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "royalty_basis_points": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "royalty_receiver": {
+                    "type": "string",
+                    "example": "0xA858DDc0445d8131daC4d1DE01f834ffcbA52Ef1"
+                },
+                "standard": {
+                    "type": "string",
+                    "example": "erc721"
+                },
+                "symbol": {
+                    "type": "string",
+                    "example": "BAYC"
+                },
+                "token_count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "total_supply": {
+                    "type": "string",
+                    "example": "10000"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.ContractResponse": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "detected_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "erc1155": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "erc165": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "erc2981": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "erc721": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "erc721_enumerable": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "erc721_metadata": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "standard": {
+                    "type": "string",
+                    "example": "erc721"
+                }
+            }
+        },
+        "dto.EndpointStatusResponse": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "integer",
+                    "example": 85
+                },
+                "failures": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "head_block": {
+                    "type": "integer",
+                    "example": 19000000
+                },
+                "healthy": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "last_checked_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "503 Service Unavailable"
+                },
+                "last_error_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 1250
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://mainnet.infura.io/..."
+                }
+            }
+        },
+        "dto.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "not_found"
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Detailed error message"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "An error occurred"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.GetBalanceRequest": {
+            "type": "object",
+            "required": [
+                "contract_address",
+                "holder",
+                "token_id"
+            ],
+            "properties": {
+                "block": {
+                    "type": "string",
+                    "example": "finalized"
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "ethereum"
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0x76BE3b62873462d2142405439777e971754E8E77"
+                },
+                "holder": {
+                    "type": "string",
+                    "example": "0x1234567890123456789012345678901234567890"
+                },
+                "token_id": {
+                    "type": "string",
+                    "example": "10570"
+                }
+            }
+        },
+        "dto.GetOwnerRequest": {
+            "type": "object",
+            "required": [
+                "contract_address",
+                "token_id"
+            ],
+            "properties": {
+                "block": {
+                    "type": "string",
+                    "example": "finalized"
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "ethereum"
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                },
+                "token_id": {
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "dto.HealthResponse": {
+            "type": "object",
+            "properties": {
+                "chains": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.ChainHealthResponse"
+                    }
+                },
+                "service": {
+                    "type": "string",
+                    "example": "go-cli-eth"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "dto.MetadataResponse": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AttributeResponse"
+                    }
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                },
+                "description": {
+                    "type": "string",
+                    "example": ""
+                },
+                "fetched_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "image": {
+                    "type": "string",
+                    "example": "ipfs://QmPbxeGcXhYQQNgsC6a36dDyYUcHgMLnGKnF8pVFmGsvqi"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Bored Ape #1"
+                },
+                "raw": {
+                    "type": "object"
+                },
+                "token_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "uri": {
+                    "type": "string",
+                    "example": "ipfs://QmeSjSinHpPnmXmspMjwiXyN6zS4E9zccariGR3jxcaWtq/1"
+                }
+            }
+        },
+        "dto.NFTListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.NFTResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "NFTs retrieved successfully"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.NFTResponse": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer",
+                    "example": 18000000
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "0x1234567890123456789012345678901234567890"
+                },
+                "owner_ens": {
+                    "type": "string",
+                    "example": "vitalik.eth"
+                },
+                "royalty_basis_points": {
+                    "type": "integer",
+                    "example": 250
+                },
+                "royalty_receiver": {
+                    "type": "string",
+                    "example": "0xA858DDc0445d8131daC4d1DE01f834ffcbA52Ef1"
+                },
+                "token_id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.OwnershipHistoryListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.OwnershipHistoryResponse"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Ownership history retrieved successfully"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.OwnershipHistoryResponse": {
+            "type": "object",
+            "properties": {
+                "block_number": {
+                    "type": "integer",
+                    "example": 18000000
+                },
+                "chain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                },
+                "new_owner": {
+                    "type": "string",
+                    "example": "0x1234567890123456789012345678901234567890"
+                },
+                "observed_at": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                },
+                "previous_owner": {
+                    "type": "string",
+                    "example": "0x0987654321098765432109876543210987654321"
+                },
+                "token_id": {
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        },
+        "dto.RegisterCollectionRequest": {
+            "type": "object",
+            "required": [
+                "contract_address"
+            ],
+            "properties": {
+                "chain": {
+                    "type": "string",
+                    "example": "ethereum"
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                }
+            }
+        },
+        "dto.SuccessResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "message": {
+                    "type": "string",
+                    "example": "Operation completed successfully"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.UpdateOwnerRequest": {
+            "type": "object",
+            "required": [
+                "contract_address",
+                "token_id"
+            ],
+            "properties": {
+                "block": {
+                    "type": "string",
+                    "example": "finalized"
+                },
+                "chain": {
+                    "type": "string",
+                    "example": "ethereum"
+                },
+                "contract_address": {
+                    "type": "string",
+                    "example": "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+                },
+                "token_id": {
+                    "type": "string",
+                    "example": "1"
+                }
+            }
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "",
+	BasePath:         "/",
+	Schemes:          []string{},
+	Title:            "Go CLI Ethereum NFT Tracker API",
+	Description:      "REST API for fetching ERC-721 ownership from Ethereum and storing it in PostgreSQL.",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
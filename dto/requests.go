@@ -1,13 +1,48 @@
 package dto
 
-// GetOwnerRequest represents a request to get NFT owner data
+// GetOwnerRequest represents a request to get NFT owner data.
+// ContractAddress is a 0x-prefixed 20 byte hex address or an ENS name.
+// TokenID accepts a uint256 in decimal or 0x-prefixed hex notation. Block pins
+// the read to a block number, block hash, or the latest, safe or finalized tag.
+// Chain selects the chain by ID or name and defaults to the default chain.
 type GetOwnerRequest struct {
-	ContractAddress string `json:"contract_address" binding:"required" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
-	TokenID         uint   `json:"token_id" binding:"required" example:"1"`
+	ContractAddress string `json:"contract_address" binding:"required,contract_address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	TokenID         string `json:"token_id" binding:"required" example:"1"`
+	Block           string `json:"block,omitempty" example:"finalized"`
+	Chain           string `json:"chain,omitempty" example:"ethereum"`
 }
 
-// UpdateOwnerRequest represents a request to update NFT owner data
+// UpdateOwnerRequest represents a request to update NFT owner data.
+// ContractAddress is a 0x-prefixed 20 byte hex address or an ENS name.
+// TokenID accepts a uint256 in decimal or 0x-prefixed hex notation. Block pins
+// the read to a block number, block hash, or the latest, safe or finalized tag.
+// Chain selects the chain by ID or name and defaults to the default chain.
 type UpdateOwnerRequest struct {
-	ContractAddress string `json:"contract_address" binding:"required" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
-	TokenID         uint   `json:"token_id" binding:"required" example:"1"`
+	ContractAddress string `json:"contract_address" binding:"required,contract_address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	TokenID         string `json:"token_id" binding:"required" example:"1"`
+	Block           string `json:"block,omitempty" example:"finalized"`
+	Chain           string `json:"chain,omitempty" example:"ethereum"`
+}
+
+// GetBalanceRequest represents a request to get the balance an account holds
+// of an ERC-1155 token. ContractAddress and Holder are 0x-prefixed 20 byte
+// hex addresses or ENS names. TokenID accepts a uint256 in decimal or
+// 0x-prefixed hex notation. Block pins the read to a block number, block
+// hash, or the latest, safe or finalized tag. Chain selects the chain by ID or
+// name and defaults to the default chain.
+type GetBalanceRequest struct {
+	ContractAddress string `json:"contract_address" binding:"required,contract_address" example:"0x76BE3b62873462d2142405439777e971754E8E77"`
+	TokenID         string `json:"token_id" binding:"required" example:"10570"`
+	Holder          string `json:"holder" binding:"required,contract_address" example:"0x1234567890123456789012345678901234567890"`
+	Block           string `json:"block,omitempty" example:"finalized"`
+	Chain           string `json:"chain,omitempty" example:"ethereum"`
+}
+
+// RegisterCollectionRequest represents a request to register a contract as a
+// collection. ContractAddress is a 0x-prefixed 20 byte hex address or an ENS
+// name. Chain selects the chain by ID or name and defaults to the default
+// chain.
+type RegisterCollectionRequest struct {
+	ContractAddress string `json:"contract_address" binding:"required,contract_address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	Chain           string `json:"chain,omitempty" example:"ethereum"`
 }
@@ -1,13 +1,26 @@
 package dto
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
-// NFTResponse represents the response structure for NFT data
+// NFTResponse represents the response structure for NFT data.
+// TokenID is the decimal representation of the uint256 token ID. OwnerENS is
+// the owner's primary ENS name and omitted if it has none. RoyaltyReceiver and
+// RoyaltyBasisPoints are the token's ERC-2981 royalty, in 1/100 of a percent
+// of the sale price, and omitted if none is stored.
 type NFTResponse struct {
-	TokenID   uint      `json:"token_id" example:"1"`
-	Owner     string    `json:"owner" example:"0x1234567890123456789012345678901234567890"`
-	CreatedAt time.Time `json:"created_at" example:"2023-01-01T12:00:00Z"`
-	UpdatedAt time.Time `json:"updated_at" example:"2023-01-01T12:00:00Z"`
+	ChainID            uint64    `json:"chain_id" example:"1"`
+	ContractAddress    string    `json:"contract_address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	TokenID            string    `json:"token_id" example:"1"`
+	Owner              string    `json:"owner" example:"0x1234567890123456789012345678901234567890"`
+	OwnerENS           string    `json:"owner_ens,omitempty" example:"vitalik.eth"`
+	RoyaltyReceiver    string    `json:"royalty_receiver,omitempty" example:"0xA858DDc0445d8131daC4d1DE01f834ffcbA52Ef1"`
+	RoyaltyBasisPoints *uint32   `json:"royalty_basis_points,omitempty" example:"250"`
+	BlockNumber        uint64    `json:"block_number" example:"18000000"`
+	CreatedAt          time.Time `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	UpdatedAt          time.Time `json:"updated_at" example:"2023-01-01T12:00:00Z"`
 }
 
 // SuccessResponse represents a successful API response
@@ -17,10 +30,12 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// ErrorResponse represents an error API response
+// ErrorResponse represents an error API response. Code is a stable,
+// machine-readable identifier of the error such as not_found or rate_limited.
 type ErrorResponse struct {
 	Success bool   `json:"success" example:"false"`
 	Message string `json:"message" example:"An error occurred"`
+	Code    string `json:"code" example:"not_found"`
 	Error   string `json:"error,omitempty" example:"Detailed error message"`
 }
 
@@ -31,3 +46,166 @@ type NFTListResponse struct {
 	Data    []NFTResponse `json:"data"`
 	Count   int           `json:"count" example:"10"`
 }
+
+// OwnershipHistoryResponse represents a recorded ownership change of an NFT
+type OwnershipHistoryResponse struct {
+	ChainID         uint64    `json:"chain_id" example:"1"`
+	ContractAddress string    `json:"contract_address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	TokenID         string    `json:"token_id" example:"1"`
+	PreviousOwner   string    `json:"previous_owner" example:"0x0987654321098765432109876543210987654321"`
+	NewOwner        string    `json:"new_owner" example:"0x1234567890123456789012345678901234567890"`
+	BlockNumber     uint64    `json:"block_number" example:"18000000"`
+	ObservedAt      time.Time `json:"observed_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// OwnershipHistoryListResponse represents the ownership history of an NFT
+type OwnershipHistoryListResponse struct {
+	Success bool                       `json:"success" example:"true"`
+	Message string                     `json:"message" example:"Ownership history retrieved successfully"`
+	Data    []OwnershipHistoryResponse `json:"data"`
+	Count   int                        `json:"count" example:"3"`
+}
+
+// ContractResponse represents the interfaces a contract advertises through
+// ERC-165 and the token standard derived from them: erc721, erc1155, other
+// (ERC-165 but neither) or unknown (no ERC-165).
+type ContractResponse struct {
+	ChainID          uint64    `json:"chain_id" example:"1"`
+	Address          string    `json:"address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	Standard         string    `json:"standard" example:"erc721"`
+	ERC165           bool      `json:"erc165" example:"true"`
+	ERC721           bool      `json:"erc721" example:"true"`
+	ERC721Metadata   bool      `json:"erc721_metadata" example:"true"`
+	ERC721Enumerable bool      `json:"erc721_enumerable" example:"true"`
+	ERC1155          bool      `json:"erc1155" example:"false"`
+	ERC2981          bool      `json:"erc2981" example:"false"`
+	DetectedAt       time.Time `json:"detected_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// BalanceResponse represents the balance a holder has of an ERC-1155 token.
+// TokenID and Balance are decimal representations of uint256 values.
+type BalanceResponse struct {
+	ChainID         uint64    `json:"chain_id" example:"1"`
+	ContractAddress string    `json:"contract_address" example:"0x76BE3b62873462d2142405439777e971754E8E77"`
+	TokenID         string    `json:"token_id" example:"10570"`
+	Holder          string    `json:"holder" example:"0x1234567890123456789012345678901234567890"`
+	Balance         string    `json:"balance" example:"3"`
+	BlockNumber     uint64    `json:"block_number" example:"18000000"`
+	CreatedAt       time.Time `json:"created_at" example:"2023-01-01T12:00:00Z"`
+	UpdatedAt       time.Time `json:"updated_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// BalanceListResponse represents a list of ERC-1155 balances
+type BalanceListResponse struct {
+	Success bool              `json:"success" example:"true"`
+	Message string            `json:"message" example:"Balances retrieved successfully"`
+	Data    []BalanceResponse `json:"data"`
+	Count   int               `json:"count" example:"10"`
+}
+
+// AttributeResponse represents a trait of an NFT. Value is a string, number
+// or boolean.
+type AttributeResponse struct {
+	TraitType   string      `json:"trait_type" example:"Fur"`
+	Value       interface{} `json:"value" swaggertype:"string" example:"Brown"`
+	DisplayType string      `json:"display_type,omitempty" example:"number"`
+}
+
+// MetadataResponse represents the metadata document of a token. Raw is the
+// document as fetched from URI.
+type MetadataResponse struct {
+	ChainID         uint64              `json:"chain_id" example:"1"`
+	ContractAddress string              `json:"contract_address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	TokenID         string              `json:"token_id" example:"1"`
+	URI             string              `json:"uri" example:"ipfs://QmeSjSinHpPnmXmspMjwiXyN6zS4E9zccariGR3jxcaWtq/1"`
+	Name            string              `json:"name" example:"Bored Ape #1"`
+	Description     string              `json:"description" example:""`
+	Image           string              `json:"image" example:"ipfs://QmPbxeGcXhYQQNgsC6a36dDyYUcHgMLnGKnF8pVFmGsvqi"`
+	Attributes      []AttributeResponse `json:"attributes"`
+	Raw             json.RawMessage     `json:"raw" swaggertype:"object"`
+	FetchedAt       time.Time           `json:"fetched_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// CollectionResponse represents a registered collection. TotalSupply is the
+// decimal uint256 total supply and omitted for contracts that are not ERC-721
+// Enumerable. RoyaltyReceiver and RoyaltyBasisPoints are the ERC-2981
+// royalty most tracked tokens share and omitted until royalties are
+// refreshed. TokenCount is the number of tokens of the collection tracked in
+// the database.
+type CollectionResponse struct {
+	ChainID            uint64    `json:"chain_id" example:"1"`
+	Address            string    `json:"address" example:"0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"`
+	Standard           string    `json:"standard" example:"erc721"`
+	Name               string    `json:"name" example:"BoredApeYachtClub"`
+	Symbol             string    `json:"symbol" example:"BAYC"`
+	TotalSupply        string    `json:"total_supply,omitempty" example:"10000"`
+	ContractURI        string    `json:"contract_uri" example:""`
+	RoyaltyReceiver    string    `json:"royalty_receiver,omitempty" example:"0xA858DDc0445d8131daC4d1DE01f834ffcbA52Ef1"`
+	RoyaltyBasisPoints *uint32   `json:"royalty_basis_points,omitempty" example:"250"`
+	TokenCount         int64     `json:"token_count" example:"42"`
+	RegisteredAt       time.Time `json:"registered_at" example:"2023-01-01T12:00:00Z"`
+	UpdatedAt          time.Time `json:"updated_at" example:"2023-01-01T12:00:00Z"`
+}
+
+// CollectionListResponse represents a list of collections
+type CollectionListResponse struct {
+	Success bool                 `json:"success" example:"true"`
+	Message string               `json:"message" example:"Collections retrieved successfully"`
+	Data    []CollectionResponse `json:"data"`
+	Count   int                  `json:"count" example:"3"`
+}
+
+// ChainResponse represents a configured chain. Endpoints is the number of
+// RPC endpoints configured for it; the URLs themselves are not exposed since
+// they usually embed API keys. Confirmations is omitted if the chain uses the
+// indexer default. Default marks the chain used when a request selects none.
+type ChainResponse struct {
+	ChainID          uint64 `json:"chain_id" example:"1"`
+	Name             string `json:"name" example:"ethereum"`
+	Endpoints        int    `json:"endpoints" example:"2"`
+	Confirmations    uint64 `json:"confirmations,omitempty" example:"12"`
+	MulticallAddress string `json:"multicall_address" example:"0xcA11bde05977b3631167028862bE2a173976CA11"`
+	Default          bool   `json:"default" example:"true"`
+}
+
+// ChainListResponse represents the list of configured chains
+type ChainListResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Message string          `json:"message" example:"Chains retrieved successfully"`
+	Data    []ChainResponse `json:"data"`
+	Count   int             `json:"count" example:"4"`
+}
+
+// EndpointStatusResponse represents the health and usage of an RPC endpoint.
+// URL is reduced to its scheme and host since paths usually embed API keys.
+// Healthy is false while the endpoint is skipped after a failure.
+type EndpointStatusResponse struct {
+	URL           string     `json:"url" example:"https://mainnet.infura.io/..."`
+	Healthy       bool       `json:"healthy" example:"true"`
+	Requests      uint64     `json:"requests" example:"1250"`
+	Failures      uint64     `json:"failures" example:"3"`
+	AvgLatencyMs  int64      `json:"avg_latency_ms" example:"85"`
+	HeadBlock     uint64     `json:"head_block,omitempty" example:"19000000"`
+	LastError     string     `json:"last_error,omitempty" example:"503 Service Unavailable"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty" example:"2023-01-01T12:00:00Z"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty" example:"2023-01-01T12:00:00Z"`
+}
+
+// ChainHealthResponse represents the RPC endpoints of a chain. Chains that
+// have not been used yet are not connected and have no endpoint stats.
+type ChainHealthResponse struct {
+	ChainID   uint64                   `json:"chain_id" example:"1"`
+	Name      string                   `json:"name" example:"ethereum"`
+	Selection string                   `json:"selection" example:"priority"`
+	Connected bool                     `json:"connected" example:"true"`
+	Healthy   bool                     `json:"healthy" example:"true"`
+	Endpoints []EndpointStatusResponse `json:"endpoints,omitempty"`
+}
+
+// HealthResponse represents the health of the API. Status is "degraded" when
+// a connected chain has no healthy RPC endpoint.
+type HealthResponse struct {
+	Status  string                `json:"status" example:"ok"`
+	Service string                `json:"service" example:"go-cli-eth"`
+	Chains  []ChainHealthResponse `json:"chains"`
+}
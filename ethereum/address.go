@@ -0,0 +1,58 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ErrInvalidAddress is returned when a string is not a 0x-prefixed 20 byte
+// hex address
+var ErrInvalidAddress = errors.New("invalid address")
+
+// ParseAddress parses a 0x-prefixed address of exactly 40 hex digits. Unlike
+// common.HexToAddress, which turns any input into some address, anything else
+// is rejected with an error wrapping ErrInvalidAddress. The checksum is not
+// verified; use HasValidChecksum to warn about mistyped mixed-case addresses.
+func ParseAddress(s string) (common.Address, error) {
+	s = strings.TrimSpace(s)
+	if len(s) != 2+2*common.AddressLength || !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return common.Address{}, fmt.Errorf("%w %q: expected 0x followed by 40 hex digits", ErrInvalidAddress, s)
+	}
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("%w %q: contains non-hex characters", ErrInvalidAddress, s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// HasValidChecksum reports whether the casing of a hex address is consistent
+// with EIP-55. All lowercase and all uppercase addresses carry no checksum and
+// are always valid; mixed-case addresses must match their checksummed form.
+func HasValidChecksum(s string) bool {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 {
+		return false
+	}
+	digits := s[2:]
+	if digits == strings.ToLower(digits) || digits == strings.ToUpper(digits) {
+		return true
+	}
+	return s[:2] == "0x" && s == common.HexToAddress(s).Hex()
+}
+
+// HasCode reports whether a contract is deployed at address in the latest block
+func (ec *EthereumClient) HasCode(ctx context.Context, address common.Address) (bool, error) {
+	var code []byte
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		code, err = client.CodeAt(ctx, address, nil)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get code of %s: %w", address.Hex(), err)
+	}
+	return len(code) > 0, nil
+}
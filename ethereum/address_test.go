@@ -0,0 +1,69 @@
+package ethereum
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAddress(t *testing.T) {
+	const checksummed = "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: checksummed},
+		{input: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"},
+		{input: "0XBC4CA0EDA7647A8AB7C2061C2E118A18A936F13D"},
+		{input: " " + checksummed + " "},
+		// a wrong checksum is left to HasValidChecksum
+		{input: "0xbC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"},
+		{input: "hello", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "0x", wantErr: true},
+		{input: "bc4ca0eda7647a8ab7c2061c2e118a18a936f13d", wantErr: true},
+		{input: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f1", wantErr: true},
+		{input: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d00", wantErr: true},
+		{input: "0xzc4ca0eda7647a8ab7c2061c2e118a18a936f13d", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			address, err := ParseAddress(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAddress) {
+					t.Fatalf("ParseAddress(%q) = %s, %v, want ErrInvalidAddress", tt.input, address.Hex(), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAddress(%q) error: %v", tt.input, err)
+			}
+			if address.Hex() != checksummed {
+				t.Errorf("ParseAddress(%q) = %s, want %s", tt.input, address.Hex(), checksummed)
+			}
+		})
+	}
+}
+
+func TestHasValidChecksum(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{input: "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", want: true},
+		{input: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d", want: true},
+		{input: "0xBC4CA0EDA7647A8AB7C2061C2E118A18A936F13D", want: true},
+		{input: "0x0000000000000000000000000000000000000000", want: true},
+		{input: "0xbC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", want: false},
+		{input: "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13d", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := HasValidChecksum(tt.input); got != tt.want {
+				t.Errorf("HasValidChecksum(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Block tags accepted by ParseBlockRef
+const (
+	BlockTagLatest    = "latest"
+	BlockTagSafe      = "safe"
+	BlockTagFinalized = "finalized"
+)
+
+// BlockRef identifies the block a contract read is executed against.
+// Exactly one of Number, Hash or Tag is set; the zero value means latest.
+type BlockRef struct {
+	Number *big.Int
+	Hash   *common.Hash
+	Tag    string
+}
+
+// LatestBlock returns a reference to the latest block
+func LatestBlock() BlockRef {
+	return BlockRef{Tag: BlockTagLatest}
+}
+
+// BlockAt returns a reference to the block with the given number
+func BlockAt(number uint64) BlockRef {
+	return BlockRef{Number: new(big.Int).SetUint64(number)}
+}
+
+// ParseBlockRef parses a block number (decimal or 0x hex), a 32-byte block hash
+// or one of the tags latest, safe and finalized. An empty string means latest.
+func ParseBlockRef(s string) (BlockRef, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+
+	switch s {
+	case "", BlockTagLatest:
+		return LatestBlock(), nil
+	case BlockTagSafe, BlockTagFinalized:
+		return BlockRef{Tag: s}, nil
+	}
+
+	if strings.HasPrefix(s, "0x") && len(s) == 66 {
+		if _, err := hexutil.Decode(s); err != nil {
+			return BlockRef{}, fmt.Errorf("invalid block hash %q: %v", s, err)
+		}
+		hash := common.HexToHash(s)
+		return BlockRef{Hash: &hash}, nil
+	}
+
+	base := 10
+	digits := s
+	if strings.HasPrefix(s, "0x") {
+		base = 16
+		digits = s[2:]
+	}
+	// big.Int accepts a sign, block numbers do not
+	if strings.HasPrefix(digits, "+") || strings.HasPrefix(digits, "-") {
+		digits = ""
+	}
+	number, ok := new(big.Int).SetString(digits, base)
+	if !ok || !number.IsUint64() {
+		return BlockRef{}, fmt.Errorf("invalid block %q: expected a block number, block hash, latest, safe or finalized", s)
+	}
+	return BlockRef{Number: number}, nil
+}
+
+// String returns the block reference in the notation accepted by ParseBlockRef
+func (b BlockRef) String() string {
+	switch {
+	case b.Hash != nil:
+		return b.Hash.Hex()
+	case b.Number != nil:
+		return b.Number.String()
+	case b.Tag != "":
+		return b.Tag
+	default:
+		return BlockTagLatest
+	}
+}
+
+// tagNumber returns the negative block number ethclient uses to encode a tag
+func (b BlockRef) tagNumber() *big.Int {
+	switch b.Tag {
+	case BlockTagSafe:
+		return big.NewInt(rpc.SafeBlockNumber.Int64())
+	case BlockTagFinalized:
+		return big.NewInt(rpc.FinalizedBlockNumber.Int64())
+	default:
+		return nil
+	}
+}
+
+// ResolveBlock returns the concrete block number a block reference points to.
+// Resolving a tag once and reading at the returned number keeps several reads
+// consistent with each other even if the chain head moves in between.
+func (ec *EthereumClient) ResolveBlock(ctx context.Context, block BlockRef) (uint64, error) {
+	if block.Number != nil {
+		return block.Number.Uint64(), nil
+	}
+
+	var header *types.Header
+	if block.Hash != nil {
+		err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+			header, err = client.HeaderByHash(ctx, *block.Hash)
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get block %s: %w", block.Hash.Hex(), err)
+		}
+		return header.Number.Uint64(), nil
+	}
+
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		header, err = client.HeaderByNumber(ctx, block.tagNumber())
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s block: %w", block, err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// HeaderByNumber returns the header of the canonical block with the given number
+func (ec *EthereumClient) HeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
+	var header *types.Header
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		header, err = client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block %d: %w", number, err)
+	}
+	return header, nil
+}
+
+// callAt executes msg against the referenced block and returns the result
+// together with the number of the block it was executed against. The block
+// number is also returned if the call itself fails, e.g. because it reverted.
+func (ec *EthereumClient) callAt(ctx context.Context, msg ethereum.CallMsg, block BlockRef) ([]byte, uint64, error) {
+	blockNumber, err := ec.ResolveBlock(ctx, block)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result []byte
+	err = ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		if block.Hash != nil {
+			result, err = client.CallContractAtHash(ctx, msg, *block.Hash)
+		} else {
+			result, err = client.CallContract(ctx, msg, new(big.Int).SetUint64(blockNumber))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, blockNumber, err
+	}
+
+	return result, blockNumber, nil
+}
@@ -0,0 +1,77 @@
+package ethereum
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBlockRef(t *testing.T) {
+	hash := "0x" + strings.Repeat("ab", 32)
+
+	tests := []struct {
+		input   string
+		want    string
+		kind    string
+		wantErr bool
+	}{
+		{input: "", want: "latest", kind: "tag"},
+		{input: "latest", want: "latest", kind: "tag"},
+		{input: " Safe ", want: "safe", kind: "tag"},
+		{input: "FINALIZED", want: "finalized", kind: "tag"},
+		{input: "0", want: "0", kind: "number"},
+		{input: "19000000", want: "19000000", kind: "number"},
+		{input: "0x10", want: "16", kind: "number"},
+		{input: "0X10", want: "16", kind: "number"},
+		{input: "18446744073709551615", want: "18446744073709551615", kind: "number"},
+		{input: "0xffffffffffffffff", want: "18446744073709551615", kind: "number"},
+		{input: hash, want: hash, kind: "hash"},
+		{input: strings.ToUpper(hash[2:]), wantErr: true},
+		{input: "0x" + strings.Repeat("AB", 32), want: hash, kind: "hash"},
+		// 64 hex digits are a hash, fewer a number
+		{input: "0x" + strings.Repeat("0", 63) + "1", want: "0x" + strings.Repeat("0", 63) + "1", kind: "hash"},
+		{input: "0x" + strings.Repeat("0", 62) + "1", want: "1", kind: "number"},
+		{input: "0x" + strings.Repeat("f", 63), wantErr: true},
+		{input: "0x" + strings.Repeat("zz", 32), wantErr: true},
+		{input: "18446744073709551616", wantErr: true},
+		{input: "0x10000000000000000", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "+1", wantErr: true},
+		{input: "0x-1", wantErr: true},
+		{input: "0x+1", wantErr: true},
+		{input: "0x", wantErr: true},
+		{input: "1.5", wantErr: true},
+		{input: "pending", wantErr: true},
+		{input: "earliest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseBlockRef(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseBlockRef(%q) = %s, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBlockRef(%q) error: %v", tt.input, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseBlockRef(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+
+			var kind string
+			switch {
+			case got.Hash != nil:
+				kind = "hash"
+			case got.Number != nil:
+				kind = "number"
+			case got.Tag != "":
+				kind = "tag"
+			}
+			if kind != tt.kind {
+				t.Errorf("ParseBlockRef(%q) is a %s, want a %s", tt.input, kind, tt.kind)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package ethereum
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownChain is returned when a chain is not in the chain registry
+	ErrUnknownChain = errors.New("unknown chain")
+
+	// ErrChainMismatch is returned when an RPC endpoint serves another chain
+	// than the one it is configured for
+	ErrChainMismatch = errors.New("chain ID mismatch")
+)
+
+// knownChainNames names well-known chains when the registry is built from a
+// single RPC URL instead of a chains file
+var knownChainNames = map[uint64]string{
+	1:        "ethereum",
+	10:       "optimism",
+	56:       "bsc",
+	137:      "polygon",
+	8453:     "base",
+	42161:    "arbitrum",
+	11155111: "sepolia",
+}
+
+// ChainConfig describes a chain the tracker can connect to. Requests fail
+// over between RPCURLs, picked according to Selection (default
+// SelectionPriority). Confirmations is the number of blocks the indexer stays
+// behind the chain head, zero meaning the indexer default. MulticallAddress
+// overrides DefaultMulticallAddress. RateLimits limits the requests sent to
+// each endpoint by the host name of its URL.
+type ChainConfig struct {
+	ID               uint64               `json:"id"`
+	Name             string               `json:"name"`
+	RPCURLs          []string             `json:"rpc_urls"`
+	Selection        string               `json:"selection,omitempty"`
+	Confirmations    uint64               `json:"confirmations,omitempty"`
+	MulticallAddress string               `json:"multicall_address,omitempty"`
+	RateLimits       map[string]RateLimit `json:"rate_limits,omitempty"`
+}
+
+// ChainName returns the well-known name of a chain, or "chain-<id>" for
+// chains without one
+func ChainName(id uint64) string {
+	if name, ok := knownChainNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("chain-%d", id)
+}
+
+// RateLimit returns the rate limit configured for an RPC URL of the chain
+func (c ChainConfig) RateLimit(rpcURL string) (RateLimit, bool) {
+	limit, ok := c.RateLimits[endpointHost(rpcURL)]
+	return limit, ok
+}
+
+// ChainRegistry holds the configured chains. The first chain is the default
+// one, used when no chain is selected.
+type ChainRegistry struct {
+	chains []ChainConfig
+}
+
+// NewChainRegistry validates chain configurations and creates a registry of
+// them. Names are lowercased; IDs and names must be unique.
+func NewChainRegistry(chains []ChainConfig) (*ChainRegistry, error) {
+	if len(chains) == 0 {
+		return nil, errors.New("no chains configured")
+	}
+
+	ids := make(map[uint64]bool, len(chains))
+	names := make(map[string]bool, len(chains))
+	registry := &ChainRegistry{chains: make([]ChainConfig, 0, len(chains))}
+	for _, chain := range chains {
+		chain.Name = strings.ToLower(strings.TrimSpace(chain.Name))
+		if chain.ID == 0 {
+			return nil, fmt.Errorf("chain %q has no ID", chain.Name)
+		}
+		if chain.Name == "" {
+			chain.Name = ChainName(chain.ID)
+		}
+		if _, err := strconv.ParseUint(chain.Name, 10, 64); err == nil {
+			return nil, fmt.Errorf("chain %d has a numeric name %q", chain.ID, chain.Name)
+		}
+		if ids[chain.ID] {
+			return nil, fmt.Errorf("chain %d is configured twice", chain.ID)
+		}
+		if names[chain.Name] {
+			return nil, fmt.Errorf("chain name %q is used twice", chain.Name)
+		}
+		if len(chain.RPCURLs) == 0 {
+			return nil, fmt.Errorf("chain %s has no RPC URLs", chain.Name)
+		}
+		switch chain.Selection {
+		case "", SelectionPriority, SelectionRoundRobin, SelectionLatency:
+		default:
+			return nil, fmt.Errorf("chain %s has an invalid selection %q: must be %s, %s or %s",
+				chain.Name, chain.Selection, SelectionPriority, SelectionRoundRobin, SelectionLatency)
+		}
+		if chain.MulticallAddress != "" {
+			if _, err := ParseAddress(chain.MulticallAddress); err != nil {
+				return nil, fmt.Errorf("chain %s has an invalid Multicall address: %w", chain.Name, err)
+			}
+		}
+		if len(chain.RateLimits) > 0 {
+			limits := make(map[string]RateLimit, len(chain.RateLimits))
+			for host, limit := range chain.RateLimits {
+				if limit.RequestsPerSecond <= 0 || limit.Burst < 0 {
+					return nil, fmt.Errorf("chain %s has an invalid rate limit for %s: requests_per_second must be positive and burst not negative", chain.Name, host)
+				}
+				limits[strings.ToLower(host)] = limit
+			}
+			chain.RateLimits = limits
+		}
+
+		ids[chain.ID] = true
+		names[chain.Name] = true
+		registry.chains = append(registry.chains, chain)
+	}
+	return registry, nil
+}
+
+// LoadChainRegistry reads a chains file, a JSON object with a "chains" array
+// of chain configurations. ${VAR} references in RPC URLs are expanded from
+// the environment so API keys can stay out of the file.
+func LoadChainRegistry(path string) (*ChainRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chains file: %v", err)
+	}
+
+	var file struct {
+		Chains []ChainConfig `json:"chains"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse chains file %s: %v", path, err)
+	}
+	for i := range file.Chains {
+		for j, rpcURL := range file.Chains[i].RPCURLs {
+			file.Chains[i].RPCURLs[j] = os.ExpandEnv(rpcURL)
+		}
+	}
+
+	registry, err := NewChainRegistry(file.Chains)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chains file %s: %v", path, err)
+	}
+	return registry, nil
+}
+
+// Chains returns the configured chains, the default one first
+func (r *ChainRegistry) Chains() []ChainConfig {
+	return append([]ChainConfig(nil), r.chains...)
+}
+
+// Default returns the default chain
+func (r *ChainRegistry) Default() ChainConfig {
+	return r.chains[0]
+}
+
+// Lookup returns the chain selected by a chain ID or name. An empty selector
+// selects the default chain; chains not in the registry are rejected with an
+// error wrapping ErrUnknownChain.
+func (r *ChainRegistry) Lookup(chain string) (ChainConfig, error) {
+	chain = strings.ToLower(strings.TrimSpace(chain))
+	if chain == "" {
+		return r.Default(), nil
+	}
+
+	id, err := strconv.ParseUint(chain, 10, 64)
+	for _, config := range r.chains {
+		if (err == nil && config.ID == id) || config.Name == chain {
+			return config, nil
+		}
+	}
+	return ChainConfig{}, fmt.Errorf("%w %q: configured chains are %s", ErrUnknownChain, chain, strings.Join(r.names(), ", "))
+}
+
+// names returns the names of the configured chains
+func (r *ChainRegistry) names() []string {
+	names := make([]string, 0, len(r.chains))
+	for _, chain := range r.chains {
+		names = append(names, chain.Name)
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+package ethereum
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewChainRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		chains  []ChainConfig
+		wantErr bool
+	}{
+		{name: "valid", chains: []ChainConfig{
+			{ID: 1, Name: "Ethereum", RPCURLs: []string{"https://eth.example"}},
+			{ID: 137, RPCURLs: []string{"https://polygon.example"}, MulticallAddress: DefaultMulticallAddress},
+			{ID: 8453, RPCURLs: []string{"https://base.example/v2/key"}, RateLimits: map[string]RateLimit{"base.example": {RequestsPerSecond: 25}}},
+		}},
+		{name: "empty", wantErr: true},
+		{name: "missing ID", chains: []ChainConfig{{Name: "ethereum", RPCURLs: []string{"https://eth.example"}}}, wantErr: true},
+		{name: "numeric name", chains: []ChainConfig{{ID: 1, Name: "137", RPCURLs: []string{"https://eth.example"}}}, wantErr: true},
+		{name: "missing RPC URLs", chains: []ChainConfig{{ID: 1, Name: "ethereum"}}, wantErr: true},
+		{name: "duplicate ID", chains: []ChainConfig{
+			{ID: 1, Name: "ethereum", RPCURLs: []string{"https://eth.example"}},
+			{ID: 1, Name: "mainnet", RPCURLs: []string{"https://eth.example"}},
+		}, wantErr: true},
+		{name: "duplicate name", chains: []ChainConfig{
+			{ID: 1, Name: "ethereum", RPCURLs: []string{"https://eth.example"}},
+			{ID: 5, Name: "ETHEREUM", RPCURLs: []string{"https://goerli.example"}},
+		}, wantErr: true},
+		{name: "invalid rate limit", chains: []ChainConfig{
+			{ID: 1, Name: "ethereum", RPCURLs: []string{"https://eth.example"}, RateLimits: map[string]RateLimit{"eth.example": {Burst: 5}}},
+		}, wantErr: true},
+		{name: "invalid Multicall address", chains: []ChainConfig{
+			{ID: 1, Name: "ethereum", RPCURLs: []string{"https://eth.example"}, MulticallAddress: "0x1234"},
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewChainRegistry(tt.chains)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("NewChainRegistry() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChainRegistryLookup(t *testing.T) {
+	registry, err := NewChainRegistry([]ChainConfig{
+		{ID: 1, Name: "Ethereum", RPCURLs: []string{"https://eth.example"}},
+		{ID: 137, RPCURLs: []string{"https://polygon.example"}},
+		{ID: 424242, RPCURLs: []string{"https://devnet.example"}},
+	})
+	if err != nil {
+		t.Fatalf("NewChainRegistry() error: %v", err)
+	}
+
+	tests := []struct {
+		chain   string
+		want    uint64
+		wantErr bool
+	}{
+		{chain: "", want: 1},
+		{chain: "1", want: 1},
+		{chain: "ethereum", want: 1},
+		{chain: " Polygon ", want: 137},
+		{chain: "137", want: 137},
+		{chain: "chain-424242", want: 424242},
+		{chain: "8453", wantErr: true},
+		{chain: "base", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.chain, func(t *testing.T) {
+			config, err := registry.Lookup(tt.chain)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownChain) {
+					t.Fatalf("Lookup(%q) = %+v, %v, want ErrUnknownChain", tt.chain, config, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Lookup(%q) error: %v", tt.chain, err)
+			}
+			if config.ID != tt.want {
+				t.Errorf("Lookup(%q) = chain %d, want %d", tt.chain, config.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadChainRegistry(t *testing.T) {
+	t.Setenv("TEST_ALCHEMY_KEY", "secret")
+
+	path := filepath.Join(t.TempDir(), "chains.json")
+	data := `{"chains": [
+		{"id": 8453, "name": "base", "rpc_urls": ["https://base.example/v2/${TEST_ALCHEMY_KEY}"], "confirmations": 5,
+			"rate_limits": {"Base.Example": {"requests_per_second": 25, "burst": 50}}},
+		{"id": 1, "name": "ethereum", "rpc_urls": ["https://eth.example"]}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := LoadChainRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadChainRegistry() error: %v", err)
+	}
+
+	base := registry.Default()
+	if base.ID != 8453 || base.Confirmations != 5 {
+		t.Errorf("Default() = %+v, want chain 8453 with 5 confirmations", base)
+	}
+	if want := "https://base.example/v2/secret"; base.RPCURLs[0] != want {
+		t.Errorf("RPC URL = %s, want %s", base.RPCURLs[0], want)
+	}
+	if limit, ok := base.RateLimit(base.RPCURLs[0]); !ok || limit.RequestsPerSecond != 25 || limit.Burst != 50 {
+		t.Errorf("RateLimit() = %+v, %v, want 25 requests per second with a burst of 50", limit, ok)
+	}
+	if got := len(registry.Chains()); got != 2 {
+		t.Errorf("Chains() has %d chains, want 2", got)
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
@@ -12,17 +15,52 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-// EthereumClient represents the Ethereum client
+// EthereumClient represents the Ethereum client. Requests go to one of the
+// RPC endpoints of its chain and fail over to the others when an endpoint is
+// unreachable, times out or answers with a server error.
 type EthereumClient struct {
-	client      *ethclient.Client
+	endpoints   []*endpoint
+	selection   string
 	contractABI abi.ABI
+	chainID     uint64
+	chain       ChainConfig
+
+	// next is the round-robin position
+	next atomic.Uint64
+
+	// rpcTimeout bounds every request to an endpoint, in nanoseconds
+	rpcTimeout atomic.Int64
+
+	// retryPolicy controls how failed requests are retried
+	retryPolicy atomic.Pointer[RetryPolicy]
+
+	// healthOnce, closeOnce and stopHealth control the background health
+	// checks
+	healthOnce sync.Once
+	closeOnce  sync.Once
+	stopHealth chan struct{}
+
+	// batchMu guards the batching configuration below
+	batchMu            sync.Mutex
+	multicallAddress   common.Address
+	multicallAvailable *bool
+	rpcBatchLimit      int
 }
 
-// NewEthereumClient creates a new Ethereum client
-func NewEthereumClient(rpcURL string) (*EthereumClient, error) {
-	client, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ethereum client: %v", err)
+// NewEthereumClient creates a new Ethereum client for whichever chain the RPC
+// URL serves
+func NewEthereumClient(ctx context.Context, rpcURL string) (*EthereumClient, error) {
+	return DialChain(ctx, ChainConfig{RPCURLs: []string{rpcURL}})
+}
+
+// DialChain creates a client for a chain with one or more RPC endpoints.
+// Endpoints are connected on first use and must report the configured chain
+// ID; endpoints serving another chain are skipped and fail with an error
+// wrapping ErrChainMismatch. If the configuration has no chain ID it is read
+// from the endpoints right away.
+func DialChain(ctx context.Context, config ChainConfig) (*EthereumClient, error) {
+	if len(config.RPCURLs) == 0 {
+		return nil, fmt.Errorf("chain %s has no RPC URLs", config.Name)
 	}
 
 	// ERC-721 ownerOf function ABI
@@ -41,24 +79,126 @@ func NewEthereumClient(rpcURL string) (*EthereumClient, error) {
 		return nil, fmt.Errorf("failed to parse contract ABI: %v", err)
 	}
 
-	return &EthereumClient{
-		client:      client,
-		contractABI: contractABI,
-	}, nil
+	ec := &EthereumClient{
+		selection:        config.Selection,
+		contractABI:      contractABI,
+		chainID:          config.ID,
+		stopHealth:       make(chan struct{}),
+		multicallAddress: common.HexToAddress(DefaultMulticallAddress),
+		rpcBatchLimit:    DefaultRPCBatchLimit,
+	}
+	ec.SetRPCTimeout(DefaultRPCTimeout)
+	ec.SetRetryPolicy(DefaultRetryPolicy)
+	for _, rpcURL := range config.RPCURLs {
+		ep := &endpoint{url: rpcURL}
+		if limit, ok := config.RateLimit(rpcURL); ok {
+			ep.setRateLimit(limit, true)
+		}
+		ec.endpoints = append(ec.endpoints, ep)
+	}
+
+	// Resolve the chain ID once so stored NFTs can be keyed by chain
+	if ec.chainID == 0 {
+		var chainID *big.Int
+		err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+			chainID, err = client.ChainID(ctx)
+			return err
+		})
+		if err != nil {
+			ec.Close()
+			return nil, fmt.Errorf("failed to get chain ID: %w", err)
+		}
+		ec.chainID = chainID.Uint64()
+		config.ID = ec.chainID
+		if config.Name == "" {
+			config.Name = ChainName(ec.chainID)
+		}
+	}
+	ec.chain = config
+
+	if config.MulticallAddress != "" {
+		if err := ec.SetMulticallAddress(config.MulticallAddress); err != nil {
+			ec.Close()
+			return nil, err
+		}
+	}
+	return ec, nil
 }
 
-// GetOwnerOf calls the ownerOf function on an ERC-721 contract
-func (ec *EthereumClient) GetOwnerOf(contractAddress string, tokenID uint) (string, error) {
-	// Convert contract address to common.Address
-	contractAddr := common.HexToAddress(contractAddress)
+// ChainID returns the ID of the chain the client is connected to
+func (ec *EthereumClient) ChainID() uint64 {
+	return ec.chainID
+}
 
-	// Convert tokenID to big.Int
-	tokenIDBig := big.NewInt(int64(tokenID))
+// SetRPCTimeout sets how long a single request to an endpoint may take before
+// it fails over to the next endpoint. Non-positive values restore
+// DefaultRPCTimeout.
+func (ec *EthereumClient) SetRPCTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultRPCTimeout
+	}
+	ec.rpcTimeout.Store(int64(timeout))
+}
+
+// RPCTimeout returns how long a single request to an endpoint may take
+func (ec *EthereumClient) RPCTimeout() time.Duration {
+	return time.Duration(ec.rpcTimeout.Load())
+}
+
+// SetRetryPolicy sets how requests that failed on every endpoint are
+// retried. A MaxRetries of zero or less disables retries; non-positive
+// backoffs are replaced by the ones of DefaultRetryPolicy.
+func (ec *EthereumClient) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxRetries < 0 {
+		policy.MaxRetries = 0
+	}
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = DefaultRetryPolicy.MinBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	if policy.MaxBackoff < policy.MinBackoff {
+		policy.MaxBackoff = policy.MinBackoff
+	}
+	ec.retryPolicy.Store(&policy)
+}
+
+// RetryPolicy returns how requests that failed on every endpoint are retried
+func (ec *EthereumClient) RetryPolicy() RetryPolicy {
+	return *ec.retryPolicy.Load()
+}
+
+// SetRateLimit limits the requests sent to each endpoint that has no rate
+// limit in the chain configuration. A zero limit removes the limit.
+func (ec *EthereumClient) SetRateLimit(limit RateLimit) {
+	for _, ep := range ec.endpoints {
+		ep.setRateLimit(limit, false)
+	}
+}
+
+// Chain returns the configuration of the chain the client is connected to
+func (ec *EthereumClient) Chain() ChainConfig {
+	return ec.chain
+}
+
+// GetOwnerOf calls the ownerOf function on an ERC-721 contract at the given
+// block and returns the owner along with the number of the block it was read at.
+// If the call reverts the error wraps one of the typed revert errors, e.g. a
+// *NonexistentTokenError, and the block number is still returned.
+// Malformed contract addresses are rejected with an error wrapping
+// ErrInvalidAddress before any call is made.
+func (ec *EthereumClient) GetOwnerOf(ctx context.Context, contractAddress string, tokenID *big.Int, block BlockRef) (string, uint64, error) {
+	// Convert contract address to common.Address
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return "", 0, err
+	}
 
 	// Prepare the call data
-	data, err := ec.contractABI.Pack("ownerOf", tokenIDBig)
+	data, err := ec.contractABI.Pack("ownerOf", tokenID)
 	if err != nil {
-		return "", fmt.Errorf("failed to pack function call: %v", err)
+		return "", 0, fmt.Errorf("failed to pack function call: %v", err)
 	}
 
 	// Create the call message
@@ -67,23 +207,42 @@ func (ec *EthereumClient) GetOwnerOf(contractAddress string, tokenID uint) (stri
 		Data: data,
 	}
 
-	// Make the call
-	result, err := ec.client.CallContract(context.Background(), msg, nil)
+	// Make the call pinned to a single block
+	result, blockNumber, err := ec.callAt(ctx, msg, block)
 	if err != nil {
-		return "", fmt.Errorf("failed to call contract: %v", err)
+		return "", blockNumber, fmt.Errorf("failed to call contract: %w", decodeCallError(err))
 	}
 
 	// Unpack the result
+	if len(result) == 0 {
+		return "", blockNumber, fmt.Errorf("failed to unpack result: %w", ErrEmptyResult)
+	}
 	var owner common.Address
 	err = ec.contractABI.UnpackIntoInterface(&owner, "ownerOf", result)
 	if err != nil {
-		return "", fmt.Errorf("failed to unpack result: %v", err)
+		return "", 0, fmt.Errorf("failed to unpack result: %v", err)
 	}
 
-	return owner.Hex(), nil
+	return owner.Hex(), blockNumber, nil
 }
 
-// Close closes the Ethereum client connection
+// BlockNumber returns the number of the latest block
+func (ec *EthereumClient) BlockNumber(ctx context.Context) (uint64, error) {
+	var blockNumber uint64
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		blockNumber, err = client.BlockNumber(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	return blockNumber, nil
+}
+
+// Close stops the health checks and closes the connections to every endpoint
 func (ec *EthereumClient) Close() {
-	ec.client.Close()
+	ec.closeOnce.Do(func() { close(ec.stopHealth) })
+	for _, ep := range ec.endpoints {
+		ep.close()
+	}
 }
@@ -0,0 +1,102 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// collectionABI holds the optional collection-level functions of ERC-721
+// Metadata, ERC-721 Enumerable and the OpenSea contractURI convention
+var collectionABI = mustParseABI(`[
+	{"type": "function", "name": "name", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "string"}]},
+	{"type": "function", "name": "symbol", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "string"}]},
+	{"type": "function", "name": "totalSupply", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "uint256"}]},
+	{"type": "function", "name": "contractURI", "stateMutability": "view", "inputs": [], "outputs": [{"name": "", "type": "string"}]}
+]`)
+
+// CollectionInfo holds the collection-level data of an NFT contract. Every
+// field is optional: functions a contract does not implement leave their
+// field empty, and TotalSupply nil.
+type CollectionInfo struct {
+	Name        string
+	Symbol      string
+	TotalSupply *big.Int
+	ContractURI string
+}
+
+// GetCollectionInfo reads name, symbol and contractURI of a contract at the
+// latest block, and totalSupply if enumerable is set since only ERC-721
+// Enumerable defines it. Reverting or missing functions are skipped.
+func (ec *EthereumClient) GetCollectionInfo(ctx context.Context, address common.Address, enumerable bool) (CollectionInfo, error) {
+	var info CollectionInfo
+
+	var err error
+	if info.Name, err = ec.collectionString(ctx, address, "name"); err != nil {
+		return CollectionInfo{}, err
+	}
+	if info.Symbol, err = ec.collectionString(ctx, address, "symbol"); err != nil {
+		return CollectionInfo{}, err
+	}
+	if info.ContractURI, err = ec.collectionString(ctx, address, "contractURI"); err != nil {
+		return CollectionInfo{}, err
+	}
+
+	if enumerable {
+		result, err := ec.collectionCall(ctx, address, "totalSupply")
+		if err != nil {
+			return CollectionInfo{}, err
+		}
+		if len(result) == 32 {
+			info.TotalSupply = new(big.Int).SetBytes(result)
+		}
+	}
+
+	return info, nil
+}
+
+// collectionString calls a string getter
+func (ec *EthereumClient) collectionString(ctx context.Context, address common.Address, method string) (string, error) {
+	result, err := ec.collectionCall(ctx, address, method)
+	if err != nil {
+		return "", err
+	}
+	return decodeCollectionString(method, result), nil
+}
+
+// decodeCollectionString decodes the result of a string getter. Some early
+// contracts return bytes32 instead of string, which is decoded as a
+// zero-padded string. Other malformed results yield an empty string.
+func decodeCollectionString(method string, result []byte) string {
+	var value string
+	if err := collectionABI.UnpackIntoInterface(&value, method, result); err == nil {
+		return value
+	}
+	if len(result) == 32 {
+		return string(bytes.TrimRight(result, "\x00"))
+	}
+	return ""
+}
+
+// collectionCall calls a getter without arguments at the latest block.
+// Reverts yield an empty result since the getters are optional.
+func (ec *EthereumClient) collectionCall(ctx context.Context, address common.Address, method string) ([]byte, error) {
+	data, err := collectionABI.Pack(method)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s call: %v", method, err)
+	}
+
+	result, err := ec.call(ctx, ethereum.CallMsg{To: &address, Data: data})
+	if err != nil {
+		err = decodeCallError(err)
+		if IsRevert(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to call %s of %s: %w", method, address.Hex(), err)
+	}
+	return result, nil
+}
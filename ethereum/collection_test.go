@@ -0,0 +1,36 @@
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDecodeCollectionString(t *testing.T) {
+	packed, err := collectionABI.Methods["name"].Outputs.Pack("BoredApeYachtClub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var bytes32 common.Hash
+	copy(bytes32[:], "MKR")
+
+	tests := []struct {
+		name   string
+		result []byte
+		want   string
+	}{
+		{name: "string", result: packed, want: "BoredApeYachtClub"},
+		{name: "bytes32", result: bytes32.Bytes(), want: "MKR"},
+		{name: "empty", result: nil, want: ""},
+		{name: "malformed", result: []byte{0x01, 0x02}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeCollectionString("name", tt.result); got != tt.want {
+				t.Errorf("decodeCollectionString = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
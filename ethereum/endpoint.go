@@ -0,0 +1,368 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Endpoint selection strategies. Priority uses the first healthy endpoint in
+// configuration order and only moves on when it fails; round-robin rotates
+// through the healthy endpoints; latency picks healthy endpoints at random,
+// weighted by the inverse of their average latency.
+const (
+	SelectionPriority   = "priority"
+	SelectionRoundRobin = "round-robin"
+	SelectionLatency    = "latency"
+)
+
+const (
+	// DefaultHealthCheckInterval is how often endpoints are probed by
+	// default when health checks run in the background
+	DefaultHealthCheckInterval = 30 * time.Second
+
+	// DefaultRPCTimeout bounds a single request to an endpoint by default so
+	// that a hanging endpoint fails over instead of blocking the caller
+	DefaultRPCTimeout = 30 * time.Second
+
+	// endpointCooldown is how long a failed endpoint is skipped while other
+	// endpoints are healthy, unless a health check succeeds earlier
+	endpointCooldown = 30 * time.Second
+
+	// latencyWeight is the weight of a new sample in the moving average of
+	// an endpoint's latency
+	latencyWeight = 0.2
+)
+
+// EndpointStats describes the health and usage of an RPC endpoint. URL is
+// redacted to its scheme and host since paths usually embed API keys.
+type EndpointStats struct {
+	URL           string
+	Healthy       bool
+	Requests      uint64
+	Failures      uint64
+	AvgLatency    time.Duration
+	HeadBlock     uint64
+	LastError     string
+	LastErrorAt   time.Time
+	LastCheckedAt time.Time
+}
+
+// endpoint is a single RPC endpoint of a chain together with its health
+type endpoint struct {
+	url string
+
+	// dialMu serializes dialing and chain ID verification, which perform
+	// network I/O and must not block readers of the stats below
+	dialMu   sync.Mutex
+	client   *ethclient.Client
+	verified bool
+
+	mu            sync.Mutex
+	mismatch      error
+	downUntil     time.Time
+	retryAfter    time.Time
+	limiter       *tokenBucket
+	ownLimit      bool
+	requests      uint64
+	failures      uint64
+	avgLatency    time.Duration
+	headBlock     uint64
+	lastError     string
+	lastErrorAt   time.Time
+	lastCheckedAt time.Time
+}
+
+// connect dials the endpoint if needed and, once chainID is known, verifies
+// that the endpoint serves that chain. Endpoints serving another chain are
+// disabled for good.
+func (ep *endpoint) connect(ctx context.Context, chainID uint64) (*ethclient.Client, error) {
+	ep.dialMu.Lock()
+	defer ep.dialMu.Unlock()
+
+	if err := ep.mismatchError(); err != nil {
+		return nil, err
+	}
+	if ep.client == nil {
+		httpClient := &http.Client{Transport: &retryAfterTransport{ep: ep, base: http.DefaultTransport}}
+		client, err := rpc.DialOptions(ctx, ep.url, rpc.WithHTTPClient(httpClient))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect: %w", err)
+		}
+		ep.client = ethclient.NewClient(client)
+	}
+	if ep.verified || chainID == 0 {
+		return ep.client, nil
+	}
+
+	served, err := ep.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if served.Uint64() != chainID {
+		err := fmt.Errorf("%w: endpoint serves chain %d, expected %d", ErrChainMismatch, served.Uint64(), chainID)
+		ep.mu.Lock()
+		ep.mismatch = err
+		ep.mu.Unlock()
+		return nil, err
+	}
+	ep.verified = true
+	return ep.client, nil
+}
+
+// mismatchError returns the error of an endpoint disabled for serving another chain
+func (ep *endpoint) mismatchError() error {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.mismatch
+}
+
+// available reports whether the endpoint may be selected while other
+// endpoints are healthy
+func (ep *endpoint) available(now time.Time) bool {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.mismatch == nil && !now.Before(ep.downUntil)
+}
+
+// usable reports whether the endpoint may be selected at all
+func (ep *endpoint) usable() bool {
+	return ep.mismatchError() == nil
+}
+
+// setRateLimit limits the requests sent to the endpoint. Unless own is set,
+// the limit only applies if the endpoint has no limit of its own.
+func (ep *endpoint) setRateLimit(limit RateLimit, own bool) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if ep.ownLimit && !own {
+		return
+	}
+	ep.limiter = newTokenBucket(limit)
+	ep.ownLimit = own
+}
+
+// wait takes n tokens from the rate limiter of the endpoint, waiting until
+// they are available or ctx is done
+func (ep *endpoint) wait(ctx context.Context, n int) error {
+	ep.mu.Lock()
+	limiter := ep.limiter
+	ep.mu.Unlock()
+	return limiter.wait(ctx, n)
+}
+
+// setRetryAfter records when the provider accepts requests again after
+// rejecting one with a Retry-After header
+func (ep *endpoint) setRetryAfter(until time.Time) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	ep.retryAfter = until
+}
+
+// retryAfterWait returns how long the provider asked to wait before the next
+// request, zero if it did not
+func (ep *endpoint) retryAfterWait(now time.Time) time.Duration {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if now.Before(ep.retryAfter) {
+		return ep.retryAfter.Sub(now)
+	}
+	return 0
+}
+
+// latency returns the average latency of the endpoint, zero if unmeasured
+func (ep *endpoint) latency() time.Duration {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	return ep.avgLatency
+}
+
+// recordSuccess records a request that reached the endpoint
+func (ep *endpoint) recordSuccess(latency time.Duration) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	ep.requests++
+	ep.downUntil = time.Time{}
+	if ep.avgLatency == 0 {
+		ep.avgLatency = latency
+	} else {
+		ep.avgLatency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(ep.avgLatency))
+	}
+}
+
+// recordFailure records a request that failed because of the endpoint and
+// skips the endpoint for the cooldown, or until the time the provider asked
+// to wait for with a Retry-After header
+func (ep *endpoint) recordFailure(err error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	ep.requests++
+	ep.failures++
+	ep.lastError = redactError(ep.url, err)
+	ep.lastErrorAt = time.Now()
+	ep.downUntil = ep.lastErrorAt.Add(endpointCooldown)
+	if ep.retryAfter.After(ep.lastErrorAt) {
+		ep.downUntil = ep.retryAfter
+	}
+}
+
+// check probes the endpoint with eth_blockNumber, bounded by timeout, and
+// records the outcome. Probes cut short by ctx are not recorded.
+func (ep *endpoint) check(ctx context.Context, chainID uint64, timeout time.Duration) {
+	if ep.wait(ctx, 1) != nil {
+		return
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	client, err := ep.connect(checkCtx, chainID)
+	var head uint64
+	if err == nil {
+		head, err = client.BlockNumber(checkCtx)
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		ep.recordFailure(err)
+	} else {
+		ep.recordSuccess(time.Since(start))
+	}
+
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	ep.lastCheckedAt = time.Now()
+	if err == nil {
+		ep.headBlock = head
+	}
+}
+
+// stats returns a snapshot of the endpoint's health and usage
+func (ep *endpoint) stats(now time.Time) EndpointStats {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	return EndpointStats{
+		URL:           redactURL(ep.url),
+		Healthy:       ep.mismatch == nil && !now.Before(ep.downUntil),
+		Requests:      ep.requests,
+		Failures:      ep.failures,
+		AvgLatency:    ep.avgLatency,
+		HeadBlock:     ep.headBlock,
+		LastError:     ep.lastError,
+		LastErrorAt:   ep.lastErrorAt,
+		LastCheckedAt: ep.lastCheckedAt,
+	}
+}
+
+// close closes the connection of the endpoint if it was dialed
+func (ep *endpoint) close() {
+	ep.dialMu.Lock()
+	defer ep.dialMu.Unlock()
+
+	if ep.client != nil {
+		ep.client.Close()
+		ep.client = nil
+		ep.verified = false
+	}
+}
+
+// isEndpointError reports whether err means that the endpoint rather than
+// the request is at fault, so that the request may succeed on another
+// endpoint: transport errors, timeouts, rate limits, 5xx responses and
+// endpoints serving the wrong chain. Reverts, other JSON-RPC errors and
+// cancellation by the caller are not.
+func isEndpointError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrChainMismatch) || IsRateLimited(err) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	return isTransportError(err)
+}
+
+// isTransportError reports whether err is a connection failure
+func isTransportError(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, rpc.ErrClientQuit)
+}
+
+// endpointError is a failure of an endpoint. Its message names the endpoint
+// by its redacted URL and hides the full URL that transport errors embed.
+type endpointError struct {
+	url string
+	err error
+}
+
+func (e *endpointError) Error() string {
+	return redactURL(e.url) + ": " + redactError(e.url, e.err)
+}
+
+func (e *endpointError) Unwrap() error {
+	return e.err
+}
+
+// redactError returns the message of err with rawURL redacted
+func redactError(rawURL string, err error) string {
+	return strings.ReplaceAll(err.Error(), rawURL, redactURL(rawURL))
+}
+
+// endpointHost returns the lowercased host name of an RPC URL, the key of its
+// rate limit in a chain configuration
+func endpointHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// redactURL returns the scheme and host of an RPC URL, hiding credentials,
+// paths and queries that usually embed API keys. IPC paths are returned as is.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	if u.Scheme == "" {
+		return rawURL
+	}
+	redacted := u.Scheme + "://" + u.Host
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		redacted += "/..."
+	}
+	return redacted
+}
@@ -0,0 +1,167 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ENSRegistryAddress is the address of the ENS registry on Ethereum mainnet
+// and its testnets
+const ENSRegistryAddress = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+
+// ErrENSNotFound is returned when an ENS name has no address, or an address
+// has no primary name that resolves back to it
+var ErrENSNotFound = errors.New("ENS name not found")
+
+// ensABI holds the registry and resolver functions used for resolution
+var ensABI = mustParseABI(`[
+	{"type": "function", "name": "resolver", "stateMutability": "view", "inputs": [{"name": "node", "type": "bytes32"}], "outputs": [{"name": "", "type": "address"}]},
+	{"type": "function", "name": "addr", "stateMutability": "view", "inputs": [{"name": "node", "type": "bytes32"}], "outputs": [{"name": "", "type": "address"}]},
+	{"type": "function", "name": "name", "stateMutability": "view", "inputs": [{"name": "node", "type": "bytes32"}], "outputs": [{"name": "", "type": "string"}]}
+]`)
+
+// IsENSName reports whether s looks like an ENS name such as
+// boredapeyachtclub.eth rather than a hex address
+func IsENSName(s string) bool {
+	_, err := NormalizeENSName(s)
+	return err == nil
+}
+
+// NormalizeENSName lowercases an ENS name and checks that it consists of
+// non-empty dot separated labels. Full ENSIP-15 normalization of unicode
+// names is not performed, so such names must be entered normalized.
+func NormalizeENSName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return "", fmt.Errorf("invalid ENS name %q: expected a name such as example.eth", name)
+	}
+	for _, label := range labels {
+		if label == "" || strings.ContainsAny(label, " \t/:") {
+			return "", fmt.Errorf("invalid ENS name %q: empty or malformed label", name)
+		}
+	}
+	return name, nil
+}
+
+// NameHash computes the EIP-137 namehash of a normalized ENS name
+func NameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash)
+	}
+	return node
+}
+
+// reverseNode returns the node of the reverse record of an address
+func reverseNode(address common.Address) common.Hash {
+	return NameHash(strings.ToLower(address.Hex()[2:]) + ".addr.reverse")
+}
+
+// ResolveName resolves an ENS name to an address by asking the registry for
+// the name's resolver and the resolver for its address. Names without a
+// resolver or address yield an error wrapping ErrENSNotFound.
+func (ec *EthereumClient) ResolveName(ctx context.Context, name string) (common.Address, error) {
+	name, err := NormalizeENSName(name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	node := NameHash(name)
+
+	resolver, err := ec.ensResolver(ctx, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+
+	var address common.Address
+	if err := ec.ensCall(ctx, resolver, "addr", node, &address); err != nil {
+		return common.Address{}, fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%s has no address: %w", name, ErrENSNotFound)
+	}
+
+	return address, nil
+}
+
+// LookupAddress returns the primary ENS name of an address. The reverse
+// record is only trusted if the name resolves back to the address, since
+// anyone can claim any name in their reverse record.
+func (ec *EthereumClient) LookupAddress(ctx context.Context, address common.Address) (string, error) {
+	node := reverseNode(address)
+
+	resolver, err := ec.ensResolver(ctx, node)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up name of %s: %w", address.Hex(), err)
+	}
+
+	var name string
+	if err := ec.ensCall(ctx, resolver, "name", node, &name); err != nil {
+		return "", fmt.Errorf("failed to look up name of %s: %w", address.Hex(), err)
+	}
+	if name == "" {
+		return "", fmt.Errorf("%s has no primary name: %w", address.Hex(), ErrENSNotFound)
+	}
+
+	// forward verification
+	resolved, err := ec.ResolveName(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if resolved != address {
+		return "", fmt.Errorf("primary name %s of %s resolves to %s: %w", name, address.Hex(), resolved.Hex(), ErrENSNotFound)
+	}
+
+	return name, nil
+}
+
+// ensResolver returns the resolver the registry stores for node
+func (ec *EthereumClient) ensResolver(ctx context.Context, node common.Hash) (common.Address, error) {
+	var resolver common.Address
+	if err := ec.ensCall(ctx, common.HexToAddress(ENSRegistryAddress), "resolver", node, &resolver); err != nil {
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("no resolver: %w", ErrENSNotFound)
+	}
+	return resolver, nil
+}
+
+// ensCall calls a registry or resolver function taking a node at the latest
+// block and unpacks its single return value into out. Reverts and empty
+// results, e.g. on chains without ENS, are reported as ErrENSNotFound.
+func (ec *EthereumClient) ensCall(ctx context.Context, to common.Address, method string, node common.Hash, out interface{}) error {
+	data, err := ensABI.Pack(method, node)
+	if err != nil {
+		return fmt.Errorf("failed to pack %s call: %v", method, err)
+	}
+
+	result, err := ec.call(ctx, ethereum.CallMsg{To: &to, Data: data})
+	if err != nil {
+		err = decodeCallError(err)
+		if IsRevert(err) {
+			return fmt.Errorf("%s reverted: %w", method, ErrENSNotFound)
+		}
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	if len(result) == 0 {
+		return fmt.Errorf("no %s at %s: %w", method, to.Hex(), ErrENSNotFound)
+	}
+
+	if err := ensABI.UnpackIntoInterface(out, method, result); err != nil {
+		return fmt.Errorf("failed to unpack %s result: %v", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package ethereum
+
+import (
+	"testing"
+)
+
+func TestNameHash(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "", want: "0x0000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "eth", want: "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{name: "foo.eth", want: "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NameHash(tt.name).Hex(); got != tt.want {
+				t.Errorf("NameHash(%q) = %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeENSName(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "boredapeyachtclub.eth", want: "boredapeyachtclub.eth"},
+		{input: " BoredApeYachtClub.ETH ", want: "boredapeyachtclub.eth"},
+		{input: "sub.vitalik.eth", want: "sub.vitalik.eth"},
+		{input: "eth", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "foo..eth", wantErr: true},
+		{input: ".eth", wantErr: true},
+		{input: "https://foo.eth", wantErr: true},
+		{input: "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := NormalizeENSName(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NormalizeENSName(%q) = %q, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeENSName(%q) error: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeENSName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if !IsENSName(tt.input) {
+				t.Errorf("IsENSName(%q) = false, want true", tt.input)
+			}
+		})
+	}
+}
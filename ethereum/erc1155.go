@@ -0,0 +1,229 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Topics of the ERC-1155 transfer events
+var (
+	TransferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	TransferBatchTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+// erc1155ABI is the subset of ERC-1155 used to read and decode balances
+var erc1155ABI = mustParseABI(`[
+	{"type": "function", "name": "balanceOf", "stateMutability": "view", "inputs": [{"name": "account", "type": "address"}, {"name": "id", "type": "uint256"}], "outputs": [{"name": "", "type": "uint256"}]},
+	{"type": "function", "name": "balanceOfBatch", "stateMutability": "view", "inputs": [{"name": "accounts", "type": "address[]"}, {"name": "ids", "type": "uint256[]"}], "outputs": [{"name": "", "type": "uint256[]"}]},
+	{"type": "event", "name": "TransferSingle", "inputs": [{"name": "operator", "type": "address", "indexed": true}, {"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "id", "type": "uint256"}, {"name": "value", "type": "uint256"}]},
+	{"type": "event", "name": "TransferBatch", "inputs": [{"name": "operator", "type": "address", "indexed": true}, {"name": "from", "type": "address", "indexed": true}, {"name": "to", "type": "address", "indexed": true}, {"name": "ids", "type": "uint256[]"}, {"name": "values", "type": "uint256[]"}]}
+]`)
+
+// DefaultBalanceBatchSize is the default number of (holder, token) pairs read
+// with a single balanceOfBatch call
+const DefaultBalanceBatchSize = 500
+
+// BalanceTransfer is a movement of an ERC-1155 token decoded from a
+// TransferSingle or TransferBatch event. A TransferBatch event yields one
+// BalanceTransfer per token. Mints come from and burns go to the zero address.
+type BalanceTransfer struct {
+	Operator    common.Address
+	From        common.Address
+	To          common.Address
+	TokenID     *big.Int
+	Value       *big.Int
+	BlockNumber uint64
+	BlockHash   common.Hash
+	TxHash      common.Hash
+	LogIndex    uint
+	Removed     bool
+}
+
+// DecodeBalanceTransfers decodes an ERC-1155 TransferSingle or TransferBatch log
+func DecodeBalanceTransfers(log types.Log) ([]BalanceTransfer, error) {
+	if len(log.Topics) != 4 || (log.Topics[0] != TransferSingleTopic && log.Topics[0] != TransferBatchTopic) {
+		return nil, fmt.Errorf("log %d of tx %s is not an ERC-1155 transfer event", log.Index, log.TxHash.Hex())
+	}
+
+	base := BalanceTransfer{
+		Operator:    common.BytesToAddress(log.Topics[1].Bytes()),
+		From:        common.BytesToAddress(log.Topics[2].Bytes()),
+		To:          common.BytesToAddress(log.Topics[3].Bytes()),
+		BlockNumber: log.BlockNumber,
+		BlockHash:   log.BlockHash,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+		Removed:     log.Removed,
+	}
+
+	if log.Topics[0] == TransferSingleTopic {
+		var event struct {
+			Id    *big.Int
+			Value *big.Int
+		}
+		if err := erc1155ABI.UnpackIntoInterface(&event, "TransferSingle", log.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode TransferSingle log %d of tx %s: %v", log.Index, log.TxHash.Hex(), err)
+		}
+		base.TokenID = event.Id
+		base.Value = event.Value
+		return []BalanceTransfer{base}, nil
+	}
+
+	var event struct {
+		Ids    []*big.Int
+		Values []*big.Int
+	}
+	if err := erc1155ABI.UnpackIntoInterface(&event, "TransferBatch", log.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode TransferBatch log %d of tx %s: %v", log.Index, log.TxHash.Hex(), err)
+	}
+	if len(event.Ids) != len(event.Values) {
+		return nil, fmt.Errorf("TransferBatch log %d of tx %s has %d ids but %d values", log.Index, log.TxHash.Hex(), len(event.Ids), len(event.Values))
+	}
+
+	transfers := make([]BalanceTransfer, 0, len(event.Ids))
+	for i := range event.Ids {
+		transfer := base
+		transfer.TokenID = event.Ids[i]
+		transfer.Value = event.Values[i]
+		transfers = append(transfers, transfer)
+	}
+	return transfers, nil
+}
+
+// FilterBalanceTransfers returns the ERC-1155 token movements of a contract
+// between fromBlock and toBlock (inclusive) in chain order
+func (ec *EthereumClient) FilterBalanceTransfers(ctx context.Context, contractAddress string, fromBlock, toBlock uint64) ([]BalanceTransfer, error) {
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{contractAddr},
+		Topics:    [][]common.Hash{{TransferSingleTopic, TransferBatchTopic}},
+	}
+	logs, err := ec.filterLogs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter ERC-1155 transfer logs in blocks %d-%d: %w", fromBlock, toBlock, err)
+	}
+
+	var transfers []BalanceTransfer
+	for _, log := range logs {
+		decoded, err := DecodeBalanceTransfers(log)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, decoded...)
+	}
+	return transfers, nil
+}
+
+// GetBalanceOf calls balanceOf on an ERC-1155 contract at the given block and
+// returns the balance of holder along with the number of the block it was
+// read at
+func (ec *EthereumClient) GetBalanceOf(ctx context.Context, contractAddress, holder string, tokenID *big.Int, block BlockRef) (*big.Int, uint64, error) {
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	holderAddr, err := ParseAddress(holder)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	data, err := erc1155ABI.Pack("balanceOf", holderAddr, tokenID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to pack function call: %v", err)
+	}
+
+	result, blockNumber, err := ec.callAt(ctx, ethereum.CallMsg{To: &contractAddr, Data: data}, block)
+	if err != nil {
+		return nil, blockNumber, fmt.Errorf("failed to call contract: %w", decodeCallError(err))
+	}
+	if len(result) == 0 {
+		return nil, blockNumber, fmt.Errorf("failed to unpack result: %w", ErrEmptyResult)
+	}
+
+	var balance *big.Int
+	if err := erc1155ABI.UnpackIntoInterface(&balance, "balanceOf", result); err != nil {
+		return nil, 0, fmt.Errorf("failed to unpack result: %v", err)
+	}
+	return balance, blockNumber, nil
+}
+
+// GetBalancesOf reads the balances of many (holder, token) pairs of an
+// ERC-1155 contract with balanceOfBatch, batchSize pairs per call. holders
+// and tokenIDs must have the same length. All calls are pinned to the same
+// block, whose number is returned.
+func (ec *EthereumClient) GetBalancesOf(ctx context.Context, contractAddress string, holders []string, tokenIDs []*big.Int, block BlockRef, batchSize int) ([]*big.Int, uint64, error) {
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(holders) != len(tokenIDs) {
+		return nil, 0, fmt.Errorf("got %d holders but %d token IDs", len(holders), len(tokenIDs))
+	}
+
+	holderAddrs := make([]common.Address, 0, len(holders))
+	for _, holder := range holders {
+		holderAddr, err := ParseAddress(holder)
+		if err != nil {
+			return nil, 0, err
+		}
+		holderAddrs = append(holderAddrs, holderAddr)
+	}
+
+	if batchSize <= 0 {
+		batchSize = DefaultBalanceBatchSize
+	}
+
+	// Resolve the block once so every batch reads the same state
+	blockNumber, err := ec.ResolveBlock(ctx, block)
+	if err != nil {
+		return nil, 0, err
+	}
+	pinned := BlockAt(blockNumber)
+	if block.Hash != nil {
+		pinned = block
+	}
+
+	balances := make([]*big.Int, 0, len(holders))
+	for start := 0; start < len(holders); start += batchSize {
+		end := start + batchSize
+		if end > len(holders) {
+			end = len(holders)
+		}
+
+		data, err := erc1155ABI.Pack("balanceOfBatch", holderAddrs[start:end], tokenIDs[start:end])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to pack function call: %v", err)
+		}
+
+		result, _, err := ec.callAt(ctx, ethereum.CallMsg{To: &contractAddr, Data: data}, pinned)
+		if err != nil {
+			return nil, blockNumber, fmt.Errorf("failed to call contract: %w", decodeCallError(err))
+		}
+		if len(result) == 0 {
+			return nil, blockNumber, fmt.Errorf("failed to unpack result: %w", ErrEmptyResult)
+		}
+
+		var batch []*big.Int
+		if err := erc1155ABI.UnpackIntoInterface(&batch, "balanceOfBatch", result); err != nil {
+			return nil, 0, fmt.Errorf("failed to unpack result: %v", err)
+		}
+		if len(batch) != end-start {
+			return nil, 0, fmt.Errorf("balanceOfBatch returned %d balances for %d pairs", len(batch), end-start)
+		}
+		balances = append(balances, batch...)
+	}
+
+	return balances, blockNumber, nil
+}
@@ -0,0 +1,104 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// balanceTransferLog builds an ERC-1155 transfer log of event with data
+// packed from args
+func balanceTransferLog(t *testing.T, event string, topic common.Hash, operator, from, to common.Address, args ...interface{}) types.Log {
+	t.Helper()
+
+	data, err := erc1155ABI.Events[event].Inputs.NonIndexed().Pack(args...)
+	if err != nil {
+		t.Fatalf("failed to pack %s data: %v", event, err)
+	}
+	return types.Log{
+		Topics: []common.Hash{
+			topic,
+			common.BytesToHash(operator.Bytes()),
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data:        data,
+		BlockNumber: 7,
+		Index:       3,
+	}
+}
+
+func TestDecodeBalanceTransfers(t *testing.T) {
+	operator := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	from := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	to := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	t.Run("TransferSingle", func(t *testing.T) {
+		log := balanceTransferLog(t, "TransferSingle", TransferSingleTopic, operator, from, to, big.NewInt(42), big.NewInt(5))
+
+		transfers, err := DecodeBalanceTransfers(log)
+		if err != nil {
+			t.Fatalf("DecodeBalanceTransfers() error: %v", err)
+		}
+		if len(transfers) != 1 {
+			t.Fatalf("DecodeBalanceTransfers() returned %d transfers, want 1", len(transfers))
+		}
+		got := transfers[0]
+		if got.Operator != operator || got.From != from || got.To != to {
+			t.Errorf("addresses = %s %s %s, want %s %s %s", got.Operator.Hex(), got.From.Hex(), got.To.Hex(), operator.Hex(), from.Hex(), to.Hex())
+		}
+		if got.TokenID.Int64() != 42 || got.Value.Int64() != 5 {
+			t.Errorf("token %s value %s, want token 42 value 5", got.TokenID, got.Value)
+		}
+		if got.BlockNumber != 7 || got.LogIndex != 3 {
+			t.Errorf("block %d log %d, want block 7 log 3", got.BlockNumber, got.LogIndex)
+		}
+	})
+
+	t.Run("TransferBatch", func(t *testing.T) {
+		ids := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+		values := []*big.Int{big.NewInt(10), big.NewInt(20), big.NewInt(30)}
+		log := balanceTransferLog(t, "TransferBatch", TransferBatchTopic, operator, common.Address{}, to, ids, values)
+
+		transfers, err := DecodeBalanceTransfers(log)
+		if err != nil {
+			t.Fatalf("DecodeBalanceTransfers() error: %v", err)
+		}
+		if len(transfers) != len(ids) {
+			t.Fatalf("DecodeBalanceTransfers() returned %d transfers, want %d", len(transfers), len(ids))
+		}
+		for i, transfer := range transfers {
+			if transfer.TokenID.Cmp(ids[i]) != 0 || transfer.Value.Cmp(values[i]) != 0 {
+				t.Errorf("transfer %d = token %s value %s, want token %s value %s", i, transfer.TokenID, transfer.Value, ids[i], values[i])
+			}
+			if transfer.From != (common.Address{}) || transfer.To != to {
+				t.Errorf("transfer %d = %s -> %s, want mint to %s", i, transfer.From.Hex(), transfer.To.Hex(), to.Hex())
+			}
+		}
+	})
+
+	t.Run("mismatched batch", func(t *testing.T) {
+		log := balanceTransferLog(t, "TransferBatch", TransferBatchTopic, operator, from, to,
+			[]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(1)})
+		if _, err := DecodeBalanceTransfers(log); err == nil {
+			t.Errorf("DecodeBalanceTransfers() succeeded, want error")
+		}
+	})
+
+	t.Run("ERC-721 Transfer", func(t *testing.T) {
+		log := types.Log{Topics: []common.Hash{TransferEventTopic, {}, {}, {}}}
+		if _, err := DecodeBalanceTransfers(log); err == nil {
+			t.Errorf("DecodeBalanceTransfers() succeeded, want error")
+		}
+	})
+
+	t.Run("truncated data", func(t *testing.T) {
+		log := balanceTransferLog(t, "TransferSingle", TransferSingleTopic, operator, from, to, big.NewInt(42), big.NewInt(5))
+		log.Data = log.Data[:40]
+		if _, err := DecodeBalanceTransfers(log); err == nil {
+			t.Errorf("DecodeBalanceTransfers() succeeded, want error")
+		}
+	})
+}
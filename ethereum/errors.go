@@ -0,0 +1,275 @@
+package ethereum
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// errorSelector is the selector of Error(string)
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0}
+
+	// panicSelector is the selector of Panic(uint256)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71}
+
+	// ownerQueryForNonexistentTokenSelector is the selector of the ERC721A
+	// error OwnerQueryForNonexistentToken()
+	ownerQueryForNonexistentTokenSelector = []byte{0xdf, 0x2d, 0x9b, 0x42}
+)
+
+// ErrEmptyResult is returned when a call succeeds without returning data,
+// typically because the address has no code or does not implement the called
+// function
+var ErrEmptyResult = errors.New("call returned no data")
+
+// limitExceededCode is the JSON-RPC error code (EIP-1474) providers use when a
+// request exceeds a rate limit
+const limitExceededCode = -32005
+
+// erc6093ABI holds the ERC-721 custom errors standardized by ERC-6093
+var erc6093ABI = mustParseABI(`[
+	{"type": "error", "name": "ERC721InvalidOwner", "inputs": [{"name": "owner", "type": "address"}]},
+	{"type": "error", "name": "ERC721NonexistentToken", "inputs": [{"name": "tokenId", "type": "uint256"}]},
+	{"type": "error", "name": "ERC721IncorrectOwner", "inputs": [{"name": "sender", "type": "address"}, {"name": "tokenId", "type": "uint256"}, {"name": "owner", "type": "address"}]},
+	{"type": "error", "name": "ERC721InvalidSender", "inputs": [{"name": "sender", "type": "address"}]},
+	{"type": "error", "name": "ERC721InvalidReceiver", "inputs": [{"name": "receiver", "type": "address"}]},
+	{"type": "error", "name": "ERC721InsufficientApproval", "inputs": [{"name": "operator", "type": "address"}, {"name": "tokenId", "type": "uint256"}]},
+	{"type": "error", "name": "ERC721InvalidApprover", "inputs": [{"name": "approver", "type": "address"}]},
+	{"type": "error", "name": "ERC721InvalidOperator", "inputs": [{"name": "operator", "type": "address"}]}
+]`)
+
+// nonexistentTokenReasons are revert reasons used by common ERC-721
+// implementations when ownerOf is called for a token that does not exist
+var nonexistentTokenReasons = []string{
+	"nonexistent token",
+	"invalid token id",
+	"not_minted",
+	"not minted",
+}
+
+// panicReasons describes the Solidity panic codes
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic overflow or underflow",
+	0x12: "division or modulo by zero",
+	0x21: "invalid enum value",
+	0x22: "invalid storage byte array encoding",
+	0x31: "pop on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to uninitialized function",
+}
+
+// RevertError is returned when a call reverts with data that could not be
+// decoded into one of the more specific revert errors
+type RevertError struct {
+	Data []byte
+}
+
+func (e *RevertError) Error() string {
+	if len(e.Data) == 0 {
+		return "execution reverted"
+	}
+	return fmt.Sprintf("execution reverted with data %s", hexutil.Encode(e.Data))
+}
+
+// ReasonError is returned when a call reverts with Error(string)
+type ReasonError struct {
+	Reason string
+}
+
+func (e *ReasonError) Error() string {
+	return fmt.Sprintf("execution reverted: %s", e.Reason)
+}
+
+// PanicError is returned when a call reverts with Panic(uint256)
+type PanicError struct {
+	Code *big.Int
+}
+
+func (e *PanicError) Error() string {
+	reason := "unknown panic"
+	if e.Code.IsUint64() {
+		if r, ok := panicReasons[e.Code.Uint64()]; ok {
+			reason = r
+		}
+	}
+	return fmt.Sprintf("execution reverted: panic 0x%x (%s)", e.Code, reason)
+}
+
+// CustomError is returned when a call reverts with a known custom error
+type CustomError struct {
+	Name string
+	Args []interface{}
+}
+
+func (e *CustomError) Error() string {
+	args := make([]string, 0, len(e.Args))
+	for _, arg := range e.Args {
+		args = append(args, fmt.Sprint(arg))
+	}
+	return fmt.Sprintf("execution reverted: %s(%s)", e.Name, strings.Join(args, ", "))
+}
+
+// NonexistentTokenError is returned when a call reverts because the queried
+// token does not exist, either through ERC721NonexistentToken(uint256) or one
+// of the revert reasons common implementations use for it. TokenID is nil if
+// the revert did not include it.
+type NonexistentTokenError struct {
+	TokenID *big.Int
+	Cause   error
+}
+
+func (e *NonexistentTokenError) Error() string {
+	if e.TokenID != nil {
+		return fmt.Sprintf("execution reverted: token %s does not exist", e.TokenID)
+	}
+	return fmt.Sprintf("token does not exist (%v)", e.Cause)
+}
+
+func (e *NonexistentTokenError) Unwrap() error {
+	return e.Cause
+}
+
+// IsNonexistentToken reports whether err means that the queried token does
+// not exist (never minted or burned)
+func IsNonexistentToken(err error) bool {
+	var nonexistent *NonexistentTokenError
+	return errors.As(err, &nonexistent)
+}
+
+// IsRevert reports whether err is one of the typed revert errors, i.e. the
+// call reached the contract and was reverted
+func IsRevert(err error) bool {
+	var (
+		revertErr      *RevertError
+		reasonErr      *ReasonError
+		panicErr       *PanicError
+		customErr      *CustomError
+		nonexistentErr *NonexistentTokenError
+	)
+	return errors.As(err, &revertErr) ||
+		errors.As(err, &reasonErr) ||
+		errors.As(err, &panicErr) ||
+		errors.As(err, &customErr) ||
+		errors.As(err, &nonexistentErr)
+}
+
+// IsRateLimited reports whether err means that the RPC provider rejected the
+// request because of a rate limit
+func IsRateLimited(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == limitExceededCode {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "too many requests") || strings.Contains(message, "rate limit")
+}
+
+// DecodeRevert decodes the return data of a reverted call into a typed error
+func DecodeRevert(data []byte) error {
+	if len(data) < 4 {
+		return &RevertError{Data: data}
+	}
+
+	selector, payload := data[:4], data[4:]
+
+	switch {
+	case bytes.Equal(selector, errorSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return &RevertError{Data: data}
+		}
+		reasonErr := &ReasonError{Reason: reason}
+		if isNonexistentTokenReason(reason) {
+			return &NonexistentTokenError{Cause: reasonErr}
+		}
+		return reasonErr
+
+	case bytes.Equal(selector, panicSelector):
+		if len(payload) != 32 {
+			return &RevertError{Data: data}
+		}
+		return &PanicError{Code: new(big.Int).SetBytes(payload)}
+
+	case bytes.Equal(selector, ownerQueryForNonexistentTokenSelector):
+		return &NonexistentTokenError{Cause: &CustomError{Name: "OwnerQueryForNonexistentToken"}}
+	}
+
+	for name, abiError := range erc6093ABI.Errors {
+		if !bytes.Equal(selector, abiError.ID[:4]) {
+			continue
+		}
+
+		args, err := abiError.Inputs.Unpack(payload)
+		if err != nil {
+			return &RevertError{Data: data}
+		}
+
+		customErr := &CustomError{Name: name, Args: args}
+		if name == "ERC721NonexistentToken" {
+			return &NonexistentTokenError{TokenID: args[0].(*big.Int), Cause: customErr}
+		}
+		return customErr
+	}
+
+	return &RevertError{Data: data}
+}
+
+// decodeCallError turns the error of an eth_call into a typed revert error if
+// the node returned revert data. Other errors are returned unchanged.
+func decodeCallError(err error) error {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(hexData); decodeErr == nil {
+				return DecodeRevert(data)
+			}
+		}
+	}
+
+	// some nodes only report the revert reason in the message
+	message := err.Error()
+	if idx := strings.Index(message, "execution reverted"); idx != -1 {
+		reason := strings.TrimPrefix(message[idx+len("execution reverted"):], ":")
+		reason = strings.TrimSpace(reason)
+		if reason == "" {
+			// reverted without data, e.g. require(condition) without a message
+			return &RevertError{}
+		}
+		reasonErr := &ReasonError{Reason: reason}
+		if isNonexistentTokenReason(reason) {
+			return &NonexistentTokenError{Cause: reasonErr}
+		}
+		return reasonErr
+	}
+
+	return err
+}
+
+// isNonexistentTokenReason reports whether a revert reason means that the
+// queried token does not exist
+func isNonexistentTokenReason(reason string) bool {
+	reason = strings.ToLower(reason)
+	for _, r := range nonexistentTokenReasons {
+		if strings.Contains(reason, r) {
+			return true
+		}
+	}
+	return false
+}
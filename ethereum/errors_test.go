@@ -0,0 +1,198 @@
+package ethereum
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// encodeRevert builds revert data for selector with args ABI-encoded as types
+func encodeRevert(t *testing.T, selector []byte, types []string, args ...interface{}) []byte {
+	t.Helper()
+
+	arguments := make(abi.Arguments, 0, len(types))
+	for _, typ := range types {
+		abiType, err := abi.NewType(typ, "", nil)
+		if err != nil {
+			t.Fatalf("invalid ABI type %q: %v", typ, err)
+		}
+		arguments = append(arguments, abi.Argument{Type: abiType})
+	}
+
+	payload, err := arguments.Pack(args...)
+	if err != nil {
+		t.Fatalf("failed to pack revert arguments: %v", err)
+	}
+	return append(append([]byte{}, selector...), payload...)
+}
+
+func TestDecodeRevert(t *testing.T) {
+	nonexistentSelector := erc6093ABI.Errors["ERC721NonexistentToken"].ID.Bytes()[:4]
+	incorrectOwnerSelector := erc6093ABI.Errors["ERC721IncorrectOwner"].ID.Bytes()[:4]
+	owner := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	tests := []struct {
+		name        string
+		data        []byte
+		want        string
+		nonexistent bool
+		tokenID     *big.Int
+	}{
+		{
+			name: "empty",
+			data: nil,
+			want: "execution reverted",
+		},
+		{
+			name: "error string",
+			data: encodeRevert(t, errorSelector, []string{"string"}, "Ownable: caller is not the owner"),
+			want: "execution reverted: Ownable: caller is not the owner",
+		},
+		{
+			name:        "error string for nonexistent token",
+			data:        encodeRevert(t, errorSelector, []string{"string"}, "ERC721: invalid token ID"),
+			want:        "token does not exist (execution reverted: ERC721: invalid token ID)",
+			nonexistent: true,
+		},
+		{
+			name: "panic",
+			data: encodeRevert(t, panicSelector, []string{"uint256"}, big.NewInt(0x11)),
+			want: "execution reverted: panic 0x11 (arithmetic overflow or underflow)",
+		},
+		{
+			name: "unknown panic",
+			data: encodeRevert(t, panicSelector, []string{"uint256"}, big.NewInt(0x99)),
+			want: "execution reverted: panic 0x99 (unknown panic)",
+		},
+		{
+			name:        "ERC721NonexistentToken",
+			data:        encodeRevert(t, nonexistentSelector, []string{"uint256"}, big.NewInt(42)),
+			want:        "execution reverted: token 42 does not exist",
+			nonexistent: true,
+			tokenID:     big.NewInt(42),
+		},
+		{
+			name: "ERC721IncorrectOwner",
+			data: encodeRevert(t, incorrectOwnerSelector, []string{"address", "uint256", "address"}, owner, big.NewInt(7), owner),
+			want: "execution reverted: ERC721IncorrectOwner(" + owner.Hex() + ", 7, " + owner.Hex() + ")",
+		},
+		{
+			name:        "OwnerQueryForNonexistentToken",
+			data:        ownerQueryForNonexistentTokenSelector,
+			want:        "token does not exist (execution reverted: OwnerQueryForNonexistentToken())",
+			nonexistent: true,
+		},
+		{
+			name: "truncated selector",
+			data: []byte{0x08, 0xc3},
+			want: "execution reverted with data 0x08c3",
+		},
+		{
+			name: "truncated error string",
+			data: append(append([]byte{}, errorSelector...), 0x00, 0x01),
+			want: "execution reverted with data 0x08c379a00001",
+		},
+		{
+			name: "truncated panic",
+			data: append(append([]byte{}, panicSelector...), 0x11),
+			want: "execution reverted with data 0x4e487b7111",
+		},
+		{
+			name: "truncated ERC721NonexistentToken",
+			data: append(append([]byte{}, nonexistentSelector...), 0x2a),
+			want: "execution reverted with data " + hexutil.Encode(nonexistentSelector) + "2a",
+		},
+		{
+			name: "unknown selector",
+			data: []byte{0xde, 0xad, 0xbe, 0xef},
+			want: "execution reverted with data 0xdeadbeef",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := DecodeRevert(tt.data)
+			if err.Error() != tt.want {
+				t.Errorf("DecodeRevert() = %q, want %q", err.Error(), tt.want)
+			}
+			if IsNonexistentToken(err) != tt.nonexistent {
+				t.Errorf("IsNonexistentToken() = %v, want %v", IsNonexistentToken(err), tt.nonexistent)
+			}
+
+			var nonexistent *NonexistentTokenError
+			if tt.tokenID != nil && (!errors.As(err, &nonexistent) || nonexistent.TokenID.Cmp(tt.tokenID) != 0) {
+				t.Errorf("DecodeRevert() token ID = %v, want %s", nonexistent, tt.tokenID)
+			}
+		})
+	}
+}
+
+// dataError mimics the JSON-RPC error of a reverted eth_call
+type dataError struct {
+	message string
+	data    interface{}
+}
+
+func (e *dataError) Error() string          { return e.message }
+func (e *dataError) ErrorCode() int         { return 3 }
+func (e *dataError) ErrorData() interface{} { return e.data }
+
+func TestDecodeCallError(t *testing.T) {
+	nonexistentSelector := erc6093ABI.Errors["ERC721NonexistentToken"].ID.Bytes()[:4]
+	transportErr := errors.New("connection refused")
+
+	tests := []struct {
+		name        string
+		err         error
+		want        string
+		nonexistent bool
+	}{
+		{
+			name:        "revert data",
+			err:         &dataError{message: "execution reverted", data: hexutil.Encode(encodeRevert(t, nonexistentSelector, []string{"uint256"}, big.NewInt(1)))},
+			want:        "execution reverted: token 1 does not exist",
+			nonexistent: true,
+		},
+		{
+			name: "reason in message",
+			err:  errors.New("execution reverted: Pausable: paused"),
+			want: "execution reverted: Pausable: paused",
+		},
+		{
+			name:        "nonexistent token reason in message",
+			err:         errors.New("execution reverted: ERC721: owner query for nonexistent token"),
+			want:        "token does not exist (execution reverted: ERC721: owner query for nonexistent token)",
+			nonexistent: true,
+		},
+		{
+			name: "no reason",
+			err:  errors.New("execution reverted"),
+			want: "execution reverted",
+		},
+		{
+			name: "other error",
+			err:  transportErr,
+			want: "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeCallError(tt.err)
+			if err.Error() != tt.want {
+				t.Errorf("decodeCallError() = %q, want %q", err.Error(), tt.want)
+			}
+			if IsNonexistentToken(err) != tt.nonexistent {
+				t.Errorf("IsNonexistentToken() = %v, want %v", IsNonexistentToken(err), tt.nonexistent)
+			}
+		})
+	}
+
+	if decodeCallError(transportErr) != transportErr {
+		t.Errorf("decodeCallError() did not return other errors unchanged")
+	}
+}
@@ -0,0 +1,291 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// errNoEndpoints is returned when every endpoint of a chain is disabled
+var errNoEndpoints = errors.New("no usable RPC endpoints")
+
+// do runs op against an endpoint chosen by the selection strategy, as a
+// single JSON-RPC call (see doCalls)
+func (ec *EthereumClient) do(ctx context.Context, op func(ctx context.Context, client *ethclient.Client) error) error {
+	return ec.doCalls(ctx, 1, op)
+}
+
+// doCalls runs op, which sends the given number of JSON-RPC calls, with
+// failover between the endpoints. If every endpoint failed with a retryable
+// error (see IsRetryable), op is retried after a backoff according to the
+// retry policy. Any error once ctx is done is returned right away.
+func (ec *EthereumClient) doCalls(ctx context.Context, calls int, op func(ctx context.Context, client *ethclient.Client) error) error {
+	policy := ec.RetryPolicy()
+	for retry := 0; ; retry++ {
+		err := ec.failover(ctx, calls, op)
+		if err == nil || ctx.Err() != nil || retry >= policy.MaxRetries || !IsRetryable(err) {
+			return err
+		}
+
+		delay := policy.backoff(retry, rand.Float64())
+		if wait := ec.retryAfterWait(time.Now()); wait > delay {
+			if wait > policy.MaxBackoff {
+				return err
+			}
+			delay = wait
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// failover runs op against an endpoint chosen by the selection strategy. If
+// the endpoint is at fault (see isEndpointError) the endpoint is skipped for
+// a cooldown and op is retried on the next one until every endpoint has been
+// tried. Other errors, and any error once ctx is done, are returned right
+// away.
+func (ec *EthereumClient) failover(ctx context.Context, calls int, op func(ctx context.Context, client *ethclient.Client) error) error {
+	tried := make(map[*endpoint]bool, len(ec.endpoints))
+	var errs []error
+
+	for len(tried) < len(ec.endpoints) {
+		ep := ec.selectEndpoint(tried)
+		if ep == nil {
+			break
+		}
+		tried[ep] = true
+
+		err := ec.attempt(ctx, ep, calls, op)
+		if err == nil || !isEndpointError(err) || ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, &endpointError{url: ep.url, err: err})
+	}
+
+	if len(errs) == 0 {
+		return errNoEndpoints
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return fmt.Errorf("all %d RPC endpoints failed: %w", len(errs), errors.Join(errs...))
+}
+
+// attempt waits for the rate limiter of the endpoint, then runs op against
+// it, bounded by the RPC timeout, and records the outcome. Failures caused by
+// ctx ending are not held against the endpoint.
+func (ec *EthereumClient) attempt(ctx context.Context, ep *endpoint, calls int, op func(ctx context.Context, client *ethclient.Client) error) error {
+	if err := ep.wait(ctx, calls); err != nil {
+		return err
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, ec.RPCTimeout())
+	defer cancel()
+
+	start := time.Now()
+	client, err := ep.connect(attemptCtx, ec.chainID)
+	if err == nil {
+		err = op(attemptCtx, client)
+	}
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+	case err != nil && isEndpointError(err):
+		ep.recordFailure(err)
+	default:
+		ep.recordSuccess(time.Since(start))
+	}
+	return err
+}
+
+// retryAfterWait returns how long the providers asked to wait before the
+// next request: the shortest Retry-After of the usable endpoints, zero if one
+// of them did not send any
+func (ec *EthereumClient) retryAfterWait(now time.Time) time.Duration {
+	var shortest time.Duration
+	for _, ep := range ec.endpoints {
+		if !ep.usable() {
+			continue
+		}
+		wait := ep.retryAfterWait(now)
+		if wait == 0 {
+			return 0
+		}
+		if shortest == 0 || wait < shortest {
+			shortest = wait
+		}
+	}
+	return shortest
+}
+
+// selectEndpoint picks the next endpoint to try, skipping the ones already
+// tried. Endpoints in their cooldown are only picked once no other endpoint
+// is left, so that a request is still attempted when all of them failed
+// recently.
+func (ec *EthereumClient) selectEndpoint(tried map[*endpoint]bool) *endpoint {
+	now := time.Now()
+	var healthy, cooling []*endpoint
+	for _, ep := range ec.endpoints {
+		switch {
+		case tried[ep] || !ep.usable():
+		case ep.available(now):
+			healthy = append(healthy, ep)
+		default:
+			cooling = append(cooling, ep)
+		}
+	}
+
+	candidates := healthy
+	if len(candidates) == 0 {
+		candidates = cooling
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	switch ec.selection {
+	case SelectionRoundRobin:
+		return candidates[int(ec.next.Add(1)-1)%len(candidates)]
+	case SelectionLatency:
+		return pickByLatency(candidates, rand.Float64())
+	default:
+		return candidates[0]
+	}
+}
+
+// pickByLatency picks an endpoint with a probability proportional to the
+// inverse of its average latency, using r in [0, 1). Endpoints without a
+// measured latency are weighted like the fastest measured one so that they
+// get measured.
+func pickByLatency(candidates []*endpoint, r float64) *endpoint {
+	latencies := make([]time.Duration, len(candidates))
+	var fastest time.Duration
+	for i, ep := range candidates {
+		latencies[i] = ep.latency()
+		if latencies[i] > 0 && (fastest == 0 || latencies[i] < fastest) {
+			fastest = latencies[i]
+		}
+	}
+	if fastest == 0 {
+		fastest = time.Millisecond
+	}
+
+	weights := make([]float64, len(candidates))
+	var total float64
+	for i, latency := range latencies {
+		if latency == 0 {
+			latency = fastest
+		}
+		weights[i] = 1 / float64(latency)
+		total += weights[i]
+	}
+
+	target := r * total
+	for i, weight := range weights {
+		if target < weight {
+			return candidates[i]
+		}
+		target -= weight
+	}
+	return candidates[len(candidates)-1]
+}
+
+// CheckHealth probes every endpoint with eth_blockNumber, verifying its chain
+// ID first, and records latency and head block. Endpoints that respond become
+// eligible again before their cooldown ends.
+func (ec *EthereumClient) CheckHealth(ctx context.Context) {
+	timeout := ec.RPCTimeout()
+
+	var wg sync.WaitGroup
+	for _, ep := range ec.endpoints {
+		wg.Add(1)
+		go func(ep *endpoint) {
+			defer wg.Done()
+			ep.check(ctx, ec.chainID, timeout)
+		}(ep)
+	}
+	wg.Wait()
+}
+
+// StartHealthChecks runs CheckHealth every interval in the background until
+// the client is closed. Calling it again has no effect.
+func (ec *EthereumClient) StartHealthChecks(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ec.healthOnce.Do(func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-ec.stopHealth
+			cancel()
+		}()
+
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			ec.CheckHealth(ctx)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					ec.CheckHealth(ctx)
+				}
+			}
+		}()
+	})
+}
+
+// EndpointStats returns the health and usage of every endpoint in
+// configuration order
+func (ec *EthereumClient) EndpointStats() []EndpointStats {
+	now := time.Now()
+	stats := make([]EndpointStats, 0, len(ec.endpoints))
+	for _, ep := range ec.endpoints {
+		stats = append(stats, ep.stats(now))
+	}
+	return stats
+}
+
+// Healthy reports whether at least one endpoint is healthy
+func (ec *EthereumClient) Healthy() bool {
+	for _, stats := range ec.EndpointStats() {
+		if stats.Healthy {
+			return true
+		}
+	}
+	return false
+}
+
+// call executes msg against the latest block
+func (ec *EthereumClient) call(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	var result []byte
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		result, err = client.CallContract(ctx, msg, nil)
+		return err
+	})
+	return result, err
+}
+
+// filterLogs returns the logs matching query
+func (ec *EthereumClient) filterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	var logs []types.Log
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		logs, err = client.FilterLogs(ctx, query)
+		return err
+	})
+	return logs, err
+}
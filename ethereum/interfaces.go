@@ -0,0 +1,118 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC-165 interface IDs probed by DetectInterfaces
+var (
+	InterfaceERC165           = [4]byte{0x01, 0xff, 0xc9, 0xa7}
+	InterfaceERC721           = [4]byte{0x80, 0xac, 0x58, 0xcd}
+	InterfaceERC721Metadata   = [4]byte{0x5b, 0x5e, 0x13, 0x9f}
+	InterfaceERC721Enumerable = [4]byte{0x78, 0x0e, 0x9d, 0x63}
+	InterfaceERC1155          = [4]byte{0xd9, 0xb6, 0x7a, 0x26}
+	InterfaceERC2981          = [4]byte{0x2a, 0x55, 0x20, 0x5a}
+
+	// invalidInterface must not be supported by ERC-165 contracts
+	invalidInterface = [4]byte{0xff, 0xff, 0xff, 0xff}
+)
+
+// supportsInterfaceGas is the gas limit EIP-165 specifies for supportsInterface
+const supportsInterfaceGas = 30000
+
+// erc165ABI is the ABI of ERC-165 supportsInterface
+var erc165ABI = mustParseABI(`[{
+	"type": "function",
+	"name": "supportsInterface",
+	"stateMutability": "view",
+	"inputs": [{"name": "interfaceId", "type": "bytes4"}],
+	"outputs": [{"name": "", "type": "bool"}]
+}]`)
+
+// Interfaces are the interfaces a contract advertises through ERC-165.
+// Contracts that do not implement ERC-165 itself report no interfaces, even
+// if they behave like an ERC-721 contract.
+type Interfaces struct {
+	ERC165           bool
+	ERC721           bool
+	ERC721Metadata   bool
+	ERC721Enumerable bool
+	ERC1155          bool
+	ERC2981          bool
+}
+
+// DetectInterfaces probes supportsInterface of a contract at the latest block
+// following the ERC-165 detection procedure: the contract must claim 0x01ffc9a7
+// and deny 0xffffffff before any other answer is trusted.
+func (ec *EthereumClient) DetectInterfaces(ctx context.Context, address common.Address) (Interfaces, error) {
+	var detected Interfaces
+
+	supportsERC165, err := ec.supportsInterface(ctx, address, InterfaceERC165)
+	if err != nil || !supportsERC165 {
+		return detected, err
+	}
+	supportsInvalid, err := ec.supportsInterface(ctx, address, invalidInterface)
+	if err != nil || supportsInvalid {
+		return detected, err
+	}
+	detected.ERC165 = true
+
+	probes := []struct {
+		id        [4]byte
+		supported *bool
+	}{
+		{InterfaceERC721, &detected.ERC721},
+		{InterfaceERC721Metadata, &detected.ERC721Metadata},
+		{InterfaceERC721Enumerable, &detected.ERC721Enumerable},
+		{InterfaceERC1155, &detected.ERC1155},
+		{InterfaceERC2981, &detected.ERC2981},
+	}
+	for _, probe := range probes {
+		supported, err := ec.supportsInterface(ctx, address, probe.id)
+		if err != nil {
+			return Interfaces{}, err
+		}
+		*probe.supported = supported
+	}
+
+	return detected, nil
+}
+
+// supportsInterface calls supportsInterface(id) with the gas limit of
+// EIP-165. Reverts, running out of gas and empty or malformed results mean
+// that the interface is not supported.
+func (ec *EthereumClient) supportsInterface(ctx context.Context, address common.Address, id [4]byte) (bool, error) {
+	data, err := erc165ABI.Pack("supportsInterface", id)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack supportsInterface call: %v", err)
+	}
+
+	msg := ethereum.CallMsg{
+		To:   &address,
+		Gas:  supportsInterfaceGas,
+		Data: data,
+	}
+	result, err := ec.call(ctx, msg)
+	if err != nil {
+		err = decodeCallError(err)
+		if IsRevert(err) || strings.Contains(err.Error(), "out of gas") {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to call supportsInterface of %s: %w", address.Hex(), err)
+	}
+
+	// a bool is returned as a single 32 byte word
+	if len(result) != 32 {
+		return false, nil
+	}
+	var supported bool
+	if err := erc165ABI.UnpackIntoInterface(&supported, "supportsInterface", result); err != nil {
+		return false, nil
+	}
+	return supported, nil
+}
@@ -0,0 +1,84 @@
+package ethereum
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// interfaceID computes an ERC-165 interface ID as the XOR of the selectors
+// of its functions
+func interfaceID(signatures ...string) [4]byte {
+	var id [4]byte
+	for _, signature := range signatures {
+		selector := crypto.Keccak256([]byte(signature))[:4]
+		for i := range id {
+			id[i] ^= selector[i]
+		}
+	}
+	return id
+}
+
+func TestInterfaceIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		id         [4]byte
+		signatures []string
+	}{
+		{
+			name:       "ERC165",
+			id:         InterfaceERC165,
+			signatures: []string{"supportsInterface(bytes4)"},
+		},
+		{
+			name: "ERC721",
+			id:   InterfaceERC721,
+			signatures: []string{
+				"balanceOf(address)",
+				"ownerOf(uint256)",
+				"safeTransferFrom(address,address,uint256,bytes)",
+				"safeTransferFrom(address,address,uint256)",
+				"transferFrom(address,address,uint256)",
+				"approve(address,uint256)",
+				"setApprovalForAll(address,bool)",
+				"getApproved(uint256)",
+				"isApprovedForAll(address,address)",
+			},
+		},
+		{
+			name:       "ERC721Metadata",
+			id:         InterfaceERC721Metadata,
+			signatures: []string{"name()", "symbol()", "tokenURI(uint256)"},
+		},
+		{
+			name:       "ERC721Enumerable",
+			id:         InterfaceERC721Enumerable,
+			signatures: []string{"totalSupply()", "tokenOfOwnerByIndex(address,uint256)", "tokenByIndex(uint256)"},
+		},
+		{
+			name: "ERC1155",
+			id:   InterfaceERC1155,
+			signatures: []string{
+				"safeTransferFrom(address,address,uint256,uint256,bytes)",
+				"safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)",
+				"balanceOf(address,uint256)",
+				"balanceOfBatch(address[],uint256[])",
+				"setApprovalForAll(address,bool)",
+				"isApprovedForAll(address,address)",
+			},
+		},
+		{
+			name:       "ERC2981",
+			id:         InterfaceERC2981,
+			signatures: []string{"royaltyInfo(uint256,uint256)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if want := interfaceID(tt.signatures...); tt.id != want {
+				t.Errorf("interface ID = %x, want %x", tt.id, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	// DefaultMulticallAddress is the address Multicall3 is deployed at on
+	// most EVM chains
+	DefaultMulticallAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
+
+	// DefaultOwnerBatchSize is the default number of ownerOf calls packed
+	// into a single aggregate3 call
+	DefaultOwnerBatchSize = 500
+)
+
+// multicallABI is the ABI of Multicall3 aggregate3
+var multicallABI = mustParseABI(`[{
+	"inputs": [{
+		"components": [
+			{"name": "target", "type": "address"},
+			{"name": "allowFailure", "type": "bool"},
+			{"name": "callData", "type": "bytes"}
+		],
+		"name": "calls",
+		"type": "tuple[]"
+	}],
+	"name": "aggregate3",
+	"outputs": [{
+		"components": [
+			{"name": "success", "type": "bool"},
+			{"name": "returnData", "type": "bytes"}
+		],
+		"name": "returnData",
+		"type": "tuple[]"
+	}],
+	"stateMutability": "payable",
+	"type": "function"
+}]`)
+
+// multicallCall is a single call of an aggregate3 batch
+type multicallCall struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicallResult is the result of a single call of an aggregate3 batch
+type multicallResult struct {
+	Success    bool
+	ReturnData []byte
+}
+
+// OwnerResult is the outcome of a single ownerOf call in a batch.
+// Err is set if the call failed, for example because the token was burned.
+type OwnerResult struct {
+	TokenID *big.Int
+	Owner   string
+	Err     error
+}
+
+// SetMulticallAddress overrides the Multicall3 address used for batched calls
+func (ec *EthereumClient) SetMulticallAddress(address string) error {
+	multicallAddress, err := ParseAddress(address)
+	if err != nil {
+		return fmt.Errorf("invalid Multicall address: %w", err)
+	}
+
+	ec.batchMu.Lock()
+	defer ec.batchMu.Unlock()
+
+	ec.multicallAddress = multicallAddress
+	ec.multicallAvailable = nil
+	return nil
+}
+
+// GetOwnersOf reads the owners of many tokens of an ERC-721 contract,
+// batchSize calls per round trip. Calls are packed into Multicall3 aggregate3
+// when the Multicall contract has code on the chain, and sent as JSON-RPC
+// batches of eth_call otherwise. Every call is allowed to fail individually so
+// that burned or nonexistent tokens do not fail the batch. All batches are
+// pinned to the same block, whose number is returned.
+func (ec *EthereumClient) GetOwnersOf(ctx context.Context, contractAddress string, tokenIDs []*big.Int, block BlockRef, batchSize int) ([]OwnerResult, uint64, error) {
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if batchSize <= 0 {
+		batchSize = DefaultOwnerBatchSize
+	}
+
+	// Resolve the block once so every batch reads the same state
+	blockNumber, err := ec.ResolveBlock(ctx, block)
+	if err != nil {
+		return nil, 0, err
+	}
+	pinned := BlockAt(blockNumber)
+	if block.Hash != nil {
+		pinned = block
+	}
+
+	useMulticall, err := ec.hasMulticall(ctx, blockNumber)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fetch := ec.aggregateOwners
+	if !useMulticall {
+		fetch = ec.batchOwners
+		if limit := ec.RPCBatchLimit(); batchSize > limit {
+			batchSize = limit
+		}
+	}
+
+	results := make([]OwnerResult, 0, len(tokenIDs))
+
+	for start := 0; start < len(tokenIDs); start += batchSize {
+		end := start + batchSize
+		if end > len(tokenIDs) {
+			end = len(tokenIDs)
+		}
+
+		batch, err := fetch(ctx, contractAddr, tokenIDs[start:end], pinned)
+		if err != nil {
+			return nil, 0, err
+		}
+		results = append(results, batch...)
+	}
+
+	return results, blockNumber, nil
+}
+
+// hasMulticall reports whether the Multicall contract has code at the given
+// block. The answer is cached until the Multicall address changes. The lock is
+// not held while probing, so that a slow endpoint does not block the other
+// batch settings.
+func (ec *EthereumClient) hasMulticall(ctx context.Context, blockNumber uint64) (bool, error) {
+	ec.batchMu.Lock()
+	multicallAddress := ec.multicallAddress
+	cached := ec.multicallAvailable
+	ec.batchMu.Unlock()
+
+	if cached != nil {
+		return *cached, nil
+	}
+
+	var code []byte
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		code, err = client.CodeAt(ctx, multicallAddress, new(big.Int).SetUint64(blockNumber))
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get code of multicall contract: %w", err)
+	}
+
+	available := len(code) > 0
+	if !available {
+		log.Printf("No Multicall contract at %s, falling back to JSON-RPC batches", multicallAddress.Hex())
+	}
+
+	// the address may have changed while probing, in which case the answer
+	// belongs to the old address and is not cached
+	ec.batchMu.Lock()
+	if ec.multicallAddress == multicallAddress {
+		ec.multicallAvailable = &available
+	}
+	ec.batchMu.Unlock()
+	return available, nil
+}
+
+// aggregateOwners reads the owners of a single batch of tokens with one
+// aggregate3 call
+func (ec *EthereumClient) aggregateOwners(ctx context.Context, contractAddr common.Address, tokenIDs []*big.Int, block BlockRef) ([]OwnerResult, error) {
+	calls := make([]multicallCall, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		data, err := ec.contractABI.Pack("ownerOf", tokenID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack function call: %v", err)
+		}
+		calls = append(calls, multicallCall{
+			Target:       contractAddr,
+			AllowFailure: true,
+			CallData:     data,
+		})
+	}
+
+	data, err := multicallABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack multicall: %v", err)
+	}
+
+	ec.batchMu.Lock()
+	multicallAddress := ec.multicallAddress
+	ec.batchMu.Unlock()
+
+	msg := ethereum.CallMsg{
+		To:   &multicallAddress,
+		Data: data,
+	}
+
+	output, _, err := ec.callAt(ctx, msg, block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call multicall: %w", err)
+	}
+
+	unpacked, err := multicallABI.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack multicall result: %v", err)
+	}
+	returnData := *abi.ConvertType(unpacked[0], new([]multicallResult)).(*[]multicallResult)
+	if len(returnData) != len(tokenIDs) {
+		return nil, fmt.Errorf("multicall returned %d results for %d calls", len(returnData), len(tokenIDs))
+	}
+
+	results := make([]OwnerResult, 0, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		result := OwnerResult{TokenID: tokenID}
+		if !returnData[i].Success {
+			result.Err = fmt.Errorf("ownerOf(%s) reverted: %w", tokenID, DecodeRevert(returnData[i].ReturnData))
+		} else if len(returnData[i].ReturnData) == 0 {
+			result.Err = fmt.Errorf("failed to unpack result: %w", ErrEmptyResult)
+		} else {
+			var owner common.Address
+			if err := ec.contractABI.UnpackIntoInterface(&owner, "ownerOf", returnData[i].ReturnData); err != nil {
+				result.Err = fmt.Errorf("failed to unpack result: %v", err)
+			} else {
+				result.Owner = owner.Hex()
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// mustParseABI parses a JSON ABI definition and panics on error
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid ABI: %v", err))
+	}
+	return parsed
+}
@@ -0,0 +1,293 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+var (
+	testCollection = common.HexToAddress("0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D")
+	testHolder     = common.HexToAddress("0x1111111111111111111111111111111111111111")
+)
+
+// callServer is a JSON-RPC endpoint of chain 1 serving an ERC-721 contract
+// and, if hasMulticall is set, Multicall3 at DefaultMulticallAddress. Tokens in
+// owners are owned by their value, tokens in empty return no data and every
+// other token reverts with ERC721NonexistentToken. Single requests and
+// JSON-RPC batches are both accepted.
+type callServer struct {
+	*httptest.Server
+	hasMulticall bool
+	owners       map[int64]common.Address
+	empty        map[int64]bool
+
+	// getCode, if set, blocks eth_getCode until it is closed. Every
+	// blocked request is reported on codeRequested first.
+	getCode       chan struct{}
+	codeRequested chan struct{}
+
+	mu         sync.Mutex
+	batches    []int // sizes of the JSON-RPC batches received
+	aggregates []int // number of calls of the aggregate3 calls received
+}
+
+// rpcRequest is a JSON-RPC request received by a callServer
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func newCallServer(t *testing.T, hasMulticall bool, owners map[int64]common.Address) *callServer {
+	t.Helper()
+
+	s := &callServer{hasMulticall: hasMulticall, owners: owners, empty: map[int64]bool{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+			var reqs []rpcRequest
+			if err := json.Unmarshal(body, &reqs); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			s.mu.Lock()
+			s.batches = append(s.batches, len(reqs))
+			s.mu.Unlock()
+
+			responses := make([]json.RawMessage, 0, len(reqs))
+			for _, req := range reqs {
+				responses = append(responses, s.respond(req))
+			}
+			json.NewEncoder(w).Encode(responses)
+			return
+		}
+
+		var req rpcRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write(s.respond(req))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// respond answers a single JSON-RPC request
+func (s *callServer) respond(req rpcRequest) json.RawMessage {
+	result := func(v interface{}) json.RawMessage {
+		encoded, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": v})
+		return encoded
+	}
+	failure := func(code int, message string, data []byte) json.RawMessage {
+		rpcErr := map[string]interface{}{"code": code, "message": message}
+		if data != nil {
+			rpcErr["data"] = hexutil.Encode(data)
+		}
+		encoded, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": rpcErr})
+		return encoded
+	}
+
+	switch req.Method {
+	case "eth_chainId":
+		return result("0x1")
+	case "eth_getCode":
+		if s.getCode != nil {
+			s.codeRequested <- struct{}{}
+			<-s.getCode
+		}
+		if s.hasMulticall {
+			return result("0x6080")
+		}
+		return result("0x")
+	case "eth_call":
+		var call struct {
+			To    common.Address `json:"to"`
+			Data  hexutil.Bytes  `json:"data"`
+			Input hexutil.Bytes  `json:"input"`
+		}
+		if err := json.Unmarshal(req.Params[0], &call); err != nil {
+			return failure(-32602, err.Error(), nil)
+		}
+		data := call.Input
+		if len(data) == 0 {
+			data = call.Data
+		}
+
+		if s.hasMulticall && call.To == common.HexToAddress(DefaultMulticallAddress) {
+			output, err := s.aggregate(data)
+			if err != nil {
+				return failure(-32602, err.Error(), nil)
+			}
+			return result(hexutil.Encode(output))
+		}
+
+		output, revert := s.ownerOf(data)
+		if revert != nil {
+			return failure(3, "execution reverted", revert)
+		}
+		return result(hexutil.Encode(output))
+	default:
+		return failure(-32601, "method not found", nil)
+	}
+}
+
+// ownerOf answers encoded ownerOf call data with the encoded owner, or the
+// revert data if the token does not exist
+func (s *callServer) ownerOf(data []byte) ([]byte, []byte) {
+	tokenID := new(big.Int).SetBytes(data[4:])
+	if s.empty[tokenID.Int64()] {
+		return []byte{}, nil
+	}
+	owner, ok := s.owners[tokenID.Int64()]
+	if !ok {
+		nonexistent := erc6093ABI.Errors["ERC721NonexistentToken"]
+		args, _ := nonexistent.Inputs.Pack(tokenID)
+		return nil, append(nonexistent.ID[:4:4], args...)
+	}
+	return common.LeftPadBytes(owner.Bytes(), 32), nil
+}
+
+// aggregate answers an aggregate3 call by running every packed ownerOf call
+func (s *callServer) aggregate(data []byte) ([]byte, error) {
+	method := multicallABI.Methods["aggregate3"]
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	calls := *abi.ConvertType(args[0], new([]multicallCall)).(*[]multicallCall)
+
+	s.mu.Lock()
+	s.aggregates = append(s.aggregates, len(calls))
+	s.mu.Unlock()
+
+	results := make([]multicallResult, 0, len(calls))
+	for _, call := range calls {
+		output, revert := s.ownerOf(call.CallData)
+		if revert != nil {
+			results = append(results, multicallResult{Success: false, ReturnData: revert})
+			continue
+		}
+		results = append(results, multicallResult{Success: true, ReturnData: output})
+	}
+	return method.Outputs.Pack(results)
+}
+
+// dial creates a client of the server
+func (s *callServer) dial(t *testing.T) *EthereumClient {
+	t.Helper()
+
+	ec, err := DialChain(context.Background(), ChainConfig{ID: 1, Name: "ethereum", RPCURLs: []string{s.URL}})
+	if err != nil {
+		t.Fatalf("DialChain() error: %v", err)
+	}
+	t.Cleanup(ec.Close)
+	ec.SetRetryPolicy(RetryPolicy{})
+	return ec
+}
+
+// tokenIDs returns the token IDs from 1 to n
+func tokenIDs(n int64) []*big.Int {
+	ids := make([]*big.Int, 0, n)
+	for id := int64(1); id <= n; id++ {
+		ids = append(ids, big.NewInt(id))
+	}
+	return ids
+}
+
+func TestGetOwnersOfMulticall(t *testing.T) {
+	server := newCallServer(t, true, map[int64]common.Address{1: testHolder, 2: testHolder, 4: testHolder, 5: testHolder})
+	server.empty[3] = true
+	ec := server.dial(t)
+
+	results, blockNumber, err := ec.GetOwnersOf(context.Background(), testCollection.Hex(), tokenIDs(6), BlockAt(100), 4)
+	if err != nil {
+		t.Fatalf("GetOwnersOf() error: %v", err)
+	}
+	if blockNumber != 100 {
+		t.Errorf("block number = %d, want 100", blockNumber)
+	}
+
+	// 6 tokens in batches of 4 calls packed into aggregate3
+	if len(server.aggregates) != 2 || server.aggregates[0] != 4 || server.aggregates[1] != 2 {
+		t.Errorf("aggregate3 calls = %v, want [4 2]", server.aggregates)
+	}
+	if len(results) != 6 {
+		t.Fatalf("got %d results, want 6", len(results))
+	}
+	for i, result := range results {
+		if result.TokenID.Int64() != int64(i+1) {
+			t.Errorf("result %d is for token %s, want %d", i, result.TokenID, i+1)
+		}
+		switch i + 1 {
+		case 3:
+			if !errors.Is(result.Err, ErrEmptyResult) {
+				t.Errorf("token 3 error = %v, want empty result", result.Err)
+			}
+		case 6:
+			if !IsNonexistentToken(result.Err) {
+				t.Errorf("token 6 error = %v, want nonexistent token", result.Err)
+			}
+		default:
+			if result.Err != nil || result.Owner != testHolder.Hex() {
+				t.Errorf("token %d = %s, %v, want %s", i+1, result.Owner, result.Err, testHolder.Hex())
+			}
+		}
+	}
+}
+
+func TestHasMulticallDoesNotBlock(t *testing.T) {
+	server := newCallServer(t, true, nil)
+	server.getCode = make(chan struct{})
+	server.codeRequested = make(chan struct{}, 1)
+	release := sync.OnceFunc(func() { close(server.getCode) })
+	t.Cleanup(release)
+	ec := server.dial(t)
+
+	probed := make(chan error, 1)
+	go func() {
+		_, err := ec.hasMulticall(context.Background(), 100)
+		probed <- err
+	}()
+
+	// the batch settings stay usable while the probe waits for the endpoint
+	<-server.codeRequested
+	done := make(chan struct{})
+	go func() {
+		ec.SetRPCBatchLimit(10)
+		ec.RPCBatchLimit()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("RPCBatchLimit() blocked while probing for Multicall")
+	}
+
+	release()
+	if err := <-probed; err != nil {
+		t.Fatalf("hasMulticall() error: %v", err)
+	}
+	if ec.multicallAvailable == nil || !*ec.multicallAvailable {
+		t.Error("probe result not cached")
+	}
+}
@@ -0,0 +1,175 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ClientPool holds one EthereumClient per chain of a registry, keyed by chain
+// ID. Clients are dialed on first use and shared afterwards.
+type ClientPool struct {
+	registry *ChainRegistry
+
+	mu             sync.Mutex
+	clients        map[uint64]*EthereumClient
+	healthInterval time.Duration
+	rpcTimeout     time.Duration
+	retryPolicy    RetryPolicy
+	rateLimit      RateLimit
+}
+
+// ChainStatus describes the RPC endpoints of a chain. Connected is false for
+// chains that have not been used yet, which have no endpoint stats. Healthy
+// reports whether at least one endpoint of a connected chain is healthy.
+type ChainStatus struct {
+	Chain     ChainConfig
+	Connected bool
+	Healthy   bool
+	Endpoints []EndpointStats
+}
+
+// NewClientPool creates a pool for the chains of a registry
+func NewClientPool(registry *ChainRegistry) *ClientPool {
+	return &ClientPool{
+		registry:    registry,
+		clients:     make(map[uint64]*EthereumClient),
+		retryPolicy: DefaultRetryPolicy,
+	}
+}
+
+// DialSingleChain dials a single RPC URL and returns a pool holding only the
+// chain it serves, named after its well-known name. It is used when no chains
+// file is configured.
+func DialSingleChain(ctx context.Context, rpcURL string) (*ClientPool, error) {
+	client, err := NewEthereumClient(ctx, rpcURL)
+	if err != nil {
+		return nil, err
+	}
+
+	registry, err := NewChainRegistry([]ChainConfig{client.Chain()})
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	pool := NewClientPool(registry)
+	pool.clients[client.ChainID()] = client
+	return pool, nil
+}
+
+// Registry returns the chain registry of the pool
+func (p *ClientPool) Registry() *ChainRegistry {
+	return p.registry
+}
+
+// Client returns the client of the chain selected by a chain ID or name, an
+// empty selector selecting the default chain. Unknown chains are rejected
+// with an error wrapping ErrUnknownChain.
+func (p *ClientPool) Client(ctx context.Context, chain string) (*EthereumClient, error) {
+	config, err := p.registry.Lookup(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[config.ID]; ok {
+		return client, nil
+	}
+
+	client, err := DialChain(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to chain %s: %w", config.Name, err)
+	}
+	client.SetRPCTimeout(p.rpcTimeout)
+	client.SetRetryPolicy(p.retryPolicy)
+	client.SetRateLimit(p.rateLimit)
+	client.StartHealthChecks(p.healthInterval)
+	p.clients[config.ID] = client
+	return client, nil
+}
+
+// SetHealthCheckInterval makes every client of the pool, dialed already or
+// later, probe its endpoints in the background every interval. A zero
+// interval disables the background checks for clients dialed later.
+func (p *ClientPool) SetHealthCheckInterval(interval time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.healthInterval = interval
+	for _, client := range p.clients {
+		client.StartHealthChecks(interval)
+	}
+}
+
+// SetRPCTimeout sets the timeout of single RPC requests of every client of
+// the pool, dialed already or later. Non-positive values restore
+// DefaultRPCTimeout.
+func (p *ClientPool) SetRPCTimeout(timeout time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rpcTimeout = timeout
+	for _, client := range p.clients {
+		client.SetRPCTimeout(timeout)
+	}
+}
+
+// SetRetryPolicy sets how requests that failed on every endpoint are retried
+// by every client of the pool, dialed already or later
+func (p *ClientPool) SetRetryPolicy(policy RetryPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.retryPolicy = policy
+	for _, client := range p.clients {
+		client.SetRetryPolicy(policy)
+	}
+}
+
+// SetRateLimit limits the requests sent to each endpoint without a rate
+// limit in the chain configuration, for every client of the pool, dialed
+// already or later. A zero limit removes the limit.
+func (p *ClientPool) SetRateLimit(limit RateLimit) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rateLimit = limit
+	for _, client := range p.clients {
+		client.SetRateLimit(limit)
+	}
+}
+
+// Status returns the endpoint status of every chain of the registry in
+// registry order
+func (p *ClientPool) Status() []ChainStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	chains := p.registry.Chains()
+	statuses := make([]ChainStatus, 0, len(chains))
+	for _, chain := range chains {
+		status := ChainStatus{Chain: chain}
+		if client, ok := p.clients[chain.ID]; ok {
+			status.Connected = true
+			status.Healthy = client.Healthy()
+			status.Endpoints = client.EndpointStats()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// Close closes every client dialed by the pool
+func (p *ClientPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, client := range p.clients {
+		client.Close()
+		delete(p.clients, id)
+	}
+}
@@ -0,0 +1,84 @@
+package ethereum
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateLimit bounds the requests sent to an endpoint, typically to stay within
+// the plan of its provider. Requests beyond Burst wait for tokens that refill
+// at RequestsPerSecond. A zero Burst allows bursts of one second worth of
+// requests.
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst,omitempty"`
+}
+
+// tokenBucket is a client-side token bucket limiter. Tokens may go negative:
+// each waiter reserves its tokens up front and waits until they are refilled,
+// so that waiters are served in order.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates a full bucket for limit, or returns nil for a zero
+// limit, which does not limit requests
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(limit.RequestsPerSecond))
+	}
+	return &tokenBucket{rate: limit.RequestsPerSecond, burst: burst, tokens: burst}
+}
+
+// reserve takes n tokens and returns how long the caller has to wait until
+// they are available
+func (b *tokenBucket) reserve(n int, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// release returns n reserved tokens that were not used
+func (b *tokenBucket) release(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+float64(n))
+}
+
+// wait takes n tokens, waiting until they are available or ctx is done. A nil
+// bucket never waits.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	if b == nil {
+		return nil
+	}
+
+	delay := b.reserve(n, time.Now())
+	if delay == 0 {
+		return nil
+	}
+	if err := sleep(ctx, delay); err != nil {
+		b.release(n)
+		return err
+	}
+	return nil
+}
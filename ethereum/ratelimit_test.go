@@ -0,0 +1,96 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{RequestsPerSecond: 10, Burst: 2})
+	now := time.Now()
+
+	if delay := bucket.reserve(1, now); delay != 0 {
+		t.Errorf("first reserve() = %s, want 0", delay)
+	}
+	if delay := bucket.reserve(1, now); delay != 0 {
+		t.Errorf("second reserve() = %s, want 0", delay)
+	}
+	// the burst is used up, so the next tokens refill at 10 per second
+	if delay := bucket.reserve(1, now); delay != 100*time.Millisecond {
+		t.Errorf("third reserve() = %s, want 100ms", delay)
+	}
+	if delay := bucket.reserve(2, now); delay != 300*time.Millisecond {
+		t.Errorf("fourth reserve() = %s, want 300ms", delay)
+	}
+
+	// refilled tokens never exceed the burst
+	later := now.Add(time.Hour)
+	for i := 0; i < 2; i++ {
+		if delay := bucket.reserve(1, later); delay != 0 {
+			t.Errorf("reserve() after refill = %s, want 0", delay)
+		}
+	}
+	if delay := bucket.reserve(1, later); delay == 0 {
+		t.Error("reserve() beyond the burst = 0, want a delay")
+	}
+}
+
+func TestTokenBucketDefaultBurst(t *testing.T) {
+	if bucket := newTokenBucket(RateLimit{}); bucket != nil {
+		t.Errorf("newTokenBucket() without a rate = %+v, want nil", bucket)
+	}
+	if bucket := newTokenBucket(RateLimit{RequestsPerSecond: 2.5}); bucket.burst != 3 {
+		t.Errorf("burst = %v, want 3", bucket.burst)
+	}
+	if bucket := newTokenBucket(RateLimit{RequestsPerSecond: 0.5}); bucket.burst != 1 {
+		t.Errorf("burst = %v, want 1", bucket.burst)
+	}
+}
+
+func TestTokenBucketWaitCanceled(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{RequestsPerSecond: 1, Burst: 1})
+	if err := bucket.wait(context.Background(), 1); err != nil {
+		t.Fatalf("wait() error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := bucket.wait(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("wait() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// the token of the canceled wait is given back
+	if bucket.tokens < -0.1 {
+		t.Errorf("tokens = %v, want about 0", bucket.tokens)
+	}
+}
+
+func TestRateLimitedEndpoint(t *testing.T) {
+	up := newRPCServer(t, 1, 0)
+
+	ec, err := DialChain(context.Background(), ChainConfig{
+		ID:         1,
+		Name:       "ethereum",
+		RPCURLs:    []string{up.URL},
+		RateLimits: map[string]RateLimit{"127.0.0.1": {RequestsPerSecond: 20, Burst: 1}},
+	})
+	if err != nil {
+		t.Fatalf("DialChain() error: %v", err)
+	}
+	defer ec.Close()
+
+	// the configured limit takes precedence over the client-wide one
+	ec.SetRateLimit(RateLimit{RequestsPerSecond: 1000})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := ec.BlockNumber(context.Background()); err != nil {
+			t.Fatalf("BlockNumber() error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests took %s, want at least 100ms at 20 per second", elapsed)
+	}
+}
@@ -0,0 +1,127 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// JSON-RPC error codes (EIP-1474) that mean the request may succeed when
+// repeated later
+const (
+	internalErrorCode       = -32603
+	resourceUnavailableCode = -32002
+)
+
+// RetryPolicy controls how often a request that failed on every endpoint is
+// repeated. The delay before retry n (starting at 0) is drawn at random
+// between half and all of MinBackoff * 2^n, capped at MaxBackoff. A
+// Retry-After sent by the provider extends the delay; requests are not
+// retried if it asks to wait longer than MaxBackoff.
+type RetryPolicy struct {
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of new clients
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 250 * time.Millisecond,
+	MaxBackoff: 10 * time.Second,
+}
+
+// backoff returns the delay before retry n, using r in [0, 1) for the jitter
+func (p RetryPolicy) backoff(n int, r float64) time.Duration {
+	delay := p.MinBackoff
+	for i := 0; i < n && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay/2 + time.Duration(r*float64(delay/2))
+}
+
+// IsRetryable reports whether a request that failed with err may succeed
+// when repeated: rate limits, transport errors, timeouts, 408 and 5xx
+// responses, and the JSON-RPC internal error and resource unavailable codes.
+// Reverts, other JSON-RPC errors, other 4xx responses, endpoints serving the
+// wrong chain and cancellation by the caller are fatal.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || IsRevert(err) {
+		return false
+	}
+	if IsRateLimited(err) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusRequestTimeout || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		code := rpcErr.ErrorCode()
+		return code == internalErrorCode || code == resourceUnavailableCode
+	}
+	return errors.Is(err, context.DeadlineExceeded) || isTransportError(err)
+}
+
+// sleep waits for d or until ctx is done, returning ctx.Err() in the latter
+// case
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, either a number
+// of seconds or an HTTP date, into the time the provider accepts requests
+// again
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return time.Time{}, false
+		}
+		return now.Add(time.Duration(seconds) * time.Second), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date, true
+	}
+	return time.Time{}, false
+}
+
+// retryAfterTransport records the Retry-After header of 429 and 503
+// responses on the endpoint that received them, since JSON-RPC errors do not
+// carry response headers
+type retryAfterTransport struct {
+	ep   *endpoint
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if until, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			t.ep.setRetryAfter(until)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,194 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// codeError is a JSON-RPC error with a code
+type codeError struct {
+	code int
+}
+
+func (e *codeError) Error() string  { return fmt.Sprintf("error %d", e.code) }
+func (e *codeError) ErrorCode() int { return e.code }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "too many requests", err: rpc.HTTPError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "limit exceeded", err: &codeError{code: limitExceededCode}, want: true},
+		{name: "server error", err: rpc.HTTPError{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "request timeout", err: rpc.HTTPError{StatusCode: http.StatusRequestTimeout}, want: true},
+		{name: "unauthorized", err: rpc.HTTPError{StatusCode: http.StatusUnauthorized}, want: false},
+		{name: "internal error", err: &codeError{code: internalErrorCode}, want: true},
+		{name: "resource unavailable", err: &codeError{code: resourceUnavailableCode}, want: true},
+		{name: "method not found", err: &codeError{code: -32601}, want: false},
+		{name: "invalid params", err: &codeError{code: -32602}, want: false},
+		{name: "connection reset", err: fmt.Errorf("read: %w", syscall.ECONNRESET), want: true},
+		{name: "timeout", err: context.DeadlineExceeded, want: true},
+		{name: "canceled", err: context.Canceled, want: false},
+		{name: "revert", err: &ReasonError{Reason: "paused"}, want: false},
+		{name: "chain mismatch", err: fmt.Errorf("%w: endpoint serves chain 5", ErrChainMismatch), want: false},
+		{name: "some endpoints down", err: errors.Join(ErrChainMismatch, rpc.HTTPError{StatusCode: http.StatusBadGateway}), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 10, MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		retry int
+		r     float64
+		want  time.Duration
+	}{
+		{retry: 0, r: 0, want: 50 * time.Millisecond},
+		{retry: 0, r: 0.5, want: 75 * time.Millisecond},
+		{retry: 1, r: 0, want: 100 * time.Millisecond},
+		{retry: 2, r: 0.5, want: 300 * time.Millisecond},
+		{retry: 4, r: 0, want: 500 * time.Millisecond},
+		{retry: 40, r: 0.5, want: 750 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := policy.backoff(tt.retry, tt.r); got != tt.want {
+			t.Errorf("backoff(%d, %v) = %s, want %s", tt.retry, tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value  string
+		want   time.Time
+		wantOK bool
+	}{
+		{value: "", wantOK: false},
+		{value: "3", want: now.Add(3 * time.Second), wantOK: true},
+		{value: "-1", wantOK: false},
+		{value: "Mon, 01 Jan 2024 12:01:00 GMT", want: now.Add(time.Minute), wantOK: true},
+		{value: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	flaky := newRPCServer(t, 1, http.StatusServiceUnavailable)
+	flaky.failures.Store(2)
+
+	ec, err := DialChain(context.Background(), ChainConfig{ID: 1, Name: "ethereum", RPCURLs: []string{flaky.URL}})
+	if err != nil {
+		t.Fatalf("DialChain() error: %v", err)
+	}
+	defer ec.Close()
+	ec.SetRetryPolicy(RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	if _, err := ec.BlockNumber(context.Background()); err != nil {
+		t.Fatalf("BlockNumber() error: %v", err)
+	}
+	// two failed requests, then the chain ID check and the block number
+	if got := flaky.requests.Load(); got != 4 {
+		t.Errorf("endpoint got %d requests, want 4", got)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	down := newRPCServer(t, 1, http.StatusBadGateway)
+
+	ec, err := DialChain(context.Background(), ChainConfig{ID: 1, Name: "ethereum", RPCURLs: []string{down.URL}})
+	if err != nil {
+		t.Fatalf("DialChain() error: %v", err)
+	}
+	defer ec.Close()
+	ec.SetRetryPolicy(RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	if _, err := ec.BlockNumber(context.Background()); err == nil {
+		t.Fatal("BlockNumber() succeeded, want error")
+	}
+	if got := down.requests.Load(); got != 3 {
+		t.Errorf("endpoint got %d requests, want 3", got)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	limited := newRPCServer(t, 1, http.StatusTooManyRequests)
+	limited.retryAfter = "120"
+
+	ec, err := DialChain(context.Background(), ChainConfig{ID: 1, Name: "ethereum", RPCURLs: []string{limited.URL}})
+	if err != nil {
+		t.Fatalf("DialChain() error: %v", err)
+	}
+	defer ec.Close()
+
+	// the provider asks to wait longer than the maximum backoff, so the
+	// request is not retried
+	_, err = ec.BlockNumber(context.Background())
+	if !IsRateLimited(err) {
+		t.Fatalf("BlockNumber() error = %v, want rate limited", err)
+	}
+	if got := limited.requests.Load(); got != 1 {
+		t.Errorf("endpoint got %d requests, want 1", got)
+	}
+
+	// the endpoint cools down until the Retry-After has passed
+	stats := ec.EndpointStats()
+	if stats[0].Healthy {
+		t.Error("rate limited endpoint is healthy, want unhealthy")
+	}
+	if wait := ec.retryAfterWait(time.Now()); wait < 110*time.Second || wait > 120*time.Second {
+		t.Errorf("retryAfterWait() = %s, want about 120s", wait)
+	}
+	if !ec.endpoints[0].available(time.Now().Add(121 * time.Second)) {
+		t.Error("endpoint unavailable after the Retry-After, want available")
+	}
+}
+
+func TestRetryFatalError(t *testing.T) {
+	up := newRPCServer(t, 1, 0)
+
+	ec, err := DialChain(context.Background(), ChainConfig{ID: 1, Name: "ethereum", RPCURLs: []string{up.URL}})
+	if err != nil {
+		t.Fatalf("DialChain() error: %v", err)
+	}
+	defer ec.Close()
+	ec.SetRetryPolicy(RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	var calls int
+	err = ec.do(context.Background(), func(ctx context.Context, client *ethclient.Client) error {
+		calls++
+		return &codeError{code: -32602}
+	})
+	if err == nil {
+		t.Fatal("do() succeeded, want error")
+	}
+	if calls != 1 {
+		t.Errorf("op ran %d times, want 1", calls)
+	}
+}
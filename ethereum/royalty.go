@@ -0,0 +1,73 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// RoyaltySalePrice is the sale price royaltyInfo is queried with. At 10000
+// the returned royalty amount equals the rate in basis points.
+const RoyaltySalePrice = 10000
+
+// royaltyABI is the ABI of ERC-2981 royaltyInfo
+var royaltyABI = mustParseABI(`[{
+	"type": "function",
+	"name": "royaltyInfo",
+	"stateMutability": "view",
+	"inputs": [{"name": "tokenId", "type": "uint256"}, {"name": "salePrice", "type": "uint256"}],
+	"outputs": [{"name": "receiver", "type": "address"}, {"name": "royaltyAmount", "type": "uint256"}]
+}]`)
+
+// Royalty is the ERC-2981 royalty of a token: the receiver and the rate in
+// basis points (1/100 of a percent) of the sale price
+type Royalty struct {
+	Receiver    common.Address
+	BasisPoints uint32
+}
+
+// GetRoyaltyInfo calls royaltyInfo on an ERC-2981 contract at the latest block
+func (ec *EthereumClient) GetRoyaltyInfo(ctx context.Context, contractAddress string, tokenID *big.Int) (Royalty, error) {
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return Royalty{}, err
+	}
+
+	data, err := royaltyABI.Pack("royaltyInfo", tokenID, big.NewInt(RoyaltySalePrice))
+	if err != nil {
+		return Royalty{}, fmt.Errorf("failed to pack function call: %v", err)
+	}
+
+	result, err := ec.call(ctx, ethereum.CallMsg{To: &contractAddr, Data: data})
+	if err != nil {
+		return Royalty{}, fmt.Errorf("failed to call royaltyInfo: %w", decodeCallError(err))
+	}
+	if len(result) == 0 {
+		return Royalty{}, fmt.Errorf("failed to unpack royaltyInfo result: %w", ErrEmptyResult)
+	}
+
+	return decodeRoyalty(result)
+}
+
+// decodeRoyalty decodes a royaltyInfo result queried at RoyaltySalePrice.
+// Royalties above the sale price are rejected.
+func decodeRoyalty(result []byte) (Royalty, error) {
+	var out struct {
+		Receiver      common.Address
+		RoyaltyAmount *big.Int
+	}
+	if err := royaltyABI.UnpackIntoInterface(&out, "royaltyInfo", result); err != nil {
+		return Royalty{}, fmt.Errorf("failed to unpack royaltyInfo result: %v", err)
+	}
+	if out.RoyaltyAmount.Cmp(big.NewInt(RoyaltySalePrice)) > 0 {
+		return Royalty{}, fmt.Errorf("royalty of %s exceeds the sale price of %d", out.RoyaltyAmount, RoyaltySalePrice)
+	}
+
+	return Royalty{
+		Receiver:    out.Receiver,
+		BasisPoints: uint32(out.RoyaltyAmount.Uint64()),
+	}, nil
+}
@@ -0,0 +1,37 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDecodeRoyalty(t *testing.T) {
+	receiver := common.HexToAddress("0xA858DDc0445d8131daC4d1DE01f834ffcbA52Ef1")
+	outputs := royaltyABI.Methods["royaltyInfo"].Outputs
+
+	packed, err := outputs.Pack(receiver, big.NewInt(250))
+	if err != nil {
+		t.Fatal(err)
+	}
+	royalty, err := decodeRoyalty(packed)
+	if err != nil {
+		t.Fatalf("decodeRoyalty error: %v", err)
+	}
+	if royalty.Receiver != receiver || royalty.BasisPoints != 250 {
+		t.Errorf("decodeRoyalty = %+v, want %s at 250 basis points", royalty, receiver.Hex())
+	}
+
+	excessive, err := outputs.Pack(receiver, big.NewInt(RoyaltySalePrice+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decodeRoyalty(excessive); err == nil {
+		t.Error("decodeRoyalty accepted a royalty above the sale price")
+	}
+
+	if _, err := decodeRoyalty([]byte{0x01}); err == nil {
+		t.Error("decodeRoyalty accepted a malformed result")
+	}
+}
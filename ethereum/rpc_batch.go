@@ -0,0 +1,91 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultRPCBatchLimit is the default maximum number of requests sent in a
+// single JSON-RPC batch. Most hosted providers reject larger batches.
+const DefaultRPCBatchLimit = 100
+
+// SetRPCBatchLimit sets the maximum number of requests per JSON-RPC batch
+func (ec *EthereumClient) SetRPCBatchLimit(limit int) {
+	if limit <= 0 {
+		limit = DefaultRPCBatchLimit
+	}
+
+	ec.batchMu.Lock()
+	defer ec.batchMu.Unlock()
+	ec.rpcBatchLimit = limit
+}
+
+// RPCBatchLimit returns the maximum number of requests per JSON-RPC batch
+func (ec *EthereumClient) RPCBatchLimit() int {
+	ec.batchMu.Lock()
+	defer ec.batchMu.Unlock()
+	return ec.rpcBatchLimit
+}
+
+// batchOwners reads the owners of a batch of tokens by sending one eth_call
+// per token in a single JSON-RPC batch request. Errors of individual calls are
+// reported per token; only transport failures fail the whole batch.
+func (ec *EthereumClient) batchOwners(ctx context.Context, contractAddr common.Address, tokenIDs []*big.Int, block BlockRef) ([]OwnerResult, error) {
+	var blockArg interface{}
+	if block.Hash != nil {
+		blockArg = rpc.BlockNumberOrHashWithHash(*block.Hash, false)
+	} else {
+		blockArg = hexutil.EncodeBig(block.Number)
+	}
+
+	elems := make([]rpc.BatchElem, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		data, err := ec.contractABI.Pack("ownerOf", tokenID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pack function call: %v", err)
+		}
+
+		callArg := map[string]interface{}{
+			"to":   contractAddr,
+			"data": hexutil.Bytes(data),
+		}
+		elems = append(elems, rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{callArg, blockArg},
+			Result: new(hexutil.Bytes),
+		})
+	}
+
+	err := ec.doCalls(ctx, len(elems), func(ctx context.Context, client *ethclient.Client) error {
+		return client.Client().BatchCallContext(ctx, elems)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send JSON-RPC batch: %w", err)
+	}
+
+	results := make([]OwnerResult, 0, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		result := OwnerResult{TokenID: tokenID}
+		if elems[i].Error != nil {
+			result.Err = fmt.Errorf("failed to call contract: %w", decodeCallError(elems[i].Error))
+		} else if output := *elems[i].Result.(*hexutil.Bytes); len(output) == 0 {
+			result.Err = fmt.Errorf("failed to unpack result: %w", ErrEmptyResult)
+		} else {
+			var owner common.Address
+			if err := ec.contractABI.UnpackIntoInterface(&owner, "ownerOf", output); err != nil {
+				result.Err = fmt.Errorf("failed to unpack result: %v", err)
+			} else {
+				result.Owner = owner.Hex()
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,84 @@
+package ethereum
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestGetOwnersOfRPCBatch(t *testing.T) {
+	// without code at the Multicall address the calls fall back to JSON-RPC
+	// batches of eth_call
+	server := newCallServer(t, false, map[int64]common.Address{1: testHolder, 3: testHolder, 4: testHolder, 5: testHolder})
+	ec := server.dial(t)
+
+	results, blockNumber, err := ec.GetOwnersOf(context.Background(), testCollection.Hex(), tokenIDs(5), BlockAt(100), 2)
+	if err != nil {
+		t.Fatalf("GetOwnersOf() error: %v", err)
+	}
+	if blockNumber != 100 {
+		t.Errorf("block number = %d, want 100", blockNumber)
+	}
+
+	if len(server.aggregates) != 0 {
+		t.Errorf("got aggregate3 calls %v, want none", server.aggregates)
+	}
+	if len(server.batches) != 3 || server.batches[0] != 2 || server.batches[1] != 2 || server.batches[2] != 1 {
+		t.Errorf("JSON-RPC batches = %v, want [2 2 1]", server.batches)
+	}
+
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5", len(results))
+	}
+	for i, result := range results {
+		if result.TokenID.Int64() != int64(i+1) {
+			t.Errorf("result %d is for token %s, want %d", i, result.TokenID, i+1)
+		}
+		if i+1 == 2 {
+			// a failing element does not fail the rest of its batch
+			if !IsNonexistentToken(result.Err) {
+				t.Errorf("token 2 error = %v, want nonexistent token", result.Err)
+			}
+			continue
+		}
+		if result.Err != nil || result.Owner != testHolder.Hex() {
+			t.Errorf("token %d = %s, %v, want %s", i+1, result.Owner, result.Err, testHolder.Hex())
+		}
+	}
+}
+
+func TestGetOwnersOfRPCBatchLimit(t *testing.T) {
+	server := newCallServer(t, false, map[int64]common.Address{})
+	ec := server.dial(t)
+	ec.SetRPCBatchLimit(3)
+
+	// the batch size is clamped to the batch limit of the provider
+	results, _, err := ec.GetOwnersOf(context.Background(), testCollection.Hex(), tokenIDs(7), BlockAt(100), 500)
+	if err != nil {
+		t.Fatalf("GetOwnersOf() error: %v", err)
+	}
+	if len(results) != 7 {
+		t.Fatalf("got %d results, want 7", len(results))
+	}
+	if len(server.batches) != 3 || server.batches[0] != 3 || server.batches[1] != 3 || server.batches[2] != 1 {
+		t.Errorf("JSON-RPC batches = %v, want [3 3 1]", server.batches)
+	}
+}
+
+func TestGetOwnersOfMulticallIgnoresBatchLimit(t *testing.T) {
+	server := newCallServer(t, true, map[int64]common.Address{})
+	ec := server.dial(t)
+	ec.SetRPCBatchLimit(3)
+
+	// a single aggregate3 call is one request however many calls it packs
+	if _, _, err := ec.GetOwnersOf(context.Background(), testCollection.Hex(), tokenIDs(7), BlockAt(100), 500); err != nil {
+		t.Fatalf("GetOwnersOf() error: %v", err)
+	}
+	if len(server.aggregates) != 1 || server.aggregates[0] != 7 {
+		t.Errorf("aggregate3 calls = %v, want [7]", server.aggregates)
+	}
+	if len(server.batches) != 0 {
+		t.Errorf("got JSON-RPC batches %v, want none", server.batches)
+	}
+}
@@ -0,0 +1,66 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// metadataABI holds the ERC-721 and ERC-1155 metadata URI functions
+var metadataABI = mustParseABI(`[
+	{"type": "function", "name": "tokenURI", "stateMutability": "view", "inputs": [{"name": "tokenId", "type": "uint256"}], "outputs": [{"name": "", "type": "string"}]},
+	{"type": "function", "name": "uri", "stateMutability": "view", "inputs": [{"name": "id", "type": "uint256"}], "outputs": [{"name": "", "type": "string"}]}
+]`)
+
+// GetTokenURI calls tokenURI on an ERC-721 contract at the latest block. If
+// the call reverts the error wraps one of the typed revert errors, e.g. a
+// *NonexistentTokenError.
+func (ec *EthereumClient) GetTokenURI(ctx context.Context, contractAddress string, tokenID *big.Int) (string, error) {
+	return ec.callURI(ctx, contractAddress, "tokenURI", tokenID)
+}
+
+// GetURI calls uri on an ERC-1155 contract at the latest block and
+// substitutes the {id} placeholder with the token ID as ERC-1155 specifies
+func (ec *EthereumClient) GetURI(ctx context.Context, contractAddress string, tokenID *big.Int) (string, error) {
+	uri, err := ec.callURI(ctx, contractAddress, "uri", tokenID)
+	if err != nil {
+		return "", err
+	}
+	return SubstituteTokenID(uri, tokenID), nil
+}
+
+// SubstituteTokenID replaces every {id} in an ERC-1155 metadata URI with the
+// token ID as 64 lowercase hex digits without 0x prefix
+func SubstituteTokenID(uri string, tokenID *big.Int) string {
+	return strings.ReplaceAll(uri, "{id}", fmt.Sprintf("%064x", tokenID))
+}
+
+// callURI calls a metadata URI function taking a token ID
+func (ec *EthereumClient) callURI(ctx context.Context, contractAddress, method string, tokenID *big.Int) (string, error) {
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := metadataABI.Pack(method, tokenID)
+	if err != nil {
+		return "", fmt.Errorf("failed to pack function call: %v", err)
+	}
+
+	result, err := ec.call(ctx, ethereum.CallMsg{To: &contractAddr, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("failed to call %s: %w", method, decodeCallError(err))
+	}
+	if len(result) == 0 {
+		return "", fmt.Errorf("failed to unpack %s result: %w", method, ErrEmptyResult)
+	}
+
+	var uri string
+	if err := metadataABI.UnpackIntoInterface(&uri, method, result); err != nil {
+		return "", fmt.Errorf("failed to unpack %s result: %v", method, err)
+	}
+	return strings.TrimSpace(uri), nil
+}
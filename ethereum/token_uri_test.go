@@ -0,0 +1,18 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSubstituteTokenID(t *testing.T) {
+	uri := SubstituteTokenID("https://token-cdn-domain/{id}.json", big.NewInt(314592))
+	want := "https://token-cdn-domain/000000000000000000000000000000000000000000000000000000000004cce0.json"
+	if uri != want {
+		t.Errorf("SubstituteTokenID = %q, want %q", uri, want)
+	}
+
+	if got := SubstituteTokenID("ipfs://QmDoc/1.json", big.NewInt(1)); got != "ipfs://QmDoc/1.json" {
+		t.Errorf("SubstituteTokenID without placeholder = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,127 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// TransferEventTopic is the topic of Transfer(address,address,uint256)
+var TransferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Transfer is a decoded ERC-721 Transfer event. BlockTimestamp is the Unix
+// time of the block if the node included it in the log, 0 otherwise.
+type Transfer struct {
+	From           common.Address
+	To             common.Address
+	TokenID        *big.Int
+	BlockNumber    uint64
+	BlockHash      common.Hash
+	BlockTimestamp uint64
+	TxHash         common.Hash
+	LogIndex       uint
+	Removed        bool
+}
+
+// IsMint reports whether the transfer created the token
+func (t Transfer) IsMint() bool {
+	return t.From == (common.Address{})
+}
+
+// IsBurn reports whether the transfer destroyed the token
+func (t Transfer) IsBurn() bool {
+	return t.To == (common.Address{})
+}
+
+// DecodeTransfer decodes an ERC-721 Transfer log. ERC-20 Transfer logs share
+// the same signature but do not index the third argument, so they are rejected.
+func DecodeTransfer(log types.Log) (Transfer, error) {
+	if len(log.Topics) != 4 || log.Topics[0] != TransferEventTopic {
+		return Transfer{}, fmt.Errorf("log %d of tx %s is not an ERC-721 Transfer event", log.Index, log.TxHash.Hex())
+	}
+
+	return Transfer{
+		From:           common.BytesToAddress(log.Topics[1].Bytes()),
+		To:             common.BytesToAddress(log.Topics[2].Bytes()),
+		TokenID:        new(big.Int).SetBytes(log.Topics[3].Bytes()),
+		BlockNumber:    log.BlockNumber,
+		BlockHash:      log.BlockHash,
+		BlockTimestamp: log.BlockTimestamp,
+		TxHash:         log.TxHash,
+		LogIndex:       log.Index,
+		Removed:        log.Removed,
+	}, nil
+}
+
+// TransferFilter returns the log filter matching Transfer events of a contract
+// between fromBlock and toBlock (inclusive)
+func TransferFilter(contractAddress common.Address, fromBlock, toBlock uint64) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{contractAddress},
+		Topics:    [][]common.Hash{{TransferEventTopic}},
+	}
+}
+
+// FilterTransfers returns the ERC-721 Transfer events emitted by a contract
+// between fromBlock and toBlock (inclusive) in chain order
+func (ec *EthereumClient) FilterTransfers(ctx context.Context, contractAddress string, fromBlock, toBlock uint64) ([]Transfer, error) {
+	contractAddr, err := ParseAddress(contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := ec.filterLogs(ctx, TransferFilter(contractAddr, fromBlock, toBlock))
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter Transfer logs in blocks %d-%d: %w", fromBlock, toBlock, err)
+	}
+
+	transfers := make([]Transfer, 0, len(logs))
+	for _, log := range logs {
+		transfer, err := DecodeTransfer(log)
+		if err != nil {
+			// ERC-20 style Transfer events are skipped
+			continue
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, nil
+}
+
+// SubscribeTransfers subscribes to new Transfer events of the given contracts.
+// The client must be connected over a websocket or IPC endpoint. ctx only
+// bounds setting up the subscription, which lasts until it is unsubscribed.
+func (ec *EthereumClient) SubscribeTransfers(ctx context.Context, contractAddresses []string, logs chan<- types.Log) (ethereum.Subscription, error) {
+	addresses := make([]common.Address, 0, len(contractAddresses))
+	for _, contractAddress := range contractAddresses {
+		address, err := ParseAddress(contractAddress)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, address)
+	}
+
+	query := ethereum.FilterQuery{
+		Addresses: addresses,
+		Topics:    [][]common.Hash{{TransferEventTopic}},
+	}
+
+	var sub ethereum.Subscription
+	err := ec.do(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		sub, err = client.SubscribeFilterLogs(ctx, query, logs)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to Transfer logs: %w", err)
+	}
+
+	return sub, nil
+}
@@ -1,16 +1,23 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"go-cli-eth/dto"
+	"go-cli-eth/ethereum"
 	"go-cli-eth/models"
 	"go-cli-eth/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// errMissingContractAddress is returned when a lookup lacks the contract address
+var errMissingContractAddress = errors.New("contract_address query parameter is required")
+
 // NFTHandler handles NFT-related API endpoints
 type NFTHandler struct {
 	nftService *services.NFTService
@@ -25,43 +32,53 @@ func NewNFTHandler(nftService *services.NFTService) *NFTHandler {
 
 // GetAndStoreOwner godoc
 // @Summary Get and store NFT owner from blockchain
-// @Description Fetches the owner of an NFT from the blockchain and stores it in the database
+// @Description Fetches the owner of an NFT from the blockchain at an optional block and stores it in the database
 // @Tags NFT
 // @Accept json
 // @Produce json
 // @Param request body dto.GetOwnerRequest true "Get owner request"
 // @Success 200 {object} dto.SuccessResponse{data=dto.NFTResponse}
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_token_id, invalid_block, invalid_address or unknown_chain"
+// @Failure 404 {object} dto.ErrorResponse "token_nonexistent"
+// @Failure 410 {object} dto.ErrorResponse "token_burned"
+// @Failure 422 {object} dto.ErrorResponse "not_erc721 or call_reverted"
+// @Failure 429 {object} dto.ErrorResponse "rate_limited"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
 // @Router /api/nft/owner [post]
 func (h *NFTHandler) GetAndStoreOwner(c *gin.Context) {
 	var req dto.GetOwnerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Success: false,
-			Message: "Invalid request data",
-			Error:   err.Error(),
-		})
+		respondBadRequest(c, "Invalid request data", bindingErrorCode(err), err)
 		return
 	}
+	warnChecksum(c, req.ContractAddress)
 
-	nft, err := h.nftService.GetAndStoreOwner(req.ContractAddress, req.TokenID)
+	tokenID, err := models.ParseTokenID(req.TokenID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Success: false,
-			Message: "Failed to get and store NFT owner",
-			Error:   err.Error(),
-		})
+		respondBadRequest(c, "Invalid token ID", CodeInvalidTokenID, err)
 		return
 	}
 
-	response := dto.NFTResponse{
-		TokenID:   nft.TokenID,
-		Owner:     nft.Owner,
-		CreatedAt: nft.CreatedAt,
-		UpdatedAt: nft.UpdatedAt,
+	block, err := ethereum.ParseBlockRef(req.Block)
+	if err != nil {
+		respondBadRequest(c, "Invalid block", CodeInvalidBlock, err)
+		return
 	}
 
+	nftService, ok := h.chainService(c, req.Chain)
+	if !ok {
+		return
+	}
+
+	nft, err := nftService.GetAndStoreOwner(c.Request.Context(), req.ContractAddress, tokenID, block)
+	if err != nil {
+		respondError(c, "Failed to get and store NFT owner", err)
+		return
+	}
+
+	response := ConvertModelToDTO(nft)
+
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Message: "NFT owner retrieved and stored successfully",
@@ -71,49 +88,54 @@ func (h *NFTHandler) GetAndStoreOwner(c *gin.Context) {
 
 // UpdateOwner godoc
 // @Summary Update NFT owner data
-// @Description Updates an existing NFT record with current blockchain data
+// @Description Updates an existing NFT record with blockchain data at an optional block
 // @Tags NFT
 // @Accept json
 // @Produce json
 // @Param request body dto.UpdateOwnerRequest true "Update owner request"
 // @Success 200 {object} dto.SuccessResponse{data=dto.NFTResponse}
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_token_id, invalid_block, invalid_address or unknown_chain"
+// @Failure 404 {object} dto.ErrorResponse "not_found or token_nonexistent"
+// @Failure 409 {object} dto.ErrorResponse "stale_block"
+// @Failure 410 {object} dto.ErrorResponse "token_burned"
+// @Failure 422 {object} dto.ErrorResponse "not_erc721 or call_reverted"
+// @Failure 429 {object} dto.ErrorResponse "rate_limited"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
 // @Router /api/nft/owner [put]
 func (h *NFTHandler) UpdateOwner(c *gin.Context) {
 	var req dto.UpdateOwnerRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Success: false,
-			Message: "Invalid request data",
-			Error:   err.Error(),
-		})
+		respondBadRequest(c, "Invalid request data", bindingErrorCode(err), err)
 		return
 	}
+	warnChecksum(c, req.ContractAddress)
 
-	nft, err := h.nftService.UpdateOwner(req.ContractAddress, req.TokenID)
+	tokenID, err := models.ParseTokenID(req.TokenID)
 	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "NFT with token ID "+strconv.Itoa(int(req.TokenID))+" not found in database" {
-			statusCode = http.StatusNotFound
-		}
+		respondBadRequest(c, "Invalid token ID", CodeInvalidTokenID, err)
+		return
+	}
 
-		c.JSON(statusCode, dto.ErrorResponse{
-			Success: false,
-			Message: "Failed to update NFT owner",
-			Error:   err.Error(),
-		})
+	block, err := ethereum.ParseBlockRef(req.Block)
+	if err != nil {
+		respondBadRequest(c, "Invalid block", CodeInvalidBlock, err)
 		return
 	}
 
-	response := dto.NFTResponse{
-		TokenID:   nft.TokenID,
-		Owner:     nft.Owner,
-		CreatedAt: nft.CreatedAt,
-		UpdatedAt: nft.UpdatedAt,
+	nftService, ok := h.chainService(c, req.Chain)
+	if !ok {
+		return
 	}
 
+	nft, err := nftService.UpdateOwner(c.Request.Context(), req.ContractAddress, tokenID, block)
+	if err != nil {
+		respondError(c, "Failed to update NFT owner", err)
+		return
+	}
+
+	response := ConvertModelToDTO(nft)
+
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Message: "NFT owner updated successfully",
@@ -123,49 +145,45 @@ func (h *NFTHandler) UpdateOwner(c *gin.Context) {
 
 // GetNFTByTokenID godoc
 // @Summary Get NFT by token ID
-// @Description Retrieves an NFT record from the database by token ID
+// @Description Retrieves an NFT record of a contract from the database by token ID
 // @Tags NFT
 // @Produce json
-// @Param token_id path int true "Token ID"
+// @Param token_id path string true "Token ID (decimal or 0x-prefixed hex)"
+// @Param contract_address query string true "Contract address or ENS name"
+// @Param chain query string false "Chain ID or name (default the default chain)"
 // @Success 200 {object} dto.SuccessResponse{data=dto.NFTResponse}
-// @Failure 400 {object} dto.ErrorResponse
-// @Failure 404 {object} dto.ErrorResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_token_id, invalid_address or unknown_chain"
+// @Failure 404 {object} dto.ErrorResponse "not_found"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
 // @Router /api/nft/{token_id} [get]
 func (h *NFTHandler) GetNFTByTokenID(c *gin.Context) {
-	tokenIDStr := c.Param("token_id")
-	tokenID, err := strconv.ParseUint(tokenIDStr, 10, 32)
+	tokenID, err := models.ParseTokenID(c.Param("token_id"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
-			Success: false,
-			Message: "Invalid token ID",
-			Error:   err.Error(),
-		})
+		respondBadRequest(c, "Invalid token ID", CodeInvalidTokenID, err)
 		return
 	}
 
-	nft, err := h.nftService.GetNFTByTokenID(uint(tokenID))
-	if err != nil {
-		statusCode := http.StatusInternalServerError
-		if err.Error() == "NFT with token ID "+tokenIDStr+" not found" {
-			statusCode = http.StatusNotFound
-		}
+	contractAddress := c.Query("contract_address")
+	if contractAddress == "" {
+		respondBadRequest(c, "Invalid request data", CodeInvalidRequest, errMissingContractAddress)
+		return
+	}
+	warnChecksum(c, contractAddress)
 
-		c.JSON(statusCode, dto.ErrorResponse{
-			Success: false,
-			Message: "Failed to get NFT",
-			Error:   err.Error(),
-		})
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
 		return
 	}
 
-	response := dto.NFTResponse{
-		TokenID:   nft.TokenID,
-		Owner:     nft.Owner,
-		CreatedAt: nft.CreatedAt,
-		UpdatedAt: nft.UpdatedAt,
+	nft, err := nftService.GetNFTByTokenID(c.Request.Context(), contractAddress, tokenID)
+	if err != nil {
+		respondError(c, "Failed to get NFT", err)
+		return
 	}
 
+	response := ConvertModelToDTO(nft)
+
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Message: "NFT retrieved successfully",
@@ -175,31 +193,34 @@ func (h *NFTHandler) GetNFTByTokenID(c *gin.Context) {
 
 // GetAllNFTs godoc
 // @Summary Get all NFTs
-// @Description Retrieves all NFT records from the database
+// @Description Retrieves all NFT records from the database, optionally filtered by contract
 // @Tags NFT
 // @Produce json
+// @Param contract_address query string false "Contract address or ENS name"
+// @Param chain query string false "Chain ID or name (default the default chain)"
 // @Success 200 {object} dto.NFTListResponse
-// @Failure 500 {object} dto.ErrorResponse
+// @Failure 400 {object} dto.ErrorResponse "invalid_address or unknown_chain"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
 // @Router /api/nft [get]
 func (h *NFTHandler) GetAllNFTs(c *gin.Context) {
-	nfts, err := h.nftService.GetAllNFTs()
+	contractAddress := c.Query("contract_address")
+	warnChecksum(c, contractAddress)
+
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
+		return
+	}
+
+	nfts, err := nftService.GetAllNFTs(c.Request.Context(), contractAddress)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
-			Success: false,
-			Message: "Failed to get NFTs",
-			Error:   err.Error(),
-		})
+		respondError(c, "Failed to get NFTs", err)
 		return
 	}
 
-	var nftResponses []dto.NFTResponse
-	for _, nft := range nfts {
-		nftResponses = append(nftResponses, dto.NFTResponse{
-			TokenID:   nft.TokenID,
-			Owner:     nft.Owner,
-			CreatedAt: nft.CreatedAt,
-			UpdatedAt: nft.UpdatedAt,
-		})
+	nftResponses := make([]dto.NFTResponse, 0, len(nfts))
+	for i := range nfts {
+		nftResponses = append(nftResponses, ConvertModelToDTO(&nfts[i]))
 	}
 
 	c.JSON(http.StatusOK, dto.NFTListResponse{
@@ -210,30 +231,629 @@ func (h *NFTHandler) GetAllNFTs(c *gin.Context) {
 	})
 }
 
+// GetOwnershipHistory godoc
+// @Summary Get NFT ownership history
+// @Description Retrieves the recorded ownership changes of an NFT, newest first
+// @Tags NFT
+// @Produce json
+// @Param token_id path string true "Token ID (decimal or 0x-prefixed hex)"
+// @Param contract_address query string true "Contract address or ENS name"
+// @Param chain query string false "Chain ID or name (default the default chain)"
+// @Success 200 {object} dto.OwnershipHistoryListResponse
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_token_id, invalid_address or unknown_chain"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
+// @Router /api/nft/{token_id}/history [get]
+func (h *NFTHandler) GetOwnershipHistory(c *gin.Context) {
+	tokenID, err := models.ParseTokenID(c.Param("token_id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid token ID", CodeInvalidTokenID, err)
+		return
+	}
+
+	contractAddress := c.Query("contract_address")
+	if contractAddress == "" {
+		respondBadRequest(c, "Invalid request data", CodeInvalidRequest, errMissingContractAddress)
+		return
+	}
+	warnChecksum(c, contractAddress)
+
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
+		return
+	}
+
+	history, err := nftService.GetOwnershipHistory(c.Request.Context(), contractAddress, tokenID)
+	if err != nil {
+		respondError(c, "Failed to get ownership history", err)
+		return
+	}
+
+	historyResponses := make([]dto.OwnershipHistoryResponse, 0, len(history))
+	for i := range history {
+		historyResponses = append(historyResponses, ConvertHistoryToDTO(&history[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.OwnershipHistoryListResponse{
+		Success: true,
+		Message: "Ownership history retrieved successfully",
+		Data:    historyResponses,
+		Count:   len(historyResponses),
+	})
+}
+
+// GetMetadata godoc
+// @Summary Get NFT metadata
+// @Description Returns the metadata document of a token with its name, description, image and attributes. It is fetched through tokenURI (ERC-721) or uri (ERC-1155) on first use and stored.
+// @Tags NFT
+// @Produce json
+// @Param token_id path string true "Token ID (decimal or 0x-prefixed hex)"
+// @Param contract_address query string true "Contract address or ENS name"
+// @Param refresh query bool false "Fetch the metadata again"
+// @Param chain query string false "Chain ID or name (default the default chain)"
+// @Success 200 {object} dto.SuccessResponse{data=dto.MetadataResponse}
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_token_id, invalid_address or unknown_chain"
+// @Failure 404 {object} dto.ErrorResponse "token_nonexistent"
+// @Failure 422 {object} dto.ErrorResponse "not_erc721, call_reverted or invalid_metadata"
+// @Failure 429 {object} dto.ErrorResponse "rate_limited"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable or metadata_unavailable"
+// @Router /api/nft/{token_id}/metadata [get]
+func (h *NFTHandler) GetMetadata(c *gin.Context) {
+	tokenID, err := models.ParseTokenID(c.Param("token_id"))
+	if err != nil {
+		respondBadRequest(c, "Invalid token ID", CodeInvalidTokenID, err)
+		return
+	}
+
+	contractAddress := c.Query("contract_address")
+	if contractAddress == "" {
+		respondBadRequest(c, "Invalid request data", CodeInvalidRequest, errMissingContractAddress)
+		return
+	}
+	warnChecksum(c, contractAddress)
+
+	refresh, err := queryBool(c, "refresh")
+	if err != nil {
+		respondBadRequest(c, "Invalid request data", CodeInvalidRequest, err)
+		return
+	}
+
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
+		return
+	}
+
+	meta, err := nftService.GetMetadata(c.Request.Context(), contractAddress, tokenID, refresh)
+	if err != nil {
+		respondError(c, "Failed to get metadata", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Metadata retrieved successfully",
+		Data:    ConvertMetadataToDTO(meta),
+	})
+}
+
+// GetContract godoc
+// @Summary Get contract interfaces
+// @Description Returns the interfaces a contract advertises through ERC-165 and its token standard. They are probed on first use and stored.
+// @Tags Contract
+// @Produce json
+// @Param address path string true "Contract address or ENS name"
+// @Param refresh query bool false "Probe the interfaces again"
+// @Param chain query string false "Chain ID or name (default the default chain)"
+// @Success 200 {object} dto.SuccessResponse{data=dto.ContractResponse}
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_address or unknown_chain"
+// @Failure 422 {object} dto.ErrorResponse "not_erc721 (no code at the address)"
+// @Failure 429 {object} dto.ErrorResponse "rate_limited"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
+// @Router /api/contracts/{address} [get]
+func (h *NFTHandler) GetContract(c *gin.Context) {
+	refresh, err := queryBool(c, "refresh")
+	if err != nil {
+		respondBadRequest(c, "Invalid request data", CodeInvalidRequest, err)
+		return
+	}
+
+	address := c.Param("address")
+	warnChecksum(c, address)
+
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
+		return
+	}
+
+	contract, err := nftService.InspectContract(c.Request.Context(), address, refresh)
+	if err != nil {
+		respondError(c, "Failed to get contract", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Contract retrieved successfully",
+		Data:    ConvertContractToDTO(contract),
+	})
+}
+
+// RegisterCollection godoc
+// @Summary Register a collection
+// @Description Registers a contract as a collection, reading its name, symbol, contractURI and, for ERC-721 Enumerable contracts, totalSupply. Registering it again refreshes them.
+// @Tags Collection
+// @Accept json
+// @Produce json
+// @Param request body dto.RegisterCollectionRequest true "Register collection request"
+// @Success 200 {object} dto.SuccessResponse{data=dto.CollectionResponse}
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_address or unknown_chain"
+// @Failure 422 {object} dto.ErrorResponse "not_erc721 (no code or another standard)"
+// @Failure 429 {object} dto.ErrorResponse "rate_limited"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
+// @Router /api/collections [post]
+func (h *NFTHandler) RegisterCollection(c *gin.Context) {
+	var req dto.RegisterCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request data", bindingErrorCode(err), err)
+		return
+	}
+	warnChecksum(c, req.ContractAddress)
+
+	nftService, ok := h.chainService(c, req.Chain)
+	if !ok {
+		return
+	}
+
+	collection, err := nftService.RegisterCollection(c.Request.Context(), req.ContractAddress)
+	if err != nil {
+		respondError(c, "Failed to register collection", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Collection registered successfully",
+		Data:    ConvertCollectionToDTO(collection),
+	})
+}
+
+// GetCollections godoc
+// @Summary Get all collections
+// @Description Retrieves all registered collections with the number of tokens tracked for each
+// @Tags Collection
+// @Produce json
+// @Param chain query string false "Chain ID or name (default the default chain)"
+// @Success 200 {object} dto.CollectionListResponse
+// @Failure 400 {object} dto.ErrorResponse "unknown_chain"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
+// @Router /api/collections [get]
+func (h *NFTHandler) GetCollections(c *gin.Context) {
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
+		return
+	}
+
+	collections, err := nftService.GetCollections(c.Request.Context())
+	if err != nil {
+		respondError(c, "Failed to get collections", err)
+		return
+	}
+
+	collectionResponses := make([]dto.CollectionResponse, 0, len(collections))
+	for i := range collections {
+		collectionResponses = append(collectionResponses, ConvertCollectionToDTO(&collections[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.CollectionListResponse{
+		Success: true,
+		Message: "Collections retrieved successfully",
+		Data:    collectionResponses,
+		Count:   len(collectionResponses),
+	})
+}
+
+// GetCollection godoc
+// @Summary Get a collection
+// @Description Retrieves a registered collection with the number of tokens tracked for it
+// @Tags Collection
+// @Produce json
+// @Param address path string true "Contract address or ENS name"
+// @Param chain query string false "Chain ID or name (default the default chain)"
+// @Success 200 {object} dto.SuccessResponse{data=dto.CollectionResponse}
+// @Failure 400 {object} dto.ErrorResponse "invalid_address or unknown_chain"
+// @Failure 404 {object} dto.ErrorResponse "not_found"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
+// @Router /api/collections/{address} [get]
+func (h *NFTHandler) GetCollection(c *gin.Context) {
+	address := c.Param("address")
+	warnChecksum(c, address)
+
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
+		return
+	}
+
+	collection, err := nftService.GetCollection(c.Request.Context(), address)
+	if err != nil {
+		respondError(c, "Failed to get collection", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Collection retrieved successfully",
+		Data:    ConvertCollectionToDTO(collection),
+	})
+}
+
+// GetAndStoreBalance godoc
+// @Summary Get and store ERC-1155 balance from blockchain
+// @Description Reads the balance a holder has of an ERC-1155 token at an optional block and stores it. A zero balance removes the stored holder.
+// @Tags Balance
+// @Accept json
+// @Produce json
+// @Param request body dto.GetBalanceRequest true "Get balance request"
+// @Success 200 {object} dto.SuccessResponse{data=dto.BalanceResponse}
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_token_id, invalid_block, invalid_address or unknown_chain"
+// @Failure 409 {object} dto.ErrorResponse "stale_block"
+// @Failure 422 {object} dto.ErrorResponse "not_erc1155 or call_reverted"
+// @Failure 429 {object} dto.ErrorResponse "rate_limited"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
+// @Router /api/balances [post]
+func (h *NFTHandler) GetAndStoreBalance(c *gin.Context) {
+	var req dto.GetBalanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBadRequest(c, "Invalid request data", bindingErrorCode(err), err)
+		return
+	}
+	warnChecksum(c, req.ContractAddress)
+	warnChecksum(c, req.Holder)
+
+	tokenID, err := models.ParseTokenID(req.TokenID)
+	if err != nil {
+		respondBadRequest(c, "Invalid token ID", CodeInvalidTokenID, err)
+		return
+	}
+
+	block, err := ethereum.ParseBlockRef(req.Block)
+	if err != nil {
+		respondBadRequest(c, "Invalid block", CodeInvalidBlock, err)
+		return
+	}
+
+	nftService, ok := h.chainService(c, req.Chain)
+	if !ok {
+		return
+	}
+
+	balance, err := nftService.GetAndStoreBalance(c.Request.Context(), req.ContractAddress, tokenID, req.Holder, block)
+	if err != nil {
+		respondError(c, "Failed to get and store balance", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse{
+		Success: true,
+		Message: "Balance retrieved and stored successfully",
+		Data:    ConvertBalanceToDTO(balance),
+	})
+}
+
+// GetBalances godoc
+// @Summary Get ERC-1155 balances
+// @Description Retrieves the stored holders of an ERC-1155 contract, optionally narrowed to a token and a holder
+// @Tags Balance
+// @Produce json
+// @Param contract_address query string true "Contract address or ENS name"
+// @Param token_id query string false "Token ID (decimal or 0x-prefixed hex)"
+// @Param holder query string false "Holder address or ENS name"
+// @Param chain query string false "Chain ID or name (default the default chain)"
+// @Success 200 {object} dto.BalanceListResponse
+// @Failure 400 {object} dto.ErrorResponse "invalid_request, invalid_token_id, invalid_address or unknown_chain"
+// @Failure 500 {object} dto.ErrorResponse "internal_error"
+// @Failure 502 {object} dto.ErrorResponse "chain_unavailable"
+// @Router /api/balances [get]
+func (h *NFTHandler) GetBalances(c *gin.Context) {
+	contractAddress := c.Query("contract_address")
+	if contractAddress == "" {
+		respondBadRequest(c, "Invalid request data", CodeInvalidRequest, errMissingContractAddress)
+		return
+	}
+	warnChecksum(c, contractAddress)
+
+	var tokenID *models.TokenID
+	if value := c.Query("token_id"); value != "" {
+		parsed, err := models.ParseTokenID(value)
+		if err != nil {
+			respondBadRequest(c, "Invalid token ID", CodeInvalidTokenID, err)
+			return
+		}
+		tokenID = &parsed
+	}
+
+	holder := c.Query("holder")
+	warnChecksum(c, holder)
+
+	nftService, ok := h.chainService(c, c.Query("chain"))
+	if !ok {
+		return
+	}
+
+	balances, err := nftService.GetBalances(c.Request.Context(), contractAddress, tokenID, holder)
+	if err != nil {
+		respondError(c, "Failed to get balances", err)
+		return
+	}
+
+	balanceResponses := make([]dto.BalanceResponse, 0, len(balances))
+	for i := range balances {
+		balanceResponses = append(balanceResponses, ConvertBalanceToDTO(&balances[i]))
+	}
+
+	c.JSON(http.StatusOK, dto.BalanceListResponse{
+		Success: true,
+		Message: "Balances retrieved successfully",
+		Data:    balanceResponses,
+		Count:   len(balanceResponses),
+	})
+}
+
+// GetChains godoc
+// @Summary Get configured chains
+// @Description Lists the chains the API can read from. Requests select a chain with the chain parameter and use the default chain without it.
+// @Tags Chain
+// @Produce json
+// @Success 200 {object} dto.ChainListResponse
+// @Router /api/chains [get]
+func (h *NFTHandler) GetChains(c *gin.Context) {
+	chains := h.nftService.Chains()
+	defaultID := h.nftService.Chain().ID
+
+	chainResponses := make([]dto.ChainResponse, 0, len(chains))
+	for _, chain := range chains {
+		chainResponses = append(chainResponses, ConvertChainToDTO(chain, chain.ID == defaultID))
+	}
+
+	c.JSON(http.StatusOK, dto.ChainListResponse{
+		Success: true,
+		Message: "Chains retrieved successfully",
+		Data:    chainResponses,
+		Count:   len(chainResponses),
+	})
+}
+
 // HealthCheck godoc
 // @Summary Health check endpoint
-// @Description Returns the health status of the API
+// @Description Returns the health status of the API and of the RPC endpoints of every chain. The status is degraded, with a 503 response, when a connected chain has no healthy endpoint.
 // @Tags Health
 // @Produce json
-// @Success 200 {object} dto.SuccessResponse
+// @Success 200 {object} dto.SuccessResponse{data=dto.HealthResponse}
+// @Failure 503 {object} dto.SuccessResponse{data=dto.HealthResponse}
 // @Router /health [get]
 func (h *NFTHandler) HealthCheck(c *gin.Context) {
+	health := dto.HealthResponse{
+		Status:  "ok",
+		Service: "go-cli-eth",
+	}
+	for _, status := range h.nftService.RPCStatus() {
+		health.Chains = append(health.Chains, ConvertChainStatusToDTO(status))
+		if status.Connected && !status.Healthy {
+			health.Status = "degraded"
+		}
+	}
+
+	if health.Status != "ok" {
+		c.JSON(http.StatusServiceUnavailable, dto.SuccessResponse{
+			Success: false,
+			Message: "API is degraded: a chain has no healthy RPC endpoint",
+			Data:    health,
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, dto.SuccessResponse{
 		Success: true,
 		Message: "API is healthy",
-		Data: gin.H{
-			"status":  "ok",
-			"service": "go-cli-eth",
-		},
+		Data:    health,
 	})
 }
 
+// chainService returns the NFT service of the chain a request selects. If the
+// chain is unknown or unreachable the error response is written and false is
+// returned.
+func (h *NFTHandler) chainService(c *gin.Context, chain string) (*services.NFTService, bool) {
+	nftService, err := h.nftService.ForChain(c.Request.Context(), chain)
+	if err != nil {
+		respondError(c, "Failed to select chain", err)
+		return nil, false
+	}
+	return nftService, true
+}
+
+// queryBool parses an optional boolean query parameter, which defaults to false
+func queryBool(c *gin.Context, name string) (bool, error) {
+	value := c.Query(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s query parameter %q: must be true or false", name, value)
+	}
+	return parsed, nil
+}
+
+// warnChecksum adds a Warning header if a mixed-case address does not match
+// its EIP-55 checksum. Such addresses are still accepted but likely mistyped.
+func warnChecksum(c *gin.Context, address string) {
+	if address != "" && !ethereum.IsENSName(address) && !ethereum.HasValidChecksum(address) {
+		c.Header("Warning", fmt.Sprintf(`299 - "%s does not match its EIP-55 checksum"`, address))
+	}
+}
+
 // ConvertModelToDTO converts models.NFT to dto.NFTResponse
 func ConvertModelToDTO(nft *models.NFT) dto.NFTResponse {
 	return dto.NFTResponse{
-		TokenID:   nft.TokenID,
-		Owner:     nft.Owner,
-		CreatedAt: nft.CreatedAt,
-		UpdatedAt: nft.UpdatedAt,
+		ChainID:            nft.ChainID,
+		ContractAddress:    nft.ContractAddress,
+		TokenID:            nft.TokenID.String(),
+		Owner:              nft.Owner,
+		OwnerENS:           nft.OwnerENS,
+		RoyaltyReceiver:    nft.RoyaltyReceiver,
+		RoyaltyBasisPoints: nft.RoyaltyBasisPoints,
+		BlockNumber:        nft.BlockNumber,
+		CreatedAt:          nft.CreatedAt,
+		UpdatedAt:          nft.UpdatedAt,
+	}
+}
+
+// ConvertHistoryToDTO converts models.OwnershipHistory to dto.OwnershipHistoryResponse
+func ConvertHistoryToDTO(entry *models.OwnershipHistory) dto.OwnershipHistoryResponse {
+	return dto.OwnershipHistoryResponse{
+		ChainID:         entry.ChainID,
+		ContractAddress: entry.ContractAddress,
+		TokenID:         entry.TokenID.String(),
+		PreviousOwner:   entry.PreviousOwner,
+		NewOwner:        entry.NewOwner,
+		BlockNumber:     entry.BlockNumber,
+		ObservedAt:      entry.ObservedAt,
+	}
+}
+
+// ConvertContractToDTO converts models.Contract to dto.ContractResponse
+func ConvertContractToDTO(contract *models.Contract) dto.ContractResponse {
+	return dto.ContractResponse{
+		ChainID:          contract.ChainID,
+		Address:          contract.Address,
+		Standard:         contract.Standard,
+		ERC165:           contract.ERC165,
+		ERC721:           contract.ERC721,
+		ERC721Metadata:   contract.ERC721Metadata,
+		ERC721Enumerable: contract.ERC721Enumerable,
+		ERC1155:          contract.ERC1155,
+		ERC2981:          contract.ERC2981,
+		DetectedAt:       contract.DetectedAt,
+	}
+}
+
+// ConvertBalanceToDTO converts models.TokenBalance to dto.BalanceResponse
+func ConvertBalanceToDTO(balance *models.TokenBalance) dto.BalanceResponse {
+	return dto.BalanceResponse{
+		ChainID:         balance.ChainID,
+		ContractAddress: balance.ContractAddress,
+		TokenID:         balance.TokenID.String(),
+		Holder:          balance.Holder,
+		Balance:         balance.Balance.String(),
+		BlockNumber:     balance.BlockNumber,
+		CreatedAt:       balance.CreatedAt,
+		UpdatedAt:       balance.UpdatedAt,
+	}
+}
+
+// ConvertMetadataToDTO converts models.NFTMetadata to dto.MetadataResponse
+func ConvertMetadataToDTO(meta *models.NFTMetadata) dto.MetadataResponse {
+	attributes := make([]dto.AttributeResponse, 0, len(meta.Attributes))
+	for _, attr := range meta.Attributes {
+		attributes = append(attributes, dto.AttributeResponse{
+			TraitType:   attr.TraitType,
+			Value:       attr.Value,
+			DisplayType: attr.DisplayType,
+		})
+	}
+
+	return dto.MetadataResponse{
+		ChainID:         meta.ChainID,
+		ContractAddress: meta.ContractAddress,
+		TokenID:         meta.TokenID.String(),
+		URI:             meta.URI,
+		Name:            meta.Name,
+		Description:     meta.Description,
+		Image:           meta.Image,
+		Attributes:      attributes,
+		Raw:             json.RawMessage(meta.Raw),
+		FetchedAt:       meta.FetchedAt,
+	}
+}
+
+// ConvertCollectionToDTO converts models.Collection to dto.CollectionResponse
+func ConvertCollectionToDTO(collection *models.Collection) dto.CollectionResponse {
+	response := dto.CollectionResponse{
+		ChainID:            collection.ChainID,
+		Address:            collection.Address,
+		Standard:           collection.Standard,
+		Name:               collection.Name,
+		Symbol:             collection.Symbol,
+		ContractURI:        collection.ContractURI,
+		RoyaltyReceiver:    collection.RoyaltyReceiver,
+		RoyaltyBasisPoints: collection.RoyaltyBasisPoints,
+		TokenCount:         collection.TokenCount,
+		RegisteredAt:       collection.RegisteredAt,
+		UpdatedAt:          collection.UpdatedAt,
+	}
+	if collection.TotalSupply != nil {
+		response.TotalSupply = collection.TotalSupply.String()
+	}
+	return response
+}
+
+// ConvertChainToDTO converts ethereum.ChainConfig to dto.ChainResponse
+func ConvertChainToDTO(chain ethereum.ChainConfig, isDefault bool) dto.ChainResponse {
+	response := dto.ChainResponse{
+		ChainID:          chain.ID,
+		Name:             chain.Name,
+		Endpoints:        len(chain.RPCURLs),
+		Confirmations:    chain.Confirmations,
+		MulticallAddress: chain.MulticallAddress,
+		Default:          isDefault,
+	}
+	if response.MulticallAddress == "" {
+		response.MulticallAddress = ethereum.DefaultMulticallAddress
+	}
+	return response
+}
+
+// ConvertChainStatusToDTO converts ethereum.ChainStatus to dto.ChainHealthResponse
+func ConvertChainStatusToDTO(status ethereum.ChainStatus) dto.ChainHealthResponse {
+	response := dto.ChainHealthResponse{
+		ChainID:   status.Chain.ID,
+		Name:      status.Chain.Name,
+		Selection: status.Chain.Selection,
+		Connected: status.Connected,
+		Healthy:   status.Healthy,
+	}
+	if response.Selection == "" {
+		response.Selection = ethereum.SelectionPriority
+	}
+	for _, stats := range status.Endpoints {
+		response.Endpoints = append(response.Endpoints, ConvertEndpointStatsToDTO(stats))
+	}
+	return response
+}
+
+// ConvertEndpointStatsToDTO converts ethereum.EndpointStats to dto.EndpointStatusResponse
+func ConvertEndpointStatsToDTO(stats ethereum.EndpointStats) dto.EndpointStatusResponse {
+	response := dto.EndpointStatusResponse{
+		URL:          stats.URL,
+		Healthy:      stats.Healthy,
+		Requests:     stats.Requests,
+		Failures:     stats.Failures,
+		AvgLatencyMs: stats.AvgLatency.Milliseconds(),
+		HeadBlock:    stats.HeadBlock,
+		LastError:    stats.LastError,
+	}
+	if !stats.LastErrorAt.IsZero() {
+		lastErrorAt := stats.LastErrorAt
+		response.LastErrorAt = &lastErrorAt
+	}
+	if !stats.LastCheckedAt.IsZero() {
+		lastCheckedAt := stats.LastCheckedAt
+		response.LastCheckedAt = &lastCheckedAt
 	}
+	return response
 }
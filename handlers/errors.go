@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"go-cli-eth/dto"
+	"go-cli-eth/ethereum"
+	"go-cli-eth/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// Machine-readable error codes returned in dto.ErrorResponse
+const (
+	CodeInvalidRequest      = "invalid_request"
+	CodeInvalidTokenID      = "invalid_token_id"
+	CodeInvalidBlock        = "invalid_block"
+	CodeInvalidAddress      = "invalid_address"
+	CodeInvalidTokenRange   = "invalid_token_range"
+	CodeUnknownChain        = "unknown_chain"
+	CodeNotFound            = "not_found"
+	CodeTokenNonexistent    = "token_nonexistent"
+	CodeTokenBurned         = "token_burned"
+	CodeStaleBlock          = "stale_block"
+	CodeNotERC721           = "not_erc721"
+	CodeNotERC1155          = "not_erc1155"
+	CodeNotERC2981          = "not_erc2981"
+	CodeCallReverted        = "call_reverted"
+	CodeInvalidMetadata     = "invalid_metadata"
+	CodeMetadataUnavailable = "metadata_unavailable"
+	CodeRateLimited         = "rate_limited"
+	CodeChainUnavailable    = "chain_unavailable"
+	CodeInternal            = "internal_error"
+)
+
+// errorMappings maps service errors to HTTP status codes and error codes.
+// The first matching entry wins.
+var errorMappings = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{services.ErrInvalidAddress, http.StatusBadRequest, CodeInvalidAddress},
+	{services.ErrInvalidTokenRange, http.StatusBadRequest, CodeInvalidTokenRange},
+	{services.ErrUnknownChain, http.StatusBadRequest, CodeUnknownChain},
+	{services.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{services.ErrTokenBurned, http.StatusGone, CodeTokenBurned},
+	{services.ErrStaleBlock, http.StatusConflict, CodeStaleBlock},
+	{services.ErrNotERC721, http.StatusUnprocessableEntity, CodeNotERC721},
+	{services.ErrNotERC1155, http.StatusUnprocessableEntity, CodeNotERC1155},
+	{services.ErrNotERC2981, http.StatusUnprocessableEntity, CodeNotERC2981},
+	{services.ErrCallReverted, http.StatusUnprocessableEntity, CodeCallReverted},
+	{services.ErrInvalidMetadata, http.StatusUnprocessableEntity, CodeInvalidMetadata},
+	{services.ErrMetadataUnavailable, http.StatusBadGateway, CodeMetadataUnavailable},
+	{services.ErrRateLimited, http.StatusTooManyRequests, CodeRateLimited},
+	{services.ErrChainUnavailable, http.StatusBadGateway, CodeChainUnavailable},
+}
+
+// errorStatus returns the HTTP status code and error code for a service error
+func errorStatus(err error) (int, string) {
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			return mapping.status, mapping.code
+		}
+	}
+	if ethereum.IsNonexistentToken(err) {
+		return http.StatusNotFound, CodeTokenNonexistent
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
+
+// respondError writes the error response for a failed service call
+func respondError(c *gin.Context, message string, err error) {
+	status, code := errorStatus(err)
+	c.JSON(status, dto.ErrorResponse{
+		Success: false,
+		Message: message,
+		Code:    code,
+		Error:   err.Error(),
+	})
+}
+
+// respondBadRequest writes the error response for an invalid request
+func respondBadRequest(c *gin.Context, message, code string, err error) {
+	c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		Success: false,
+		Message: message,
+		Code:    code,
+		Error:   err.Error(),
+	})
+}
+
+// bindingErrorCode returns the error code for a request that failed binding.
+// Malformed contract addresses get CodeInvalidAddress.
+func bindingErrorCode(err error) string {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		for _, fieldErr := range validationErrors {
+			if fieldErr.Tag() == contractAddressTag {
+				return CodeInvalidAddress
+			}
+		}
+	}
+	return CodeInvalidRequest
+}
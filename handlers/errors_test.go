@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-cli-eth/dto"
+	"go-cli-eth/ethereum"
+	"go-cli-eth/metadata"
+	"go-cli-eth/services"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{
+			name:   "not found",
+			err:    fmt.Errorf("NFT with token ID 1 of 0xabc %w", services.ErrNotFound),
+			status: http.StatusNotFound,
+			code:   CodeNotFound,
+		},
+		{
+			name:   "invalid address",
+			err:    fmt.Errorf("%w \"hello\"", services.ErrInvalidAddress),
+			status: http.StatusBadRequest,
+			code:   CodeInvalidAddress,
+		},
+		{
+			name:   "unknown chain",
+			err:    fmt.Errorf("%w \"base\": configured chains are ethereum, polygon", services.ErrUnknownChain),
+			status: http.StatusBadRequest,
+			code:   CodeUnknownChain,
+		},
+		{
+			name:   "unreachable chain",
+			err:    fmt.Errorf("%w: failed to connect to chain polygon: %w", services.ErrChainUnavailable, ethereum.ErrChainMismatch),
+			status: http.StatusBadGateway,
+			code:   CodeChainUnavailable,
+		},
+		{
+			name:   "nonexistent token",
+			err:    fmt.Errorf("failed to get owner from blockchain: %w", &ethereum.NonexistentTokenError{Cause: errors.New("reverted")}),
+			status: http.StatusNotFound,
+			code:   CodeTokenNonexistent,
+		},
+		{
+			name:   "burned token",
+			err:    fmt.Errorf("token ID 1 of 0xabc: %w", services.ErrTokenBurned),
+			status: http.StatusGone,
+			code:   CodeTokenBurned,
+		},
+		{
+			name:   "stale block",
+			err:    fmt.Errorf("%w: block 1 is older than the stored block 2", services.ErrStaleBlock),
+			status: http.StatusConflict,
+			code:   CodeStaleBlock,
+		},
+		{
+			name:   "not ERC-721",
+			err:    fmt.Errorf("%w: %w", services.ErrNotERC721, ethereum.ErrEmptyResult),
+			status: http.StatusUnprocessableEntity,
+			code:   CodeNotERC721,
+		},
+		{
+			name:   "not ERC-1155",
+			err:    fmt.Errorf("%w: 0x76BE3b62873462d2142405439777e971754E8E77 is detected as erc721", services.ErrNotERC1155),
+			status: http.StatusUnprocessableEntity,
+			code:   CodeNotERC1155,
+		},
+		{
+			name:   "not ERC-2981",
+			err:    fmt.Errorf("%w: 0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D does not advertise ERC-2981 through ERC-165", services.ErrNotERC2981),
+			status: http.StatusUnprocessableEntity,
+			code:   CodeNotERC2981,
+		},
+		{
+			name:   "invalid metadata",
+			err:    fmt.Errorf("failed to parse metadata of token ID 1: %w", metadata.ErrInvalidMetadata),
+			status: http.StatusUnprocessableEntity,
+			code:   CodeInvalidMetadata,
+		},
+		{
+			name:   "metadata unavailable",
+			err:    fmt.Errorf("failed to fetch metadata of token ID 1: %w", metadata.ErrUnavailable),
+			status: http.StatusBadGateway,
+			code:   CodeMetadataUnavailable,
+		},
+		{
+			name:   "rate limited",
+			err:    fmt.Errorf("failed to get owner from blockchain: %w", services.ErrRateLimited),
+			status: http.StatusTooManyRequests,
+			code:   CodeRateLimited,
+		},
+		{
+			name:   "chain unavailable",
+			err:    fmt.Errorf("%w: connection refused", services.ErrChainUnavailable),
+			status: http.StatusBadGateway,
+			code:   CodeChainUnavailable,
+		},
+		{
+			name:   "other",
+			err:    errors.New("failed to get NFT: connection reset"),
+			status: http.StatusInternalServerError,
+			code:   CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := errorStatus(tt.err)
+			if status != tt.status || code != tt.code {
+				t.Errorf("errorStatus() = %d %s, want %d %s", status, code, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestBindingErrorCode(t *testing.T) {
+	registerValidators()
+
+	tests := []struct {
+		name    string
+		address string
+		code    string
+	}{
+		{name: "valid", address: "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"},
+		{name: "lowercase", address: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"},
+		{name: "ENS name", address: "boredapeyachtclub.eth"},
+		{name: "missing", address: "", code: CodeInvalidRequest},
+		{name: "garbage", address: "hello", code: CodeInvalidAddress},
+		{name: "no prefix", address: "bc4ca0eda7647a8ab7c2061c2e118a18a936f13d", code: CodeInvalidAddress},
+		{name: "too short", address: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f1", code: CodeInvalidAddress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := binding.Validator.ValidateStruct(dto.GetOwnerRequest{ContractAddress: tt.address, TokenID: "1"})
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("ValidateStruct() error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("ValidateStruct() succeeded, want error")
+			}
+			if code := bindingErrorCode(err); code != tt.code {
+				t.Errorf("bindingErrorCode() = %s, want %s", code, tt.code)
+			}
+		})
+	}
+}
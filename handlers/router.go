@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// NewRouter creates a Gin engine with all NFT API routes and the Swagger UI registered
+func NewRouter(h *NFTHandler) *gin.Engine {
+	registerValidators()
+
+	router := gin.New()
+	router.Use(gin.Logger(), gin.Recovery())
+
+	router.GET("/health", h.HealthCheck)
+
+	api := router.Group("/api")
+	{
+		api.GET("/chains", h.GetChains)
+		api.GET("/nft", h.GetAllNFTs)
+		api.POST("/nft/owner", h.GetAndStoreOwner)
+		api.PUT("/nft/owner", h.UpdateOwner)
+		api.GET("/nft/:token_id", h.GetNFTByTokenID)
+		api.GET("/nft/:token_id/history", h.GetOwnershipHistory)
+		api.GET("/nft/:token_id/metadata", h.GetMetadata)
+		api.GET("/contracts/:address", h.GetContract)
+		api.GET("/collections", h.GetCollections)
+		api.POST("/collections", h.RegisterCollection)
+		api.GET("/collections/:address", h.GetCollection)
+		api.GET("/balances", h.GetBalances)
+		api.POST("/balances", h.GetAndStoreBalance)
+	}
+
+	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+
+	return router
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"sync"
+
+	"go-cli-eth/ethereum"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// contractAddressTag is the binding rule for contract inputs, which accept a
+// hex address or an ENS name
+const contractAddressTag = "contract_address"
+
+var registerOnce sync.Once
+
+// registerValidators adds the custom binding rules to gin's validator
+func registerValidators() {
+	registerOnce.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterValidation(contractAddressTag, isContractAddress)
+		}
+	})
+}
+
+// isContractAddress validates a hex address or an ENS name
+func isContractAddress(fl validator.FieldLevel) bool {
+	value := fl.Field().String()
+	if _, err := ethereum.ParseAddress(value); err == nil {
+		return true
+	}
+	return ethereum.IsENSName(value)
+}
@@ -0,0 +1,363 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// DefaultBatchSize is the default number of blocks scanned per FilterLogs call
+	DefaultBatchSize = 2000
+
+	// DefaultConfirmations is the default number of blocks a block must be
+	// buried under before its transfers are indexed
+	DefaultConfirmations = 12
+)
+
+// ChainReader is the subset of the Ethereum client used by the indexer
+type ChainReader interface {
+	ChainID() uint64
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number uint64) (*types.Header, error)
+	FilterTransfers(ctx context.Context, contractAddress string, fromBlock, toBlock uint64) ([]ethereum.Transfer, error)
+}
+
+// Config configures an indexer for a single contract
+type Config struct {
+	// ContractAddress is the ERC-721 contract whose Transfer events are indexed
+	ContractAddress string
+	// StartBlock is the first block scanned when no checkpoint exists yet,
+	// typically the contract deployment block
+	StartBlock uint64
+	// BatchSize is the number of blocks scanned per FilterLogs call
+	BatchSize uint64
+	// Confirmations is the number of blocks SyncToHead stays behind the head
+	Confirmations uint64
+}
+
+// Stats summarizes the work done by a sync
+type Stats struct {
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+	Transfers int    `json:"transfers"`
+	Mints     int    `json:"mints"`
+	Burns     int    `json:"burns"`
+	// ReorgBlock is the common ancestor the index was rolled back to if a
+	// reorganization was detected, nil otherwise
+	ReorgBlock *uint64 `json:"reorg_block,omitempty"`
+}
+
+// Indexer scans ERC-721 Transfer logs of a contract and keeps the nfts,
+// ownership_history and transfer_events tables in sync with them
+type Indexer struct {
+	db     *gorm.DB
+	chain  ChainReader
+	config Config
+}
+
+// NewIndexer creates a new indexer for the configured contract, storing what
+// it reads from chain in db. The contract address is stored checksummed;
+// malformed addresses are rejected.
+func NewIndexer(db *gorm.DB, chain ChainReader, config Config) (*Indexer, error) {
+	if config.BatchSize == 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+
+	contractAddress, err := ethereum.ParseAddress(config.ContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	config.ContractAddress = contractAddress.Hex()
+
+	return &Indexer{
+		db:     db,
+		chain:  chain,
+		config: config,
+	}, nil
+}
+
+// Checkpoint returns the last indexed block of the contract and whether a
+// checkpoint exists
+func (ix *Indexer) Checkpoint(ctx context.Context) (uint64, bool, error) {
+	var checkpoint models.IndexerCheckpoint
+	err := ix.scope(ix.db.WithContext(ctx)).First(&checkpoint).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to get indexer checkpoint: %v", err)
+	}
+
+	return checkpoint.LastBlock, true, nil
+}
+
+// Sync indexes all blocks from the checkpoint (or the start block) up to and
+// including toBlock, committing a checkpoint after every batch so that an
+// interrupted sync resumes where it stopped. Before indexing new blocks the
+// parent hash of the first new block is compared with the stored hash of the
+// checkpoint block; on a mismatch the orphaned blocks are rolled back and the
+// canonical chain is indexed from the common ancestor.
+func (ix *Indexer) Sync(ctx context.Context, toBlock uint64) (Stats, error) {
+	lastBlock, ok, err := ix.Checkpoint(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	fromBlock := ix.config.StartBlock
+	if ok {
+		fromBlock = lastBlock + 1
+	}
+
+	stats := Stats{FromBlock: fromBlock, ToBlock: toBlock}
+	if fromBlock > toBlock {
+		return stats, nil
+	}
+
+	if ok {
+		forkBlock, reorged, err := ix.checkReorg(ctx, lastBlock, fromBlock)
+		if err != nil {
+			return stats, err
+		}
+		if reorged {
+			stats.ReorgBlock = &forkBlock
+
+			// resume from the rolled back checkpoint, or from scratch if
+			// no common ancestor was found
+			lastBlock, ok, err = ix.Checkpoint(ctx)
+			if err != nil {
+				return stats, err
+			}
+			fromBlock = ix.config.StartBlock
+			if ok {
+				fromBlock = lastBlock + 1
+			}
+			stats.FromBlock = fromBlock
+		}
+	}
+
+	for start := fromBlock; start <= toBlock; start += ix.config.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+
+		end := start + ix.config.BatchSize - 1
+		if end > toBlock {
+			end = toBlock
+		}
+
+		header, err := ix.chain.HeaderByNumber(ctx, end)
+		if err != nil {
+			return stats, err
+		}
+
+		transfers, err := ix.chain.FilterTransfers(ctx, ix.config.ContractAddress, start, end)
+		if err != nil {
+			return stats, err
+		}
+
+		blockTimes, err := ix.blockTimes(ctx, transfers, header)
+		if err != nil {
+			return stats, err
+		}
+
+		if err := ix.apply(ctx, transfers, blockTimes, header); err != nil {
+			return stats, err
+		}
+
+		for _, transfer := range transfers {
+			stats.Transfers++
+			if transfer.IsMint() {
+				stats.Mints++
+			}
+			if transfer.IsBurn() {
+				stats.Burns++
+			}
+		}
+
+		log.Printf("Indexed blocks %d-%d of %s: %d transfer(s)", start, end, ix.config.ContractAddress, len(transfers))
+	}
+
+	return stats, nil
+}
+
+// SyncToHead indexes all blocks up to the current chain head minus the
+// configured confirmation depth
+func (ix *Indexer) SyncToHead(ctx context.Context) (Stats, error) {
+	head, err := ix.chain.BlockNumber(ctx)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	toBlock := uint64(0)
+	if head > ix.config.Confirmations {
+		toBlock = head - ix.config.Confirmations
+	}
+	return ix.Sync(ctx, toBlock)
+}
+
+// ContractAddress returns the checksummed address of the indexed contract
+func (ix *Indexer) ContractAddress() string {
+	return ix.config.ContractAddress
+}
+
+// blockTimes returns the time of every block that contains one of transfers.
+// Timestamps included in the logs are used as is; the headers of other blocks
+// are fetched once per block. header is the already fetched last block of the
+// batch.
+func (ix *Indexer) blockTimes(ctx context.Context, transfers []ethereum.Transfer, header *types.Header) (map[uint64]time.Time, error) {
+	times := map[uint64]time.Time{
+		header.Number.Uint64(): time.Unix(int64(header.Time), 0),
+	}
+
+	for _, transfer := range transfers {
+		if _, ok := times[transfer.BlockNumber]; ok {
+			continue
+		}
+		if transfer.BlockTimestamp != 0 {
+			times[transfer.BlockNumber] = time.Unix(int64(transfer.BlockTimestamp), 0)
+			continue
+		}
+
+		blockHeader, err := ix.chain.HeaderByNumber(ctx, transfer.BlockNumber)
+		if err != nil {
+			return nil, err
+		}
+		times[transfer.BlockNumber] = time.Unix(int64(blockHeader.Time), 0)
+	}
+
+	return times, nil
+}
+
+// apply persists a batch of transfers, records the hash of the batch's last
+// block and advances the checkpoint to it in a single transaction. blockTimes
+// maps the block number of every transfer to the time of its block.
+func (ix *Indexer) apply(ctx context.Context, transfers []ethereum.Transfer, blockTimes map[uint64]time.Time, header *types.Header) error {
+	chainID := ix.chain.ChainID()
+	lastBlock := header.Number.Uint64()
+
+	return ix.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, transfer := range transfers {
+			err := applyTransfer(tx, chainID, ix.config.ContractAddress, transfer, blockTimes[transfer.BlockNumber])
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := recordProcessedBlock(tx, chainID, ix.config.ContractAddress, header); err != nil {
+			return err
+		}
+
+		checkpoint := models.IndexerCheckpoint{
+			ChainID:         chainID,
+			ContractAddress: ix.config.ContractAddress,
+			LastBlock:       lastBlock,
+			UpdatedAt:       time.Now(),
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chain_id"}, {Name: "contract_address"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_block", "updated_at"}),
+		}).Create(&checkpoint).Error
+		if err != nil {
+			return fmt.Errorf("failed to save indexer checkpoint: %v", err)
+		}
+
+		return nil
+	})
+}
+
+// applyTransfer records a single transfer event, its ownership history entry
+// observed at the time of its block and the resulting owner of the token.
+// Events that were already stored are skipped so that re-scanning a range is
+// harmless.
+func applyTransfer(tx *gorm.DB, chainID uint64, contractAddress string, transfer ethereum.Transfer, blockTime time.Time) error {
+	tokenID, err := models.NewTokenID(transfer.TokenID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	event := models.TransferEvent{
+		ChainID:         chainID,
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		FromAddress:     transfer.From.Hex(),
+		ToAddress:       transfer.To.Hex(),
+		Kind:            transferKind(transfer),
+		BlockNumber:     transfer.BlockNumber,
+		BlockHash:       transfer.BlockHash.Hex(),
+		TxHash:          transfer.TxHash.Hex(),
+		LogIndex:        transfer.LogIndex,
+		CreatedAt:       now,
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+	if result.Error != nil {
+		return fmt.Errorf("failed to save transfer event: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	previousOwner := ""
+	if !transfer.IsMint() {
+		previousOwner = transfer.From.Hex()
+	}
+
+	err = tx.Create(&models.OwnershipHistory{
+		ChainID:         chainID,
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		PreviousOwner:   previousOwner,
+		NewOwner:        transfer.To.Hex(),
+		BlockNumber:     transfer.BlockNumber,
+		ObservedAt:      blockTime,
+		TransferEventID: &event.ID,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save ownership history: %v", err)
+	}
+
+	// Burned tokens keep their row with the zero address as owner
+	nft := models.NFT{
+		ChainID:         chainID,
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		Owner:           transfer.To.Hex(),
+		BlockNumber:     transfer.BlockNumber,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	err = tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain_id"}, {Name: "contract_address"}, {Name: "token_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"owner", "block_number", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{SQL: "nfts.block_number <= excluded.block_number"},
+		}},
+	}).Create(&nft).Error
+	if err != nil {
+		return fmt.Errorf("failed to save NFT owner: %v", err)
+	}
+
+	return nil
+}
+
+// transferKind classifies a transfer as a mint, burn or plain transfer
+func transferKind(transfer ethereum.Transfer) string {
+	switch {
+	case transfer.IsMint():
+		return models.TransferKindMint
+	case transfer.IsBurn():
+		return models.TransferKindBurn
+	default:
+		return models.TransferKindTransfer
+	}
+}
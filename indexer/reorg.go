@@ -0,0 +1,210 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reorgWindow is the number of blocks behind the checkpoint for which
+// processed block hashes are kept. Reorganizations deeper than this window
+// cause a full re-index of the contract.
+const reorgWindow = 4096
+
+// recordProcessedBlock stores the hash of a processed block and prunes hashes
+// that fell out of the reorg window
+func recordProcessedBlock(tx *gorm.DB, chainID uint64, contractAddress string, header *types.Header) error {
+	block := models.ProcessedBlock{
+		ChainID:         chainID,
+		ContractAddress: contractAddress,
+		BlockNumber:     header.Number.Uint64(),
+		BlockHash:       header.Hash().Hex(),
+		ParentHash:      header.ParentHash.Hex(),
+		CreatedAt:       time.Now(),
+	}
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain_id"}, {Name: "contract_address"}, {Name: "block_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"block_hash", "parent_hash", "created_at"}),
+	}).Create(&block).Error
+	if err != nil {
+		return fmt.Errorf("failed to save processed block: %v", err)
+	}
+
+	if block.BlockNumber > reorgWindow {
+		err = tx.Where("chain_id = ? AND contract_address = ? AND block_number < ?", chainID, contractAddress, block.BlockNumber-reorgWindow).
+			Delete(&models.ProcessedBlock{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to prune processed blocks: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// checkReorg compares the parent hash of the first block to be indexed with
+// the stored hash of the checkpoint block. On a mismatch it rolls the index
+// back to the common ancestor and returns it.
+func (ix *Indexer) checkReorg(ctx context.Context, lastBlock, nextBlock uint64) (uint64, bool, error) {
+	var stored models.ProcessedBlock
+	err := ix.scope(ix.db.WithContext(ctx)).Where("block_number = ?", lastBlock).First(&stored).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			// checkpoints written before block hashes were tracked cannot be verified
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to get processed block %d: %v", lastBlock, err)
+	}
+
+	next, err := ix.chain.HeaderByNumber(ctx, nextBlock)
+	if err != nil {
+		return 0, false, err
+	}
+	if next.ParentHash.Hex() == stored.BlockHash {
+		return 0, false, nil
+	}
+
+	log.Printf("Reorg detected for %s: parent of block %d is %s, indexed block %d was %s",
+		ix.config.ContractAddress, nextBlock, next.ParentHash.Hex(), lastBlock, stored.BlockHash)
+
+	forkBlock, found, err := ix.findForkPoint(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+
+	if err := ix.rollback(ctx, forkBlock, found); err != nil {
+		return 0, false, err
+	}
+
+	return forkBlock, true, nil
+}
+
+// findForkPoint walks the stored block hashes backwards and returns the
+// newest block that is still part of the canonical chain. If none is, the
+// whole index has to be rebuilt and found is false.
+func (ix *Indexer) findForkPoint(ctx context.Context) (uint64, bool, error) {
+	var blocks []models.ProcessedBlock
+	err := ix.scope(ix.db.WithContext(ctx)).Order("block_number DESC").Find(&blocks).Error
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get processed blocks: %v", err)
+	}
+
+	for _, block := range blocks {
+		header, err := ix.chain.HeaderByNumber(ctx, block.BlockNumber)
+		if err != nil {
+			return 0, false, err
+		}
+		if header.Hash().Hex() == block.BlockHash {
+			return block.BlockNumber, true, nil
+		}
+	}
+
+	if ix.config.StartBlock == 0 {
+		return 0, false, nil
+	}
+	return ix.config.StartBlock - 1, false, nil
+}
+
+// rollback removes every change the indexer derived from blocks after
+// forkBlock: their transfer events and the ownership history entries derived
+// from them are deleted, NFTs whose current owner came from one of those
+// events are reset to their last remaining owner and the checkpoint is moved
+// back. Ownership history entries and owners recorded by ownerOf reads are
+// left alone. If found is false the contract is re-indexed from scratch.
+func (ix *Indexer) rollback(ctx context.Context, forkBlock uint64, found bool) error {
+	chainID := ix.chain.ChainID()
+	contractAddress := ix.config.ContractAddress
+
+	return ix.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		orphaned := func() *gorm.DB {
+			query := ix.scope(tx)
+			if found {
+				query = query.Where("block_number > ?", forkBlock)
+			}
+			return query
+		}
+
+		// NFTs whose owner and block match an orphaned transfer got their
+		// state from the indexer; newer ownerOf reads are kept
+		orphanedEvents := tx.Table("transfer_events AS e").Select("1").
+			Where("e.chain_id = nfts.chain_id AND e.contract_address = nfts.contract_address AND e.token_id = nfts.token_id").
+			Where("e.block_number = nfts.block_number AND e.to_address = nfts.owner")
+		if found {
+			orphanedEvents = orphanedEvents.Where("e.block_number > ?", forkBlock)
+		}
+		var nfts []models.NFT
+		if err := ix.scope(tx).Where("EXISTS (?)", orphanedEvents).Find(&nfts).Error; err != nil {
+			return fmt.Errorf("failed to get NFTs to roll back: %v", err)
+		}
+
+		orphanedEventIDs := orphaned().Model(&models.TransferEvent{}).Select("id")
+		if err := tx.Where("transfer_event_id IN (?)", orphanedEventIDs).Delete(&models.OwnershipHistory{}).Error; err != nil {
+			return fmt.Errorf("failed to roll back ownership history: %v", err)
+		}
+		if err := orphaned().Delete(&models.TransferEvent{}).Error; err != nil {
+			return fmt.Errorf("failed to roll back transfer events: %v", err)
+		}
+
+		for i := range nfts {
+			if err := restoreOwner(tx, &nfts[i]); err != nil {
+				return err
+			}
+		}
+
+		if err := orphaned().Delete(&models.ProcessedBlock{}).Error; err != nil {
+			return fmt.Errorf("failed to roll back processed blocks: %v", err)
+		}
+
+		checkpoint := ix.scope(tx).Model(&models.IndexerCheckpoint{})
+		var err error
+		if found {
+			err = checkpoint.Updates(map[string]interface{}{"last_block": forkBlock, "updated_at": time.Now()}).Error
+		} else {
+			err = checkpoint.Delete(&models.IndexerCheckpoint{}).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to roll back indexer checkpoint: %v", err)
+		}
+
+		log.Printf("Rolled back %s on chain %d to block %d (%d NFT(s) restored)", contractAddress, chainID, forkBlock, len(nfts))
+		return nil
+	})
+}
+
+// restoreOwner resets an NFT to the newest ownership history entry left after
+// a rollback. If no entry is left, everything known about the NFT came from
+// orphaned blocks and it is deleted.
+func restoreOwner(tx *gorm.DB, nft *models.NFT) error {
+	var entry models.OwnershipHistory
+	err := tx.Where("chain_id = ? AND contract_address = ? AND token_id = ?", nft.ChainID, nft.ContractAddress, nft.TokenID).
+		Order("block_number DESC, id DESC").
+		First(&entry).Error
+	if err == gorm.ErrRecordNotFound {
+		if err := tx.Delete(nft).Error; err != nil {
+			return fmt.Errorf("failed to delete rolled back NFT: %v", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get ownership history: %v", err)
+	}
+
+	nft.Owner = entry.NewOwner
+	nft.BlockNumber = entry.BlockNumber
+	nft.UpdatedAt = time.Now()
+	if err := tx.Save(nft).Error; err != nil {
+		return fmt.Errorf("failed to restore NFT owner: %v", err)
+	}
+	return nil
+}
+
+// scope restricts a query to the rows of the indexed contract
+func (ix *Indexer) scope(db *gorm.DB) *gorm.DB {
+	return db.Where("chain_id = ? AND contract_address = ?", ix.chain.ChainID(), ix.config.ContractAddress)
+}
@@ -0,0 +1,376 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const testChainID = 1337
+
+var (
+	testContract = common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	alice        = common.HexToAddress("0x000000000000000000000000000000000000a11c")
+	bob          = common.HexToAddress("0x0000000000000000000000000000000000000b0b")
+	carol        = common.HexToAddress("0x00000000000000000000000000000000000ca401")
+	dave         = common.HexToAddress("0x0000000000000000000000000000000000000da5")
+)
+
+// simulatedChain is an in-memory chain of headers and Transfer logs that can
+// be forked to simulate reorganizations
+type simulatedChain struct {
+	headers   []*types.Header
+	transfers map[common.Hash][]ethereum.Transfer
+	forks     int
+}
+
+// newSimulatedChain creates a chain that only contains the genesis block
+func newSimulatedChain() *simulatedChain {
+	c := &simulatedChain{transfers: map[common.Hash][]ethereum.Transfer{}}
+	c.headers = append(c.headers, &types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(0), Time: 1700000000})
+	return c
+}
+
+// mine appends a block with the given transfers of token IDs to the chain
+func (c *simulatedChain) mine(transfers ...ethereum.Transfer) *types.Header {
+	parent := c.headers[len(c.headers)-1]
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, big.NewInt(1)),
+		Difficulty: big.NewInt(0),
+		Time:       parent.Time + 12,
+		Extra:      []byte(fmt.Sprintf("fork %d", c.forks)),
+	}
+	c.headers = append(c.headers, header)
+
+	for i := range transfers {
+		transfers[i].BlockNumber = header.Number.Uint64()
+		transfers[i].BlockHash = header.Hash()
+		transfers[i].TxHash = common.BigToHash(big.NewInt(int64(i + 1)))
+		transfers[i].LogIndex = uint(i)
+	}
+	c.transfers[header.Hash()] = transfers
+	return header
+}
+
+// fork drops every block after number so that the following blocks form a
+// competing branch with different hashes
+func (c *simulatedChain) fork(number uint64) {
+	c.headers = c.headers[:number+1]
+	c.forks++
+}
+
+func (c *simulatedChain) ChainID() uint64 {
+	return testChainID
+}
+
+func (c *simulatedChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return uint64(len(c.headers) - 1), nil
+}
+
+func (c *simulatedChain) HeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
+	if number >= uint64(len(c.headers)) {
+		return nil, fmt.Errorf("block %d not found", number)
+	}
+	return c.headers[number], nil
+}
+
+func (c *simulatedChain) FilterTransfers(ctx context.Context, contractAddress string, fromBlock, toBlock uint64) ([]ethereum.Transfer, error) {
+	var transfers []ethereum.Transfer
+	for number := fromBlock; number <= toBlock && number < uint64(len(c.headers)); number++ {
+		transfers = append(transfers, c.transfers[c.headers[number].Hash()]...)
+	}
+	return transfers, nil
+}
+
+// transfer creates a Transfer of tokenID from from to to
+func transfer(from, to common.Address, tokenID int64) ethereum.Transfer {
+	return ethereum.Transfer{From: from, To: to, TokenID: big.NewInt(tokenID)}
+}
+
+// setupDB opens a fresh SQLite database
+func setupDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "indexer.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	err = db.AutoMigrate(
+		&models.NFT{},
+		&models.OwnershipHistory{},
+		&models.TransferEvent{},
+		&models.IndexerCheckpoint{},
+		&models.ProcessedBlock{},
+	)
+	if err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// newTestIndexer creates an indexer of testContract that records every block
+func newTestIndexer(t *testing.T, db *gorm.DB, chain ChainReader) *Indexer {
+	t.Helper()
+
+	ix, err := NewIndexer(db, chain, Config{
+		ContractAddress: testContract.Hex(),
+		StartBlock:      1,
+		BatchSize:       1,
+	})
+	if err != nil {
+		t.Fatalf("NewIndexer() error: %v", err)
+	}
+	return ix
+}
+
+// assertOwner checks the stored owner and block of a token
+func assertOwner(t *testing.T, db *gorm.DB, tokenID uint64, owner common.Address, block uint64) {
+	t.Helper()
+
+	var nft models.NFT
+	err := db.Where("chain_id = ? AND contract_address = ? AND token_id = ?", testChainID, testContract.Hex(), models.TokenIDFromUint64(tokenID)).
+		First(&nft).Error
+	if err != nil {
+		t.Fatalf("failed to get NFT %d: %v", tokenID, err)
+	}
+	if nft.Owner != owner.Hex() || nft.BlockNumber != block {
+		t.Errorf("NFT %d owner = %s at block %d, want %s at block %d", tokenID, nft.Owner, nft.BlockNumber, owner.Hex(), block)
+	}
+}
+
+// assertCheckpoint checks the last indexed block
+func assertCheckpoint(t *testing.T, ix *Indexer, want uint64) {
+	t.Helper()
+
+	lastBlock, ok, err := ix.Checkpoint(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get checkpoint: %v", err)
+	}
+	if !ok || lastBlock != want {
+		t.Errorf("checkpoint = %d (exists %v), want %d", lastBlock, ok, want)
+	}
+}
+
+// historyOwners returns the new owners recorded for a token, oldest first
+func historyOwners(t *testing.T, db *gorm.DB, tokenID uint64) []string {
+	t.Helper()
+
+	var owners []string
+	err := db.Model(&models.OwnershipHistory{}).
+		Where("chain_id = ? AND contract_address = ? AND token_id = ?", testChainID, testContract.Hex(), models.TokenIDFromUint64(tokenID)).
+		Order("block_number, id").
+		Pluck("new_owner", &owners).Error
+	if err != nil {
+		t.Fatalf("failed to get ownership history: %v", err)
+	}
+	return owners
+}
+
+func assertHistory(t *testing.T, db *gorm.DB, tokenID uint64, want ...common.Address) {
+	t.Helper()
+
+	got := historyOwners(t, db, tokenID)
+	if len(got) != len(want) {
+		t.Fatalf("history of NFT %d = %v, want %d entries", tokenID, got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i].Hex() {
+			t.Errorf("history of NFT %d = %v, want %s at %d", tokenID, got, want[i].Hex(), i)
+		}
+	}
+}
+
+func TestSyncUsesBlockTime(t *testing.T) {
+	db := setupDB(t)
+	chain := newSimulatedChain()
+	chain.mine(transfer(common.Address{}, alice, 1))
+	mined := chain.mine(transfer(alice, bob, 1))
+
+	ix := newTestIndexer(t, db, chain)
+	stats, err := ix.Sync(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+	if stats.Transfers != 2 || stats.Mints != 1 {
+		t.Errorf("Sync() stats = %+v, want 2 transfers and 1 mint", stats)
+	}
+
+	assertOwner(t, db, 1, bob, 2)
+	assertCheckpoint(t, ix, 2)
+
+	var entry models.OwnershipHistory
+	if err := db.Where("block_number = ?", 2).First(&entry).Error; err != nil {
+		t.Fatalf("failed to get ownership history: %v", err)
+	}
+	if want := time.Unix(int64(mined.Time), 0); !entry.ObservedAt.Equal(want) {
+		t.Errorf("ObservedAt = %s, want block time %s", entry.ObservedAt, want)
+	}
+	if entry.TransferEventID == nil {
+		t.Errorf("TransferEventID = nil, want the indexed event")
+	}
+}
+
+func TestReorgRollsBackAndReapplies(t *testing.T) {
+	db := setupDB(t)
+	chain := newSimulatedChain()
+	chain.mine(transfer(common.Address{}, alice, 1))
+	chain.mine(transfer(alice, bob, 1))
+	chain.mine(transfer(bob, carol, 1), transfer(common.Address{}, carol, 2))
+
+	ix := newTestIndexer(t, db, chain)
+	if _, err := ix.Sync(context.Background(), 3); err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+	assertOwner(t, db, 1, carol, 3)
+	assertOwner(t, db, 2, carol, 3)
+
+	// blocks 2 and 3 are replaced by a longer branch
+	chain.fork(1)
+	chain.mine(transfer(alice, dave, 1))
+	chain.mine()
+	chain.mine()
+
+	stats, err := ix.Sync(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+	if stats.ReorgBlock == nil || *stats.ReorgBlock != 1 {
+		t.Fatalf("Sync() reorg block = %v, want 1", stats.ReorgBlock)
+	}
+	if stats.FromBlock != 2 || stats.Transfers != 1 {
+		t.Errorf("Sync() stats = %+v, want 1 transfer from block 2", stats)
+	}
+
+	assertOwner(t, db, 1, dave, 2)
+	assertHistory(t, db, 1, alice, dave)
+	assertCheckpoint(t, ix, 4)
+
+	// token 2 was only minted on the orphaned branch
+	var count int64
+	db.Model(&models.NFT{}).Where("token_id = ?", models.TokenIDFromUint64(2)).Count(&count)
+	if count != 0 {
+		t.Errorf("NFT 2 minted in an orphaned block still stored")
+	}
+	db.Model(&models.TransferEvent{}).Count(&count)
+	if count != 2 {
+		t.Errorf("%d transfer events stored, want 2", count)
+	}
+
+	var processed models.ProcessedBlock
+	if err := db.Where("block_number = ?", 3).First(&processed).Error; err != nil {
+		t.Fatalf("failed to get processed block: %v", err)
+	}
+	if processed.BlockHash != chain.headers[3].Hash().Hex() {
+		t.Errorf("processed block 3 = %s, want canonical %s", processed.BlockHash, chain.headers[3].Hash().Hex())
+	}
+}
+
+func TestReorgKeepsOwnerOfReads(t *testing.T) {
+	db := setupDB(t)
+	chain := newSimulatedChain()
+	chain.mine(transfer(common.Address{}, alice, 1))
+	chain.mine(transfer(alice, bob, 1))
+
+	ix := newTestIndexer(t, db, chain)
+	if _, err := ix.Sync(context.Background(), 2); err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+
+	// an ownerOf read of another token at a later block, as written by
+	// "owner get" at latest
+	now := time.Now()
+	read := models.NFT{
+		ChainID:         testChainID,
+		ContractAddress: testContract.Hex(),
+		TokenID:         models.TokenIDFromUint64(7),
+		Owner:           carol.Hex(),
+		BlockNumber:     5,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := db.Create(&read).Error; err != nil {
+		t.Fatalf("failed to store NFT: %v", err)
+	}
+	err := db.Create(&models.OwnershipHistory{
+		ChainID:         testChainID,
+		ContractAddress: testContract.Hex(),
+		TokenID:         read.TokenID,
+		NewOwner:        carol.Hex(),
+		BlockNumber:     5,
+		ObservedAt:      now,
+	}).Error
+	if err != nil {
+		t.Fatalf("failed to store ownership history: %v", err)
+	}
+
+	chain.fork(1)
+	chain.mine(transfer(alice, dave, 1))
+	chain.mine()
+
+	stats, err := ix.Sync(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+	if stats.ReorgBlock == nil || *stats.ReorgBlock != 1 {
+		t.Fatalf("Sync() reorg block = %v, want 1", stats.ReorgBlock)
+	}
+
+	assertOwner(t, db, 1, dave, 2)
+	assertOwner(t, db, 7, carol, 5)
+	assertHistory(t, db, 7, carol)
+}
+
+func TestReorgWithoutCommonAncestorReindexes(t *testing.T) {
+	db := setupDB(t)
+	chain := newSimulatedChain()
+	chain.mine(transfer(common.Address{}, alice, 1))
+	chain.mine(transfer(alice, bob, 1))
+
+	ix := newTestIndexer(t, db, chain)
+	if _, err := ix.Sync(context.Background(), 2); err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+
+	// every indexed block is orphaned
+	chain.fork(0)
+	chain.mine(transfer(common.Address{}, carol, 1))
+	chain.mine()
+	chain.mine()
+
+	stats, err := ix.Sync(context.Background(), 3)
+	if err != nil {
+		t.Fatalf("Sync() error: %v", err)
+	}
+	if stats.ReorgBlock == nil || *stats.ReorgBlock != 0 {
+		t.Fatalf("Sync() reorg block = %v, want 0", stats.ReorgBlock)
+	}
+	if stats.FromBlock != 1 {
+		t.Errorf("Sync() from block = %d, want start block 1", stats.FromBlock)
+	}
+
+	assertOwner(t, db, 1, carol, 1)
+	assertHistory(t, db, 1, carol)
+	assertCheckpoint(t, ix, 3)
+}
@@ -0,0 +1,310 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gorm.io/gorm"
+)
+
+// Defaults of the watcher
+const (
+	DefaultMinBackoff   = time.Second
+	DefaultMaxBackoff   = time.Minute
+	DefaultPollInterval = 15 * time.Second
+)
+
+// WatcherConfig configures a watcher
+type WatcherConfig struct {
+	// RPCURL is the websocket (or IPC) endpoint used for the subscription
+	RPCURL string
+	// ChainID is the chain the endpoint must serve. Zero accepts any chain.
+	ChainID uint64
+	// RPCTimeout bounds every request to the endpoint, zero meaning
+	// ethereum.DefaultRPCTimeout
+	RPCTimeout time.Duration
+	// RetryPolicy controls how failed requests are retried, the zero value
+	// meaning ethereum.DefaultRetryPolicy
+	RetryPolicy ethereum.RetryPolicy
+	// RateLimit limits the requests sent to the endpoint, the zero value
+	// meaning no limit
+	RateLimit ethereum.RateLimit
+	// Contracts are the contracts to follow. If empty, every contract with
+	// stored NFTs or an indexer checkpoint on the chain is followed.
+	Contracts []string
+	// StartBlock, BatchSize and Confirmations configure the indexer of every
+	// contract, see Config
+	StartBlock    uint64
+	BatchSize     uint64
+	Confirmations uint64
+	// PollInterval is how often the watcher syncs even without new logs, so
+	// that blocks reaching the confirmation depth get indexed
+	PollInterval time.Duration
+	// MinBackoff and MaxBackoff bound the delay between reconnect attempts
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// Watcher follows Transfer events of tracked contracts over an eth_subscribe
+// subscription and keeps the database current. Incoming logs trigger a sync of
+// their contract up to the confirmed head, so every write goes through the
+// reorg checks of the indexer. After every (re)connect it backfills the
+// blocks missed since the last checkpoint.
+type Watcher struct {
+	db     *gorm.DB
+	config WatcherConfig
+}
+
+// NewWatcher creates a new watcher storing the followed transfers in db
+func NewWatcher(db *gorm.DB, config WatcherConfig) *Watcher {
+	if config.MinBackoff == 0 {
+		config.MinBackoff = DefaultMinBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.PollInterval == 0 {
+		config.PollInterval = DefaultPollInterval
+	}
+
+	return &Watcher{
+		db:     db,
+		config: config,
+	}
+}
+
+// fatalError marks errors that reconnecting cannot fix, such as an endpoint
+// without subscription support
+type fatalError struct {
+	err error
+}
+
+func (e *fatalError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fatalError) Unwrap() error {
+	return e.err
+}
+
+// Run follows the tracked contracts until ctx is cancelled, reconnecting with
+// exponential backoff whenever the connection or subscription drops. Errors
+// that a reconnect cannot fix, such as an HTTP endpoint, are returned.
+func (w *Watcher) Run(ctx context.Context) error {
+	if err := checkSubscriptionURL(w.config.RPCURL); err != nil {
+		return err
+	}
+
+	backoff := w.config.MinBackoff
+
+	for {
+		connected, err := w.runOnce(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		var fatal *fatalError
+		if errors.As(err, &fatal) {
+			return fatal.err
+		}
+
+		if connected {
+			backoff = w.config.MinBackoff
+		}
+		log.Printf("Watch connection lost: %v; reconnecting in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > w.config.MaxBackoff {
+			backoff = w.config.MaxBackoff
+		}
+	}
+}
+
+// runOnce connects, subscribes, backfills and then syncs on every incoming log
+// and poll tick until the subscription fails. It reports whether the subscription was
+// established so that the caller can reset its backoff.
+func (w *Watcher) runOnce(ctx context.Context) (bool, error) {
+	ethClient, err := ethereum.NewEthereumClient(ctx, w.config.RPCURL)
+	if err != nil {
+		return false, err
+	}
+	defer ethClient.Close()
+	ethClient.SetRPCTimeout(w.config.RPCTimeout)
+	if w.config.RetryPolicy != (ethereum.RetryPolicy{}) {
+		ethClient.SetRetryPolicy(w.config.RetryPolicy)
+	}
+	ethClient.SetRateLimit(w.config.RateLimit)
+	if w.config.ChainID != 0 && ethClient.ChainID() != w.config.ChainID {
+		return false, &fatalError{err: fmt.Errorf("%w: %s serves chain %d, expected %d", ethereum.ErrChainMismatch, w.config.RPCURL, ethClient.ChainID(), w.config.ChainID)}
+	}
+
+	contracts := w.config.Contracts
+	if len(contracts) == 0 {
+		contracts, err = TrackedContracts(ctx, w.db, ethClient.ChainID())
+		if err != nil {
+			return false, err
+		}
+		if len(contracts) == 0 {
+			return false, fmt.Errorf("no tracked contracts on chain %d", ethClient.ChainID())
+		}
+	}
+
+	indexers := make(map[common.Address]*Indexer, len(contracts))
+	for _, contract := range contracts {
+		ix, err := NewIndexer(w.db, ethClient, Config{
+			ContractAddress: contract,
+			StartBlock:      w.config.StartBlock,
+			BatchSize:       w.config.BatchSize,
+			Confirmations:   w.config.Confirmations,
+		})
+		if err != nil {
+			return false, &fatalError{err: err}
+		}
+		indexers[common.HexToAddress(ix.ContractAddress())] = ix
+
+		// backfilling a contract from genesis is almost never intended
+		if w.config.StartBlock == 0 {
+			_, ok, err := ix.Checkpoint(ctx)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				return false, &fatalError{err: fmt.Errorf("%s has no indexer checkpoint: pass -start-block (e.g. the deployment block) or run index for it first", ix.ContractAddress())}
+			}
+		}
+	}
+
+	// Subscribe before backfilling so that no block falls between the two.
+	// Logs for already backfilled blocks only trigger a no-op sync.
+	logs := make(chan types.Log, 256)
+	sub, err := ethClient.SubscribeTransfers(ctx, contracts, logs)
+	if err != nil {
+		if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+			return false, &fatalError{err: fmt.Errorf("%s does not support subscriptions: use a websocket or IPC endpoint: %v", w.config.RPCURL, err)}
+		}
+		return false, err
+	}
+	defer sub.Unsubscribe()
+
+	log.Printf("Watching Transfer events of %d contract(s) on chain %d", len(indexers), ethClient.ChainID())
+
+	for _, ix := range indexers {
+		if err := syncContract(ctx, ix); err != nil {
+			return true, err
+		}
+	}
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case err := <-sub.Err():
+			return true, fmt.Errorf("subscription failed: %v", err)
+		case <-ticker.C:
+			for _, ix := range indexers {
+				if err := syncContract(ctx, ix); err != nil {
+					return true, err
+				}
+			}
+		case entry := <-logs:
+			ix, ok := indexers[entry.Address]
+			if !ok {
+				continue
+			}
+
+			if entry.Removed {
+				log.Printf("Transfer log %d of tx %s of %s was removed by a reorg", entry.Index, entry.TxHash.Hex(), ix.ContractAddress())
+			}
+
+			if err := syncContract(ctx, ix); err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+// checkSubscriptionURL rejects HTTP endpoints, which cannot deliver
+// eth_subscribe notifications
+func checkSubscriptionURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid RPC URL %q: %v", rawURL, err)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https":
+		return fmt.Errorf("watch needs a websocket or IPC endpoint for eth_subscribe, got %s (set -ws or ETH_WS_URL)", parsed.Redacted())
+	}
+	return nil
+}
+
+// syncContract indexes a contract up to the confirmed head and logs the result
+func syncContract(ctx context.Context, ix *Indexer) error {
+	stats, err := ix.SyncToHead(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sync %s: %v", ix.ContractAddress(), err)
+	}
+
+	if stats.ReorgBlock != nil {
+		log.Printf("Rolled back %s to block %d after a reorg", ix.ContractAddress(), *stats.ReorgBlock)
+	}
+	if stats.Transfers > 0 {
+		log.Printf("Synced %d transfer(s) of %s in blocks %d-%d", stats.Transfers, ix.ContractAddress(), stats.FromBlock, stats.ToBlock)
+	}
+	return nil
+}
+
+// TrackedContracts returns every contract on the chain that has stored NFTs
+// or an indexer checkpoint in db
+func TrackedContracts(ctx context.Context, db *gorm.DB, chainID uint64) ([]string, error) {
+	db = db.WithContext(ctx)
+
+	var contracts []string
+	err := db.Model(&models.NFT{}).
+		Where("chain_id = ?", chainID).
+		Distinct().
+		Pluck("contract_address", &contracts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked contracts: %v", err)
+	}
+
+	var checkpointed []string
+	err = db.Model(&models.IndexerCheckpoint{}).
+		Where("chain_id = ?", chainID).
+		Pluck("contract_address", &checkpointed).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked contracts: %v", err)
+	}
+
+	seen := make(map[string]bool, len(contracts))
+	for _, contract := range contracts {
+		seen[contract] = true
+	}
+	for _, contract := range checkpointed {
+		if !seen[contract] {
+			contracts = append(contracts, contract)
+			seen[contract] = true
+		}
+	}
+
+	return contracts, nil
+}
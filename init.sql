@@ -2,11 +2,16 @@
 -- This file is executed when the PostgreSQL container starts for the first time
 
 -- Create the nfts table (GORM will also create it automatically, but this is for reference)
+-- An NFT is identified by (chain_id, contract_address, token_id)
 CREATE TABLE IF NOT EXISTS nfts (
-    token_id BIGINT PRIMARY KEY,
+    chain_id BIGINT NOT NULL,
+    contract_address VARCHAR(42) NOT NULL,
+    token_id NUMERIC(78,0) NOT NULL,
     owner VARCHAR(42) NOT NULL,
+    block_number BIGINT NOT NULL DEFAULT 0,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chain_id, contract_address, token_id)
 );
 
 -- Create an index on the owner field for faster queries
@@ -16,7 +21,7 @@ CREATE INDEX IF NOT EXISTS idx_nfts_owner ON nfts(owner);
 CREATE INDEX IF NOT EXISTS idx_nfts_updated_at ON nfts(updated_at);
 
 -- Insert some sample data (optional)
--- INSERT INTO nfts (token_id, owner) VALUES 
--- (1, '0x1234567890123456789012345678901234567890'),
--- (2, '0x0987654321098765432109876543210987654321')
--- ON CONFLICT (token_id) DO NOTHING;
+-- INSERT INTO nfts (chain_id, contract_address, token_id, owner) VALUES
+-- (1, '0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D', 1, '0x1234567890123456789012345678901234567890'),
+-- (1, '0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D', 2, '0x0987654321098765432109876543210987654321')
+-- ON CONFLICT (chain_id, contract_address, token_id) DO NOTHING;
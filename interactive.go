@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go-cli-eth/database"
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+	"go-cli-eth/services"
+)
+
+// runInteractive runs the numbered menu that prompts for every input. RPC
+// requests and database statements are bounded by RPC_TIMEOUT and DB_TIMEOUT
+// so that an unresponsive node or database returns to the menu, and RPC
+// requests are retried and rate limited according to RPC_RETRIES and
+// RPC_RATE_LIMIT; SIGINT ends the menu.
+func runInteractive(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		return usageErrorf("interactive takes no arguments")
+	}
+
+	fmt.Println("🚀 Go CLI Ethereum NFT Tracker")
+	fmt.Println("===============================")
+
+	console := newConsole(ctx, os.Stdin)
+
+	// Initialize database connection
+	dbConnectionString, ok := console.prompt("Enter PostgreSQL connection string (or press Enter to use DATABASE_URL env var): ")
+	if !ok {
+		return nil
+	}
+
+	repo, err := database.InitDB(ctx, dbConnectionString, envDuration("DB_TIMEOUT", database.DefaultQueryTimeout))
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %v", err)
+	}
+	defer repo.Close()
+
+	// Initialize Ethereum client
+	rpcURL, ok := console.prompt("Enter Ethereum RPC URL (or press Enter for default): ")
+	if !ok {
+		return nil
+	}
+
+	if rpcURL == "" {
+		rpcURL = defaultRPCURL
+		fmt.Printf("Using default RPC URL: %s\n", rpcURL)
+	}
+
+	ethClient, err := ethereum.NewEthereumClient(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Ethereum client: %v", err)
+	}
+	defer ethClient.Close()
+	ethClient.SetRPCTimeout(envDuration("RPC_TIMEOUT", ethereum.DefaultRPCTimeout))
+	retryPolicy := ethereum.DefaultRetryPolicy
+	retryPolicy.MaxRetries = envInt("RPC_RETRIES", retryPolicy.MaxRetries)
+	ethClient.SetRetryPolicy(retryPolicy)
+	ethClient.SetRateLimit(ethereum.RateLimit{RequestsPerSecond: envFloat("RPC_RATE_LIMIT", 0)})
+
+	// Initialize NFT service
+	nftService := services.NewNFTService(repo, ethClient)
+
+	// Main application loop
+	for {
+		fmt.Println("\n📋 Available Commands:")
+		fmt.Println("1. Get and store NFT owner")
+		fmt.Println("2. Update NFT owner")
+		fmt.Println("3. Get NFT by Token ID")
+		fmt.Println("4. List all NFTs")
+		fmt.Println("5. Exit")
+
+		choice, ok := console.prompt("\nSelect an option (1-5): ")
+		if !ok {
+			fmt.Println("\n👋 Goodbye!")
+			return nil
+		}
+
+		switch choice {
+		case "1":
+			handleGetAndStoreOwner(ctx, nftService, console)
+		case "2":
+			handleUpdateOwner(ctx, nftService, console)
+		case "3":
+			handleGetNFT(ctx, nftService, console)
+		case "4":
+			handleListAllNFTs(ctx, nftService, console)
+		case "5":
+			fmt.Println("👋 Goodbye!")
+			return nil
+		default:
+			fmt.Println("❌ Invalid option. Please select 1-5.")
+		}
+	}
+}
+
+// readContractAddress prompts for a contract address or ENS name and returns
+// the checksummed address or the normalized name, which the service resolves.
+// It reports false after printing the problem if the input is neither, or is
+// empty and optional is false. Addresses with a wrong EIP-55 checksum are
+// accepted with a warning.
+func readContractAddress(console *console, prompt string, optional bool) (string, bool) {
+	input, ok := console.prompt(prompt)
+	if !ok {
+		return "", false
+	}
+
+	if input == "" && optional {
+		return "", true
+	}
+	if ethereum.IsENSName(input) {
+		name, _ := ethereum.NormalizeENSName(input)
+		return name, true
+	}
+
+	address, err := ethereum.ParseAddress(input)
+	if err != nil {
+		fmt.Printf("❌ Invalid contract address: %v\n", err)
+		return "", false
+	}
+	if !ethereum.HasValidChecksum(input) {
+		fmt.Printf("⚠️  %s does not match its EIP-55 checksum, using %s\n", input, address.Hex())
+	}
+	return address.Hex(), true
+}
+
+func handleGetAndStoreOwner(ctx context.Context, nftService *services.NFTService, console *console) {
+	contractAddress, ok := readContractAddress(console, "Enter contract address or ENS name: ", false)
+	if !ok {
+		return
+	}
+
+	tokenIDStr, ok := console.prompt("Enter token ID (decimal or 0x hex): ")
+	if !ok {
+		return
+	}
+
+	tokenID, err := models.ParseTokenID(tokenIDStr)
+	if err != nil {
+		fmt.Printf("❌ Invalid token ID: %v\n", err)
+		return
+	}
+
+	blockStr, ok := console.prompt("Enter block (number, hash, safe, finalized) or press Enter for latest: ")
+	if !ok {
+		return
+	}
+
+	block, err := ethereum.ParseBlockRef(blockStr)
+	if err != nil {
+		fmt.Printf("❌ Invalid block: %v\n", err)
+		return
+	}
+
+	nft, err := nftService.GetAndStoreOwner(ctx, contractAddress, tokenID, block)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Success! NFT Details:\n")
+	fmt.Printf("   Chain ID: %d\n", nft.ChainID)
+	fmt.Printf("   Contract: %s\n", nft.ContractAddress)
+	fmt.Printf("   Token ID: %s\n", nft.TokenID)
+	fmt.Printf("   Owner: %s\n", nft.Owner)
+	if nft.OwnerENS != "" {
+		fmt.Printf("   Owner ENS: %s\n", nft.OwnerENS)
+	}
+	fmt.Printf("   Block: %d\n", nft.BlockNumber)
+	fmt.Printf("   Created: %s\n", nft.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   Updated: %s\n", nft.UpdatedAt.Format("2006-01-02 15:04:05"))
+}
+
+func handleUpdateOwner(ctx context.Context, nftService *services.NFTService, console *console) {
+	contractAddress, ok := readContractAddress(console, "Enter contract address or ENS name: ", false)
+	if !ok {
+		return
+	}
+
+	tokenIDStr, ok := console.prompt("Enter token ID (decimal or 0x hex): ")
+	if !ok {
+		return
+	}
+
+	tokenID, err := models.ParseTokenID(tokenIDStr)
+	if err != nil {
+		fmt.Printf("❌ Invalid token ID: %v\n", err)
+		return
+	}
+
+	blockStr, ok := console.prompt("Enter block (number, hash, safe, finalized) or press Enter for latest: ")
+	if !ok {
+		return
+	}
+
+	block, err := ethereum.ParseBlockRef(blockStr)
+	if err != nil {
+		fmt.Printf("❌ Invalid block: %v\n", err)
+		return
+	}
+
+	nft, err := nftService.UpdateOwner(ctx, contractAddress, tokenID, block)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("✅ Updated! NFT Details:\n")
+	fmt.Printf("   Chain ID: %d\n", nft.ChainID)
+	fmt.Printf("   Contract: %s\n", nft.ContractAddress)
+	fmt.Printf("   Token ID: %s\n", nft.TokenID)
+	fmt.Printf("   Owner: %s\n", nft.Owner)
+	if nft.OwnerENS != "" {
+		fmt.Printf("   Owner ENS: %s\n", nft.OwnerENS)
+	}
+	fmt.Printf("   Block: %d\n", nft.BlockNumber)
+	fmt.Printf("   Created: %s\n", nft.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   Updated: %s\n", nft.UpdatedAt.Format("2006-01-02 15:04:05"))
+}
+
+func handleGetNFT(ctx context.Context, nftService *services.NFTService, console *console) {
+	contractAddress, ok := readContractAddress(console, "Enter contract address or ENS name: ", false)
+	if !ok {
+		return
+	}
+
+	tokenIDStr, ok := console.prompt("Enter token ID (decimal or 0x hex): ")
+	if !ok {
+		return
+	}
+
+	tokenID, err := models.ParseTokenID(tokenIDStr)
+	if err != nil {
+		fmt.Printf("❌ Invalid token ID: %v\n", err)
+		return
+	}
+
+	nft, err := nftService.GetNFTByTokenID(ctx, contractAddress, tokenID)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+
+	fmt.Printf("📄 NFT Details:\n")
+	fmt.Printf("   Chain ID: %d\n", nft.ChainID)
+	fmt.Printf("   Contract: %s\n", nft.ContractAddress)
+	fmt.Printf("   Token ID: %s\n", nft.TokenID)
+	fmt.Printf("   Owner: %s\n", nft.Owner)
+	if nft.OwnerENS != "" {
+		fmt.Printf("   Owner ENS: %s\n", nft.OwnerENS)
+	}
+	fmt.Printf("   Block: %d\n", nft.BlockNumber)
+	fmt.Printf("   Created: %s\n", nft.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   Updated: %s\n", nft.UpdatedAt.Format("2006-01-02 15:04:05"))
+}
+
+func handleListAllNFTs(ctx context.Context, nftService *services.NFTService, console *console) {
+	contractAddress, ok := readContractAddress(console, "Enter contract address (or press Enter for all contracts): ", true)
+	if !ok {
+		return
+	}
+
+	nfts, err := nftService.GetAllNFTs(ctx, contractAddress)
+	if err != nil {
+		fmt.Printf("❌ Error: %v\n", err)
+		return
+	}
+
+	if len(nfts) == 0 {
+		fmt.Println("📭 No NFTs found in database.")
+		return
+	}
+
+	fmt.Printf("📋 Found %d NFT(s):\n", len(nfts))
+	fmt.Println("===========================================")
+	for _, nft := range nfts {
+		fmt.Printf("Contract: %s | Token ID: %s | Owner: %s | Updated: %s\n",
+			nft.ContractAddress,
+			nft.TokenID,
+			nft.Owner,
+			nft.UpdatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// console reads menu input from stdin. Lines are read in the background so
+// that a prompt can be abandoned as soon as ctx is cancelled.
+type console struct {
+	ctx   context.Context
+	lines <-chan string
+}
+
+// newConsole starts reading lines from r until it is exhausted
+func newConsole(ctx context.Context, r io.Reader) *console {
+	lines := make(chan string)
+	go func() {
+		defer close(lines)
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return &console{ctx: ctx, lines: lines}
+}
+
+// prompt prints text and returns the next line of input with surrounding
+// whitespace removed. It reports false once ctx is cancelled or the input is
+// exhausted.
+func (c *console) prompt(text string) (string, bool) {
+	fmt.Print(text)
+	select {
+	case line, ok := <-c.lines:
+		return strings.TrimSpace(line), ok
+	case <-c.ctx.Done():
+		return "", false
+	}
+}
@@ -0,0 +1,118 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"go-cli-eth/models"
+)
+
+// Document holds the fields of a metadata document following the ERC-721
+// metadata JSON schema and the OpenSea attributes convention
+type Document struct {
+	Name        string
+	Description string
+	Image       string
+	Attributes  []models.Attribute
+}
+
+// rawDocument is the loosely typed form of a metadata document. Fields are
+// decoded one by one since real-world documents use numbers for names,
+// image_url for image and objects for attributes.
+type rawDocument struct {
+	Name        json.RawMessage `json:"name"`
+	Description json.RawMessage `json:"description"`
+	Image       json.RawMessage `json:"image"`
+	ImageURL    json.RawMessage `json:"image_url"`
+	Attributes  json.RawMessage `json:"attributes"`
+}
+
+// Parse extracts the name, description, image and attributes of a metadata
+// document. Missing or malformed fields are left empty; only documents that
+// are not a JSON object are rejected with an error wrapping ErrInvalidMetadata.
+func Parse(raw []byte) (*Document, error) {
+	if err := validateObject(raw); err != nil {
+		return nil, err
+	}
+
+	var fields rawDocument
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMetadata, err)
+	}
+
+	doc := &Document{
+		Name:        text(fields.Name),
+		Description: text(fields.Description),
+		Image:       text(fields.Image),
+		Attributes:  attributes(fields.Attributes),
+	}
+	if doc.Image == "" {
+		doc.Image = text(fields.ImageURL)
+	}
+	return doc, nil
+}
+
+// validateObject rejects documents that are not a single JSON object
+func validateObject(raw []byte) error {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return fmt.Errorf("%w: document is not a JSON object", ErrInvalidMetadata)
+	}
+	return nil
+}
+
+// text returns a string or number field as a string, or an empty string for
+// any other value
+func text(field json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(field, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(field, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+// attributes decodes the attributes field, which is either an array of
+// {trait_type, value, display_type} objects or an object mapping trait types
+// to values. Entries without a value are skipped.
+func attributes(field json.RawMessage) []models.Attribute {
+	var list []struct {
+		TraitType   json.RawMessage `json:"trait_type"`
+		Value       interface{}     `json:"value"`
+		DisplayType string          `json:"display_type"`
+	}
+	if err := json.Unmarshal(field, &list); err == nil {
+		attrs := make([]models.Attribute, 0, len(list))
+		for _, entry := range list {
+			if entry.Value == nil {
+				continue
+			}
+			attrs = append(attrs, models.Attribute{
+				TraitType:   text(entry.TraitType),
+				Value:       entry.Value,
+				DisplayType: entry.DisplayType,
+			})
+		}
+		return attrs
+	}
+
+	var traits map[string]interface{}
+	if err := json.Unmarshal(field, &traits); err == nil {
+		attrs := make([]models.Attribute, 0, len(traits))
+		for traitType, value := range traits {
+			if value == nil {
+				continue
+			}
+			attrs = append(attrs, models.Attribute{TraitType: traitType, Value: value})
+		}
+		sort.Slice(attrs, func(i, j int) bool { return attrs[i].TraitType < attrs[j].TraitType })
+		return attrs
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"go-cli-eth/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want Document
+	}{
+		{
+			name: "attribute array",
+			raw: `{"name": "Ape #1", "description": "An ape", "image": "ipfs://QmImage/1.png", "attributes": [
+				{"trait_type": "Fur", "value": "Brown"},
+				{"trait_type": "Level", "value": 5, "display_type": "number"},
+				{"trait_type": "Empty", "value": null}
+			]}`,
+			want: Document{
+				Name:        "Ape #1",
+				Description: "An ape",
+				Image:       "ipfs://QmImage/1.png",
+				Attributes: []models.Attribute{
+					{TraitType: "Fur", Value: "Brown"},
+					{TraitType: "Level", Value: float64(5), DisplayType: "number"},
+				},
+			},
+		},
+		{
+			name: "attribute object and image_url",
+			raw:  `{"name": 42, "image_url": "https://example.com/42.png", "attributes": {"b": true, "a": "x"}}`,
+			want: Document{
+				Name:  "42",
+				Image: "https://example.com/42.png",
+				Attributes: []models.Attribute{
+					{TraitType: "a", Value: "x"},
+					{TraitType: "b", Value: true},
+				},
+			},
+		},
+		{
+			name: "malformed fields",
+			raw:  `{"name": {"en": "x"}, "attributes": "none"}`,
+			want: Document{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.raw))
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsNonObjects(t *testing.T) {
+	for _, raw := range []string{``, `[]`, `"name"`, `{"name": `} {
+		if _, err := Parse([]byte(raw)); !errors.Is(err, ErrInvalidMetadata) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidMetadata", raw, err)
+		}
+	}
+}
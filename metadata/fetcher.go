@@ -0,0 +1,292 @@
+package metadata
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultIPFSGateway is the gateway ipfs:// URIs are fetched through by default
+	DefaultIPFSGateway = "https://ipfs.io/ipfs/"
+
+	// DefaultArweaveGateway is the gateway ar:// URIs are fetched through by default
+	DefaultArweaveGateway = "https://arweave.net/"
+
+	// DefaultTimeout bounds a single metadata request by default
+	DefaultTimeout = 15 * time.Second
+
+	// DefaultMaxSize is the default limit of a metadata document in bytes
+	DefaultMaxSize = 1 << 20
+
+	// maxRedirects bounds the redirects followed for a single document
+	maxRedirects = 5
+)
+
+var (
+	// ErrInvalidMetadata is returned when a metadata URI has an unsupported
+	// scheme or the document it points to is not a JSON object
+	ErrInvalidMetadata = errors.New("invalid metadata")
+
+	// ErrUnavailable is returned when a metadata document could not be fetched
+	ErrUnavailable = errors.New("metadata unavailable")
+
+	// errPrivateAddress is returned when a request would connect to an
+	// address that is not publicly routable
+	errPrivateAddress = errors.New("private address not allowed")
+
+	// sharedAddressSpace is the carrier-grade NAT range of RFC 6598
+	sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+)
+
+// Config configures a Fetcher. Zero values select the defaults.
+type Config struct {
+	// IPFSGateway is the URL prefix ipfs:// paths are appended to
+	IPFSGateway string
+	// ArweaveGateway is the URL prefix ar:// transaction IDs are appended to
+	ArweaveGateway string
+	// Timeout bounds a single HTTP request
+	Timeout time.Duration
+	// MaxSize is the largest document accepted, in bytes
+	MaxSize int64
+	// AllowPrivateAddresses allows requests to loopback, private and
+	// link-local addresses, e.g. a local IPFS gateway or a test server. By
+	// default they are rejected, since token URIs are chosen by whoever
+	// deployed the contract.
+	AllowPrivateAddresses bool
+	// Client is the HTTP client used for requests. Its timeout, redirect
+	// policy and dialer, including AllowPrivateAddresses, are left unchanged
+	// if set.
+	Client *http.Client
+}
+
+// Fetcher retrieves token metadata documents from https://, ipfs://, ar://
+// and data: URIs
+type Fetcher struct {
+	config Config
+	client *http.Client
+}
+
+// NewFetcher creates a metadata fetcher
+func NewFetcher(config Config) *Fetcher {
+	if config.IPFSGateway == "" {
+		config.IPFSGateway = DefaultIPFSGateway
+	}
+	if config.ArweaveGateway == "" {
+		config.ArweaveGateway = DefaultArweaveGateway
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = DefaultMaxSize
+	}
+	config.IPFSGateway = withTrailingSlash(config.IPFSGateway)
+	config.ArweaveGateway = withTrailingSlash(config.ArweaveGateway)
+
+	client := config.Client
+	if client == nil {
+		client = newClient(config)
+	}
+
+	return &Fetcher{
+		config: config,
+		client: client,
+	}
+}
+
+// newClient creates the HTTP client of a fetcher. Redirects are limited to
+// maxRedirects http(s) URLs, and unless config allows private addresses every
+// connection, including those of redirects, must go to a public address.
+// The address is checked after DNS resolution so that a public host name
+// cannot point the request at a private address.
+func newClient(config Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !config.AllowPrivateAddresses {
+		dialer := &net.Dialer{
+			Timeout:   config.Timeout,
+			KeepAlive: 30 * time.Second,
+			Control:   requirePublicAddress,
+		}
+		transport.DialContext = dialer.DialContext
+	}
+
+	return &http.Client{
+		Timeout:       config.Timeout,
+		Transport:     transport,
+		CheckRedirect: checkRedirect,
+	}
+}
+
+// checkRedirect limits the number and schemes of followed redirects
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	return nil
+}
+
+// requirePublicAddress rejects connections to addresses that are not publicly
+// routable, such as loopback, private, link-local (e.g. cloud metadata
+// services at 169.254.169.254) and carrier-grade NAT addresses
+func requirePublicAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, ip)
+	}
+	return nil
+}
+
+// ResolveURI returns the HTTP URL a metadata URI is fetched from. ipfs:// and
+// ar:// URIs are rewritten to the configured gateways, http:// and https://
+// URIs are returned unchanged.
+func (f *Fetcher) ResolveURI(uri string) (string, error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return "", fmt.Errorf("%w: unsupported URI %q", ErrInvalidMetadata, uri)
+	}
+
+	switch strings.ToLower(scheme) {
+	case "http", "https":
+		return uri, nil
+	case "ipfs":
+		// ipfs://ipfs/<cid> is a common mistake for ipfs://<cid>
+		rest = strings.TrimPrefix(rest, "ipfs/")
+		if rest == "" {
+			return "", fmt.Errorf("%w: IPFS URI %q has no CID", ErrInvalidMetadata, uri)
+		}
+		return f.config.IPFSGateway + rest, nil
+	case "ar":
+		if rest == "" {
+			return "", fmt.Errorf("%w: Arweave URI %q has no transaction ID", ErrInvalidMetadata, uri)
+		}
+		return f.config.ArweaveGateway + rest, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported URI scheme %q", ErrInvalidMetadata, scheme)
+	}
+}
+
+// Fetch retrieves the metadata document a URI points to. Documents that are
+// not a JSON object are rejected with an error wrapping ErrInvalidMetadata;
+// network failures and non-2xx responses wrap ErrUnavailable. ctx bounds the
+// request on top of the configured timeout.
+func (f *Fetcher) Fetch(ctx context.Context, uri string) ([]byte, error) {
+	var (
+		raw []byte
+		err error
+	)
+	if strings.HasPrefix(strings.ToLower(uri), "data:") {
+		raw, err = decodeDataURI(uri)
+	} else {
+		raw, err = f.get(ctx, uri)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateObject(raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// get fetches an http(s), ipfs or ar URI
+func (f *Fetcher) get(ctx context.Context, uri string) ([]byte, error) {
+	target, err := f.ResolveURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid URL %q: %v", ErrInvalidMetadata, target, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if errors.Is(err, errPrivateAddress) {
+		return nil, fmt.Errorf("%w: %s: %w", ErrInvalidMetadata, target, errPrivateAddress)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%w: GET %s returned %s", ErrUnavailable, target, resp.Status)
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, f.config.MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s: %v", ErrUnavailable, target, err)
+	}
+	if int64(len(raw)) > f.config.MaxSize {
+		return nil, fmt.Errorf("%w: document at %s is larger than %d bytes", ErrInvalidMetadata, target, f.config.MaxSize)
+	}
+	return raw, nil
+}
+
+// decodeDataURI decodes an RFC 2397 data URI such as
+// data:application/json;base64,eyJuYW1lIjoiIn0=. Non-base64 payloads may be
+// percent-encoded or plain JSON.
+func decodeDataURI(uri string) ([]byte, error) {
+	header, payload, ok := strings.Cut(uri[len("data:"):], ",")
+	if !ok {
+		return nil, fmt.Errorf("%w: data URI without payload", ErrInvalidMetadata)
+	}
+
+	params := strings.Split(header, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+	if mediaType != "" && mediaType != "application/json" && mediaType != "text/plain" {
+		return nil, fmt.Errorf("%w: unsupported data URI media type %q", ErrInvalidMetadata, mediaType)
+	}
+
+	if strings.EqualFold(params[len(params)-1], "base64") {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(payload, "="))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid base64 in data URI: %v", ErrInvalidMetadata, err)
+		}
+		return decoded, nil
+	}
+
+	// Many contracts embed JSON containing a literal % without escaping it
+	if unescaped, err := url.PathUnescape(payload); err == nil {
+		return []byte(unescaped), nil
+	}
+	return []byte(payload), nil
+}
+
+// withTrailingSlash appends a slash to a gateway URL that lacks one
+func withTrailingSlash(gateway string) string {
+	if strings.HasSuffix(gateway, "/") {
+		return gateway
+	}
+	return gateway + "/"
+}
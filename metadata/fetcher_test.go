@@ -0,0 +1,145 @@
+package metadata
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDocument = `{"name":"Token #1","image":"ipfs://QmImage/1.png"}`
+
+// newTestServer serves testDocument at /ipfs/QmDoc/1, /arweave/TxID and
+// /token/1, a redirect to /token/1 at /redirect, a redirect loop at /loop, a
+// non-object at /array and a 404 everywhere else
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	for _, path := range []string{"/ipfs/QmDoc/1", "/arweave/TxID", "/token/1"} {
+		mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(testDocument))
+		})
+	}
+	mux.HandleFunc("/array", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[1, 2, 3]`))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/token/1", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"` + strings.Repeat("a", 100) + `"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetch(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewFetcher(Config{
+		IPFSGateway:           server.URL + "/ipfs",
+		ArweaveGateway:        server.URL + "/arweave/",
+		MaxSize:               64,
+		AllowPrivateAddresses: true,
+	})
+
+	tests := []struct {
+		name    string
+		uri     string
+		wantErr error
+	}{
+		{name: "http", uri: server.URL + "/token/1"},
+		{name: "ipfs", uri: "ipfs://QmDoc/1"},
+		{name: "ipfs with ipfs path", uri: "ipfs://ipfs/QmDoc/1"},
+		{name: "arweave", uri: "ar://TxID"},
+		{name: "data base64", uri: "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(testDocument))},
+		{name: "data base64 unpadded", uri: "data:application/json;base64," + base64.RawStdEncoding.EncodeToString([]byte(testDocument))},
+		{name: "data utf8", uri: "data:application/json;utf8," + testDocument},
+		{name: "data percent-encoded", uri: "data:application/json,%7B%22name%22%3A%22Token%20%231%22%7D"},
+		{name: "redirect", uri: server.URL + "/redirect"},
+		{name: "redirect loop", uri: server.URL + "/loop", wantErr: ErrUnavailable},
+		{name: "not found", uri: server.URL + "/token/2", wantErr: ErrUnavailable},
+		{name: "not an object", uri: server.URL + "/array", wantErr: ErrInvalidMetadata},
+		{name: "too large", uri: server.URL + "/large", wantErr: ErrInvalidMetadata},
+		{name: "unsupported scheme", uri: "ftp://example.com/1.json", wantErr: ErrInvalidMetadata},
+		{name: "no scheme", uri: "QmDoc/1", wantErr: ErrInvalidMetadata},
+		{name: "data image", uri: "data:image/svg+xml;base64,PHN2Zy8+", wantErr: ErrInvalidMetadata},
+		{name: "data invalid base64", uri: "data:application/json;base64,!!!", wantErr: ErrInvalidMetadata},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := fetcher.Fetch(context.Background(), tt.uri)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Fetch(%q) error = %v, want %v", tt.uri, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch(%q) error: %v", tt.uri, err)
+			}
+
+			doc, err := Parse(raw)
+			if err != nil {
+				t.Fatalf("Parse error: %v", err)
+			}
+			if doc.Name != "Token #1" {
+				t.Errorf("name = %q, want %q", doc.Name, "Token #1")
+			}
+		})
+	}
+}
+
+func TestFetchPrivateAddress(t *testing.T) {
+	server := newTestServer(t)
+	fetcher := NewFetcher(Config{Timeout: time.Second})
+
+	// token URIs must not reach the host itself or cloud metadata services
+	for _, uri := range []string{
+		server.URL + "/token/1",
+		strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/token/1",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/token/1",
+		"http://[::1]/token/1",
+	} {
+		_, err := fetcher.Fetch(context.Background(), uri)
+		if !errors.Is(err, ErrInvalidMetadata) || !errors.Is(err, errPrivateAddress) {
+			t.Errorf("Fetch(%q) error = %v, want a private address error", uri, err)
+		}
+	}
+}
+
+func TestResolveURI(t *testing.T) {
+	fetcher := NewFetcher(Config{})
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "https://example.com/1.json", want: "https://example.com/1.json"},
+		{uri: "ipfs://QmDoc/1", want: DefaultIPFSGateway + "QmDoc/1"},
+		{uri: "ipfs://ipfs/QmDoc/1", want: DefaultIPFSGateway + "QmDoc/1"},
+		{uri: "ar://TxID", want: DefaultArweaveGateway + "TxID"},
+	}
+
+	for _, tt := range tests {
+		got, err := fetcher.ResolveURI(tt.uri)
+		if err != nil {
+			t.Fatalf("ResolveURI(%q) error: %v", tt.uri, err)
+		}
+		if got != tt.want {
+			t.Errorf("ResolveURI(%q) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// Amount is an unsigned 256-bit quantity such as an ERC-1155 balance. Like
+// TokenID it is stored as NUMERIC(78,0) and encoded as a decimal string in
+// JSON.
+type Amount struct {
+	TokenID
+}
+
+// NewAmount creates an amount from a big integer
+func NewAmount(v *big.Int) (Amount, error) {
+	value, err := NewTokenID(v)
+	if err != nil {
+		return Amount{}, fmt.Errorf("amount %v is out of uint256 range", v)
+	}
+	return Amount{TokenID: value}, nil
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+	return a.value.Sign() == 0
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+func TestNewAmount(t *testing.T) {
+	max, _ := new(big.Int).SetString(maxUint256, 10)
+
+	amount, err := NewAmount(max)
+	if err != nil {
+		t.Fatalf("NewAmount(max uint256) error: %v", err)
+	}
+	if amount.IsZero() {
+		t.Error("max uint256 amount reported as zero")
+	}
+	if _, err := NewAmount(new(big.Int).Add(max, big.NewInt(1))); err == nil {
+		t.Error("NewAmount(max uint256 + 1) succeeded, want error")
+	}
+	if _, err := NewAmount(big.NewInt(-1)); err == nil {
+		t.Error("NewAmount(-1) succeeded, want error")
+	}
+
+	zero, err := NewAmount(new(big.Int))
+	if err != nil {
+		t.Fatalf("NewAmount(0) error: %v", err)
+	}
+	if !zero.IsZero() {
+		t.Errorf("NewAmount(0) = %s, want zero", zero)
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	amount, err := NewAmount(big.NewInt(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"1000"` {
+		t.Errorf("Marshal = %s, want \"1000\"", data)
+	}
+
+	var decoded Amount
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.String() != "1000" {
+		t.Errorf("Unmarshal = %s, want 1000", decoded)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Collection is an NFT contract registered for tracking together with its
+// collection-level data. TotalSupply is only known for ERC-721 Enumerable
+// contracts. RoyaltyReceiver and RoyaltyBasisPoints are the ERC-2981 royalty
+// most tracked tokens share, set when the royalties of the collection are
+// refreshed. TokenCount is the number of tokens of the collection currently
+// tracked and is computed, not stored.
+type Collection struct {
+	ChainID            uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	Address            string    `gorm:"primaryKey;type:varchar(42)" json:"address"`
+	Standard           string    `gorm:"type:varchar(16);not null" json:"standard"`
+	Name               string    `gorm:"type:text" json:"name"`
+	Symbol             string    `gorm:"type:text" json:"symbol"`
+	TotalSupply        *Amount   `json:"total_supply,omitempty"`
+	ContractURI        string    `gorm:"type:text" json:"contract_uri"`
+	RoyaltyReceiver    string    `gorm:"type:varchar(42)" json:"royalty_receiver,omitempty"`
+	RoyaltyBasisPoints *uint32   `json:"royalty_basis_points,omitempty"`
+	TokenCount         int64     `gorm:"-" json:"token_count"`
+	RegisteredAt       time.Time `json:"registered_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the Collection model
+func (Collection) TableName() string {
+	return "collections"
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// Token standards of tracked contracts
+const (
+	StandardERC721  = "erc721"
+	StandardERC1155 = "erc1155"
+	// StandardUnknown is used for contracts that do not implement ERC-165,
+	// such as ERC-721 contracts deployed before the standard was final
+	StandardUnknown = "unknown"
+	// StandardOther is used for ERC-165 contracts that are neither ERC-721
+	// nor ERC-1155
+	StandardOther = "other"
+)
+
+// Contract records the interfaces a contract advertised through ERC-165 when
+// it was first used. Standard is derived from them.
+type Contract struct {
+	ChainID          uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	Address          string    `gorm:"primaryKey;type:varchar(42)" json:"address"`
+	Standard         string    `gorm:"type:varchar(16);not null" json:"standard"`
+	ERC165           bool      `gorm:"column:erc165;not null" json:"erc165"`
+	ERC721           bool      `gorm:"column:erc721;not null" json:"erc721"`
+	ERC721Metadata   bool      `gorm:"column:erc721_metadata;not null" json:"erc721_metadata"`
+	ERC721Enumerable bool      `gorm:"column:erc721_enumerable;not null" json:"erc721_enumerable"`
+	ERC1155          bool      `gorm:"column:erc1155;not null" json:"erc1155"`
+	ERC2981          bool      `gorm:"column:erc2981;not null" json:"erc2981"`
+	DetectedAt       time.Time `gorm:"not null" json:"detected_at"`
+}
+
+// TableName returns the table name for the Contract model
+func (Contract) TableName() string {
+	return "contracts"
+}
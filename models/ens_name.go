@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Directions of cached ENS lookups
+const (
+	ENSForward = "forward"
+	ENSReverse = "reverse"
+)
+
+// ENSName caches an ENS lookup on a chain. Forward entries map a name to the
+// checksummed address it resolves to; reverse entries map a checksummed
+// address to its verified primary name, which is empty if it has none. Entries
+// are not used after ExpiresAt.
+type ENSName struct {
+	ChainID   uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	Direction string    `gorm:"primaryKey;type:varchar(7)" json:"direction"`
+	Query     string    `gorm:"primaryKey;type:varchar(255)" json:"query"`
+	Result    string    `gorm:"type:varchar(255);not null" json:"result"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the ENSName model
+func (ENSName) TableName() string {
+	return "ens_names"
+}
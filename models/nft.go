@@ -4,15 +4,35 @@ import (
 	"time"
 )
 
-// NFT represents the NFT data structure in the database
+// BurnedOwner is the owner stored for tokens that have been burned
+const BurnedOwner = "0x0000000000000000000000000000000000000000"
+
+// NFT represents the NFT data structure in the database.
+// An NFT is identified by the chain it lives on, the contract that minted it
+// and its token ID within that contract. BlockNumber is the block the owner
+// was read at. OwnerENS is the verified primary ENS name of the owner; it is
+// filled in by the service when available and never stored, as are
+// RoyaltyReceiver and RoyaltyBasisPoints, the stored ERC-2981 royalty of the
+// token.
 type NFT struct {
-	TokenID   uint      `gorm:"primaryKey" json:"token_id"`
-	Owner     string    `gorm:"type:varchar(42);not null" json:"owner"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ChainID            uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	ContractAddress    string    `gorm:"primaryKey;type:varchar(42)" json:"contract_address"`
+	TokenID            TokenID   `gorm:"primaryKey" json:"token_id"`
+	Owner              string    `gorm:"type:varchar(42);not null" json:"owner"`
+	BlockNumber        uint64    `gorm:"not null;default:0" json:"block_number"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	OwnerENS           string    `gorm:"-" json:"owner_ens,omitempty"`
+	RoyaltyReceiver    string    `gorm:"-" json:"royalty_receiver,omitempty"`
+	RoyaltyBasisPoints *uint32   `gorm:"-" json:"royalty_basis_points,omitempty"`
 }
 
 // TableName returns the table name for the NFT model
 func (NFT) TableName() string {
 	return "nfts"
 }
+
+// IsBurned reports whether the token has been burned
+func (n *NFT) IsBurned() bool {
+	return n.Owner == BurnedOwner
+}
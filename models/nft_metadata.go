@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// Attribute is a trait of an NFT as listed in the attributes array of its
+// metadata. Value is a string, number or boolean.
+type Attribute struct {
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	DisplayType string      `json:"display_type,omitempty"`
+}
+
+// NFTMetadata is the metadata document a token's tokenURI (ERC-721) or uri
+// (ERC-1155) points to. Raw holds the document as fetched; the other fields
+// are parsed from it.
+type NFTMetadata struct {
+	ChainID         uint64      `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	ContractAddress string      `gorm:"primaryKey;type:varchar(42)" json:"contract_address"`
+	TokenID         TokenID     `gorm:"primaryKey" json:"token_id"`
+	URI             string      `gorm:"type:text;not null" json:"uri"`
+	Raw             string      `gorm:"type:text;not null" json:"raw"`
+	Name            string      `gorm:"type:text" json:"name"`
+	Description     string      `gorm:"type:text" json:"description"`
+	Image           string      `gorm:"type:text" json:"image"`
+	Attributes      []Attribute `gorm:"type:text;serializer:json" json:"attributes"`
+	FetchedAt       time.Time   `json:"fetched_at"`
+}
+
+// TableName returns the table name for the NFTMetadata model
+func (NFTMetadata) TableName() string {
+	return "nft_metadata"
+}
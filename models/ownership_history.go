@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// OwnershipHistory is an append-only record of an observed ownership change.
+// PreviousOwner is empty for the first observation of a token. ObservedAt is
+// the time of the block for changes derived from Transfer events and the time
+// of the ownerOf read otherwise. TransferEventID references the indexed
+// Transfer log a change was derived from and is nil for ownerOf reads.
+type OwnershipHistory struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ChainID         uint64    `gorm:"not null;index:idx_ownership_history_token" json:"chain_id"`
+	ContractAddress string    `gorm:"type:varchar(42);not null;index:idx_ownership_history_token" json:"contract_address"`
+	TokenID         TokenID   `gorm:"not null;index:idx_ownership_history_token" json:"token_id"`
+	PreviousOwner   string    `gorm:"type:varchar(42)" json:"previous_owner"`
+	NewOwner        string    `gorm:"type:varchar(42);not null" json:"new_owner"`
+	BlockNumber     uint64    `json:"block_number"`
+	ObservedAt      time.Time `gorm:"not null;index" json:"observed_at"`
+	TransferEventID *uint     `gorm:"index" json:"transfer_event_id,omitempty"`
+}
+
+// TableName returns the table name for the OwnershipHistory model
+func (OwnershipHistory) TableName() string {
+	return "ownership_history"
+}
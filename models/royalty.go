@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Royalty is the ERC-2981 royalty of a token as returned by royaltyInfo:
+// the receiver and the rate in basis points (1/100 of a percent) of the sale
+// price
+type Royalty struct {
+	ChainID         uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	ContractAddress string    `gorm:"primaryKey;type:varchar(42)" json:"contract_address"`
+	TokenID         TokenID   `gorm:"primaryKey" json:"token_id"`
+	Receiver        string    `gorm:"type:varchar(42);not null" json:"receiver"`
+	BasisPoints     uint32    `gorm:"not null" json:"basis_points"`
+	FetchedAt       time.Time `json:"fetched_at"`
+}
+
+// TableName returns the table name for the Royalty model
+func (Royalty) TableName() string {
+	return "royalties"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// TokenBalance is the balance a holder has of an ERC-1155 token. Only
+// holders with a positive balance are stored. BlockNumber is the block the
+// balance was read at.
+type TokenBalance struct {
+	ChainID         uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	ContractAddress string    `gorm:"primaryKey;type:varchar(42)" json:"contract_address"`
+	TokenID         TokenID   `gorm:"primaryKey" json:"token_id"`
+	Holder          string    `gorm:"primaryKey;type:varchar(42);index" json:"holder"`
+	Balance         Amount    `gorm:"not null" json:"balance"`
+	BlockNumber     uint64    `gorm:"not null" json:"block_number"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the TokenBalance model
+func (TokenBalance) TableName() string {
+	return "token_balances"
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// maxTokenID is the largest value a uint256 token ID can hold
+var maxTokenID = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+// TokenID represents an unsigned 256-bit ERC-721 token ID.
+// It is stored as NUMERIC(78,0) in the database and encoded as a decimal
+// string in JSON so that no precision is lost.
+type TokenID struct {
+	value big.Int
+}
+
+// NewTokenID creates a token ID from a big integer
+func NewTokenID(v *big.Int) (TokenID, error) {
+	var id TokenID
+	if v == nil || v.Sign() < 0 || v.Cmp(maxTokenID) > 0 {
+		return id, fmt.Errorf("token ID %v is out of uint256 range", v)
+	}
+	id.value.Set(v)
+	return id, nil
+}
+
+// TokenIDFromUint64 creates a token ID from a uint64
+func TokenIDFromUint64(v uint64) TokenID {
+	var id TokenID
+	id.value.SetUint64(v)
+	return id
+}
+
+// ParseTokenID parses a token ID given in decimal or 0x-prefixed hex notation
+func ParseTokenID(s string) (TokenID, error) {
+	s = strings.TrimSpace(s)
+
+	base := 10
+	digits := s
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		base = 16
+		digits = s[2:]
+	}
+
+	v, ok := new(big.Int).SetString(digits, base)
+	if !ok || digits == "" || strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		return TokenID{}, fmt.Errorf("invalid token ID %q: must be a decimal or 0x-prefixed hex integer", s)
+	}
+
+	return NewTokenID(v)
+}
+
+// Big returns a copy of the token ID as a big integer
+func (t TokenID) Big() *big.Int {
+	return new(big.Int).Set(&t.value)
+}
+
+// String returns the decimal representation of the token ID
+func (t TokenID) String() string {
+	return t.value.String()
+}
+
+// Hex returns the 0x-prefixed hex representation of the token ID
+func (t TokenID) Hex() string {
+	return "0x" + t.value.Text(16)
+}
+
+// Cmp compares two token IDs and returns -1, 0 or +1
+func (t TokenID) Cmp(other TokenID) int {
+	return t.value.Cmp(&other.value)
+}
+
+// GormDataType returns the column type used to store token IDs
+func (TokenID) GormDataType() string {
+	return "numeric(78,0)"
+}
+
+// Value implements driver.Valuer
+func (t TokenID) Value() (driver.Value, error) {
+	return t.value.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (t *TokenID) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		if v < 0 {
+			return fmt.Errorf("cannot scan negative token ID %d", v)
+		}
+		t.value.SetInt64(v)
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into TokenID", src)
+	}
+
+	parsed, err := ParseTokenID(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// MarshalJSON encodes the token ID as a decimal string
+func (t TokenID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.value.String())
+}
+
+// UnmarshalJSON accepts a decimal or hex string as well as a plain JSON number
+func (t *TokenID) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	parsed, err := ParseTokenID(s)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
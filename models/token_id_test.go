@@ -0,0 +1,164 @@
+package models
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+const maxUint256 = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+func TestParseTokenID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "0", want: "0"},
+		{input: "42", want: "42"},
+		{input: " 42 ", want: "42"},
+		{input: "007", want: "7"},
+		{input: "0x2a", want: "42"},
+		{input: "0X2A", want: "42"},
+		{input: "0x000000000000000000000000000000000000000000000000000000000000002a", want: "42"},
+		{input: maxUint256, want: maxUint256},
+		{input: "0x" + "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", want: maxUint256},
+		{input: "115792089237316195423570985008687907853269984665640564039457584007913129639936", wantErr: true},
+		{input: "0x1" + "0000000000000000000000000000000000000000000000000000000000000000", wantErr: true},
+		{input: "-1", wantErr: true},
+		{input: "+1", wantErr: true},
+		{input: "0x-1", wantErr: true},
+		{input: "0x", wantErr: true},
+		{input: "", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "0xzz", wantErr: true},
+		{input: "1.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTokenID(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseTokenID(%q) = %s, want error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTokenID(%q) error: %v", tt.input, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseTokenID(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewTokenID(t *testing.T) {
+	max, _ := new(big.Int).SetString(maxUint256, 10)
+
+	if _, err := NewTokenID(max); err != nil {
+		t.Errorf("NewTokenID(2^256-1) error: %v", err)
+	}
+	if _, err := NewTokenID(new(big.Int).Add(max, big.NewInt(1))); err == nil {
+		t.Errorf("NewTokenID(2^256) succeeded, want error")
+	}
+	if _, err := NewTokenID(big.NewInt(-1)); err == nil {
+		t.Errorf("NewTokenID(-1) succeeded, want error")
+	}
+	if _, err := NewTokenID(nil); err == nil {
+		t.Errorf("NewTokenID(nil) succeeded, want error")
+	}
+}
+
+func TestTokenIDScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    string
+		wantErr bool
+	}{
+		{name: "string", src: "42", want: "42"},
+		{name: "bytes", src: []byte(maxUint256), want: maxUint256},
+		{name: "int64", src: int64(42), want: "42"},
+		{name: "negative int64", src: int64(-1), wantErr: true},
+		{name: "negative string", src: "-1", wantErr: true},
+		{name: "overflow", src: "115792089237316195423570985008687907853269984665640564039457584007913129639936", wantErr: true},
+		{name: "float", src: 1.5, wantErr: true},
+		{name: "nil", src: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id TokenID
+			err := id.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Scan(%v) = %s, want error", tt.src, id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v) error: %v", tt.src, err)
+			}
+			if id.String() != tt.want {
+				t.Errorf("Scan(%v) = %s, want %s", tt.src, id, tt.want)
+			}
+
+			value, err := id.Value()
+			if err != nil {
+				t.Fatalf("Value() error: %v", err)
+			}
+			if value != tt.want {
+				t.Errorf("Value() = %v, want %s", value, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenIDJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "decimal string", input: `"42"`, want: "42"},
+		{name: "hex string", input: `"0x2a"`, want: "42"},
+		{name: "number", input: `42`, want: "42"},
+		{name: "max uint256 string", input: `"` + maxUint256 + `"`, want: maxUint256},
+		{name: "max uint256 number", input: maxUint256, want: maxUint256},
+		{name: "negative number", input: `-1`, wantErr: true},
+		{name: "fractional number", input: `1.5`, wantErr: true},
+		{name: "empty string", input: `""`, wantErr: true},
+		{name: "null", input: `null`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var id TokenID
+			err := json.Unmarshal([]byte(tt.input), &id)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %s, want error", tt.input, id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error: %v", tt.input, err)
+			}
+			if id.String() != tt.want {
+				t.Errorf("Unmarshal(%s) = %s, want %s", tt.input, id, tt.want)
+			}
+
+			// token IDs are always encoded as decimal strings
+			data, err := json.Marshal(id)
+			if err != nil {
+				t.Fatalf("Marshal() error: %v", err)
+			}
+			if string(data) != `"`+tt.want+`"` {
+				t.Errorf("Marshal() = %s, want %q", data, tt.want)
+			}
+		})
+	}
+}
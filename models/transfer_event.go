@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+)
+
+// Kinds of ERC-721 transfers
+const (
+	TransferKindMint     = "mint"
+	TransferKindTransfer = "transfer"
+	TransferKindBurn     = "burn"
+)
+
+// TransferEvent is an indexed ERC-721 Transfer log
+type TransferEvent struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	ChainID         uint64    `gorm:"not null;uniqueIndex:idx_transfer_events_log;index:idx_transfer_events_token" json:"chain_id"`
+	ContractAddress string    `gorm:"type:varchar(42);not null;index:idx_transfer_events_token" json:"contract_address"`
+	TokenID         TokenID   `gorm:"not null;index:idx_transfer_events_token" json:"token_id"`
+	FromAddress     string    `gorm:"type:varchar(42);not null" json:"from_address"`
+	ToAddress       string    `gorm:"type:varchar(42);not null" json:"to_address"`
+	Kind            string    `gorm:"type:varchar(16);not null" json:"kind"`
+	BlockNumber     uint64    `gorm:"not null;index" json:"block_number"`
+	BlockHash       string    `gorm:"type:varchar(66);not null;uniqueIndex:idx_transfer_events_log" json:"block_hash"`
+	TxHash          string    `gorm:"type:varchar(66);not null" json:"tx_hash"`
+	LogIndex        uint      `gorm:"not null;uniqueIndex:idx_transfer_events_log" json:"log_index"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for the TransferEvent model
+func (TransferEvent) TableName() string {
+	return "transfer_events"
+}
+
+// IndexerCheckpoint records the last block whose Transfer events have been
+// indexed for a contract
+type IndexerCheckpoint struct {
+	ChainID         uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	ContractAddress string    `gorm:"primaryKey;type:varchar(42)" json:"contract_address"`
+	LastBlock       uint64    `gorm:"not null" json:"last_block"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName returns the table name for the IndexerCheckpoint model
+func (IndexerCheckpoint) TableName() string {
+	return "indexer_checkpoints"
+}
+
+// ProcessedBlock records the hash of a block the indexer has processed for a
+// contract so that chain reorganizations can be detected on the next sync
+type ProcessedBlock struct {
+	ChainID         uint64    `gorm:"primaryKey;autoIncrement:false" json:"chain_id"`
+	ContractAddress string    `gorm:"primaryKey;type:varchar(42)" json:"contract_address"`
+	BlockNumber     uint64    `gorm:"primaryKey;autoIncrement:false" json:"block_number"`
+	BlockHash       string    `gorm:"type:varchar(66);not null" json:"block_hash"`
+	ParentHash      string    `gorm:"type:varchar(66);not null" json:"parent_hash"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// TableName returns the table name for the ProcessedBlock model
+func (ProcessedBlock) TableName() string {
+	return "processed_blocks"
+}
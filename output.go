@@ -0,0 +1,547 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"go-cli-eth/dto"
+	"go-cli-eth/ethereum"
+	"go-cli-eth/handlers"
+	"go-cli-eth/indexer"
+	"go-cli-eth/models"
+	"go-cli-eth/services"
+)
+
+// Output formats supported by the --output flag
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+	outputCSV   = "csv"
+)
+
+// isOutputFormat reports whether format is a supported output format
+func isOutputFormat(format string) bool {
+	return format == outputJSON || format == outputTable || format == outputCSV
+}
+
+// printNFTs writes nfts to w in the given output format
+func printNFTs(w io.Writer, format string, nfts []models.NFT) error {
+	switch format {
+	case outputJSON:
+		responses := make([]dto.NFTResponse, 0, len(nfts))
+		for i := range nfts {
+			responses = append(responses, handlers.ConvertModelToDTO(&nfts[i]))
+		}
+		return writeJSON(w, responses)
+	case outputCSV:
+		rows := make([][]string, 0, len(nfts))
+		for _, nft := range nfts {
+			rows = append(rows, []string{
+				fmt.Sprint(nft.ChainID),
+				nft.ContractAddress,
+				nft.TokenID.String(),
+				nft.Owner,
+				fmt.Sprint(nft.BlockNumber),
+				nft.CreatedAt.Format(time.RFC3339),
+				nft.UpdatedAt.Format(time.RFC3339),
+				nft.OwnerENS,
+				nft.RoyaltyReceiver,
+				formatBasisPoints(nft.RoyaltyBasisPoints),
+			})
+		}
+		return writeCSV(w, []string{"chain_id", "contract_address", "token_id", "owner", "block_number", "created_at", "updated_at", "owner_ens", "royalty_receiver", "royalty_basis_points"}, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHAIN\tCONTRACT\tTOKEN ID\tOWNER\tBLOCK\tUPDATED")
+		for _, nft := range nfts {
+			owner := nft.Owner
+			if nft.OwnerENS != "" {
+				owner += " (" + nft.OwnerENS + ")"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\t%s\n",
+				nft.ChainID,
+				nft.ContractAddress,
+				nft.TokenID,
+				owner,
+				nft.BlockNumber,
+				nft.UpdatedAt.Format("2006-01-02 15:04:05"))
+		}
+		return tw.Flush()
+	}
+}
+
+// printHistory writes ownership history entries to w in the given output format
+func printHistory(w io.Writer, format string, history []models.OwnershipHistory) error {
+	switch format {
+	case outputJSON:
+		responses := make([]dto.OwnershipHistoryResponse, 0, len(history))
+		for i := range history {
+			responses = append(responses, handlers.ConvertHistoryToDTO(&history[i]))
+		}
+		return writeJSON(w, responses)
+	case outputCSV:
+		rows := make([][]string, 0, len(history))
+		for _, entry := range history {
+			rows = append(rows, []string{
+				fmt.Sprint(entry.ChainID),
+				entry.ContractAddress,
+				entry.TokenID.String(),
+				entry.PreviousOwner,
+				entry.NewOwner,
+				fmt.Sprint(entry.BlockNumber),
+				entry.ObservedAt.Format(time.RFC3339),
+			})
+		}
+		return writeCSV(w, []string{"chain_id", "contract_address", "token_id", "previous_owner", "new_owner", "block_number", "observed_at"}, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "OBSERVED\tBLOCK\tPREVIOUS OWNER\tNEW OWNER")
+		for _, entry := range history {
+			previousOwner := entry.PreviousOwner
+			if previousOwner == "" {
+				previousOwner = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n",
+				entry.ObservedAt.Format("2006-01-02 15:04:05"),
+				entry.BlockNumber,
+				previousOwner,
+				entry.NewOwner)
+		}
+		return tw.Flush()
+	}
+}
+
+// printIndexStats writes the summary of an indexer sync to w in the given output format
+func printIndexStats(w io.Writer, format string, stats indexer.Stats) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, stats)
+	case outputCSV:
+		return writeCSV(w, []string{"from_block", "to_block", "transfers", "mints", "burns"}, [][]string{{
+			fmt.Sprint(stats.FromBlock),
+			fmt.Sprint(stats.ToBlock),
+			fmt.Sprint(stats.Transfers),
+			fmt.Sprint(stats.Mints),
+			fmt.Sprint(stats.Burns),
+		}})
+	default:
+		if stats.ReorgBlock != nil {
+			fmt.Fprintf(w, "Reorg detected: rolled back to block %d\n", *stats.ReorgBlock)
+		}
+		if stats.FromBlock > stats.ToBlock {
+			_, err := fmt.Fprintf(w, "Already indexed up to block %d\n", stats.ToBlock)
+			return err
+		}
+		_, err := fmt.Fprintf(w, "Indexed blocks %d-%d: %d transfer(s), %d mint(s), %d burn(s)\n",
+			stats.FromBlock, stats.ToBlock, stats.Transfers, stats.Mints, stats.Burns)
+		return err
+	}
+}
+
+// printRefreshResult writes the summary of a batched refresh to w in the given output format
+func printRefreshResult(w io.Writer, format string, result *services.RefreshResult) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, result)
+	case outputCSV:
+		rows := make([][]string, 0, len(result.Failed))
+		for _, failure := range result.Failed {
+			rows = append(rows, []string{failure.TokenID.String(), failure.Error})
+		}
+		return writeCSV(w, []string{"failed_token_id", "error"}, rows)
+	default:
+		fmt.Fprintf(w, "Refreshed %s at block %d: %d created, %d updated, %d unchanged, %d burned, %d failed\n",
+			result.ContractAddress, result.BlockNumber, result.Created, result.Updated, result.Unchanged, result.Burned, len(result.Failed))
+		if len(result.Failed) == 0 {
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FAILED TOKEN ID\tERROR")
+		for _, failure := range result.Failed {
+			fmt.Fprintf(tw, "%s\t%s\n", failure.TokenID, failure.Error)
+		}
+		return tw.Flush()
+	}
+}
+
+// printContract writes the detected interfaces of a contract to w in the given output format
+func printContract(w io.Writer, format string, contract *models.Contract) error {
+	interfaces := []struct {
+		name      string
+		supported bool
+	}{
+		{"erc165", contract.ERC165},
+		{"erc721", contract.ERC721},
+		{"erc721_metadata", contract.ERC721Metadata},
+		{"erc721_enumerable", contract.ERC721Enumerable},
+		{"erc1155", contract.ERC1155},
+		{"erc2981", contract.ERC2981},
+	}
+
+	switch format {
+	case outputJSON:
+		return writeJSON(w, handlers.ConvertContractToDTO(contract))
+	case outputCSV:
+		header := []string{"chain_id", "address", "standard"}
+		row := []string{fmt.Sprint(contract.ChainID), contract.Address, contract.Standard}
+		for _, iface := range interfaces {
+			header = append(header, iface.name)
+			row = append(row, fmt.Sprint(iface.supported))
+		}
+		header = append(header, "detected_at")
+		row = append(row, contract.DetectedAt.Format(time.RFC3339))
+		return writeCSV(w, header, [][]string{row})
+	default:
+		fmt.Fprintf(w, "%s on chain %d: %s (detected %s)\n",
+			contract.Address, contract.ChainID, contract.Standard, contract.DetectedAt.Format("2006-01-02 15:04:05"))
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "INTERFACE\tSUPPORTED")
+		for _, iface := range interfaces {
+			fmt.Fprintf(tw, "%s\t%t\n", iface.name, iface.supported)
+		}
+		return tw.Flush()
+	}
+}
+
+// printChains writes the configured chains to w in the given output format,
+// marking the selected one as the default
+func printChains(w io.Writer, format string, chains []ethereum.ChainConfig, selectedID uint64) error {
+	responses := make([]dto.ChainResponse, 0, len(chains))
+	for _, chain := range chains {
+		responses = append(responses, handlers.ConvertChainToDTO(chain, chain.ID == selectedID))
+	}
+
+	switch format {
+	case outputJSON:
+		return writeJSON(w, responses)
+	case outputCSV:
+		rows := make([][]string, 0, len(responses))
+		for _, chain := range responses {
+			rows = append(rows, []string{
+				fmt.Sprint(chain.ChainID),
+				chain.Name,
+				fmt.Sprint(chain.Endpoints),
+				fmt.Sprint(chain.Confirmations),
+				chain.MulticallAddress,
+				fmt.Sprint(chain.Default),
+			})
+		}
+		return writeCSV(w, []string{"chain_id", "name", "endpoints", "confirmations", "multicall_address", "default"}, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHAIN\tNAME\tENDPOINTS\tCONFIRMATIONS\tMULTICALL\tDEFAULT")
+		for _, chain := range responses {
+			confirmations := "-"
+			if chain.Confirmations > 0 {
+				confirmations = fmt.Sprint(chain.Confirmations)
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%s\t%t\n",
+				chain.ChainID,
+				chain.Name,
+				chain.Endpoints,
+				confirmations,
+				chain.MulticallAddress,
+				chain.Default)
+		}
+		return tw.Flush()
+	}
+}
+
+// printRPCStatus writes the endpoint health of chains to w in the given
+// output format, one row per endpoint
+func printRPCStatus(w io.Writer, format string, statuses []ethereum.ChainStatus) error {
+	responses := make([]dto.ChainHealthResponse, 0, len(statuses))
+	for _, status := range statuses {
+		responses = append(responses, handlers.ConvertChainStatusToDTO(status))
+	}
+
+	switch format {
+	case outputJSON:
+		return writeJSON(w, responses)
+	case outputCSV:
+		var rows [][]string
+		for _, chain := range responses {
+			for _, endpoint := range chain.Endpoints {
+				rows = append(rows, []string{
+					fmt.Sprint(chain.ChainID),
+					chain.Name,
+					chain.Selection,
+					endpoint.URL,
+					fmt.Sprint(endpoint.Healthy),
+					fmt.Sprint(endpoint.Requests),
+					fmt.Sprint(endpoint.Failures),
+					fmt.Sprint(endpoint.AvgLatencyMs),
+					fmt.Sprint(endpoint.HeadBlock),
+					endpoint.LastError,
+				})
+			}
+		}
+		return writeCSV(w, []string{"chain_id", "name", "selection", "url", "healthy", "requests", "failures", "avg_latency_ms", "head_block", "last_error"}, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHAIN\tSELECTION\tENDPOINT\tHEALTHY\tLATENCY\tHEAD BLOCK\tLAST ERROR")
+		for _, chain := range responses {
+			for _, endpoint := range chain.Endpoints {
+				latency, head, lastError := "-", "-", "-"
+				if endpoint.AvgLatencyMs > 0 || endpoint.Requests > endpoint.Failures {
+					latency = fmt.Sprintf("%dms", endpoint.AvgLatencyMs)
+				}
+				if endpoint.HeadBlock > 0 {
+					head = fmt.Sprint(endpoint.HeadBlock)
+				}
+				if endpoint.LastError != "" {
+					lastError = endpoint.LastError
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n",
+					chain.Name,
+					chain.Selection,
+					endpoint.URL,
+					endpoint.Healthy,
+					latency,
+					head,
+					lastError)
+			}
+		}
+		return tw.Flush()
+	}
+}
+
+// printMetadata writes the metadata of a token to w in the given output format
+func printMetadata(w io.Writer, format string, meta *models.NFTMetadata) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, handlers.ConvertMetadataToDTO(meta))
+	case outputCSV:
+		attributes, err := json.Marshal(meta.Attributes)
+		if err != nil {
+			return err
+		}
+		return writeCSV(w, []string{"chain_id", "contract_address", "token_id", "uri", "name", "description", "image", "attributes", "fetched_at"}, [][]string{{
+			fmt.Sprint(meta.ChainID),
+			meta.ContractAddress,
+			meta.TokenID.String(),
+			meta.URI,
+			meta.Name,
+			meta.Description,
+			meta.Image,
+			string(attributes),
+			meta.FetchedAt.Format(time.RFC3339),
+		}})
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(tw, "Name:\t%s\n", meta.Name)
+		fmt.Fprintf(tw, "Description:\t%s\n", meta.Description)
+		fmt.Fprintf(tw, "Image:\t%s\n", meta.Image)
+		fmt.Fprintf(tw, "URI:\t%s\n", meta.URI)
+		fmt.Fprintf(tw, "Fetched:\t%s\n", meta.FetchedAt.Format("2006-01-02 15:04:05"))
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if len(meta.Attributes) == 0 {
+			return nil
+		}
+
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TRAIT\tVALUE")
+		for _, attr := range meta.Attributes {
+			fmt.Fprintf(tw, "%s\t%v\n", attr.TraitType, attr.Value)
+		}
+		return tw.Flush()
+	}
+}
+
+// printCollections writes collections to w in the given output format
+func printCollections(w io.Writer, format string, collections []models.Collection) error {
+	switch format {
+	case outputJSON:
+		responses := make([]dto.CollectionResponse, 0, len(collections))
+		for i := range collections {
+			responses = append(responses, handlers.ConvertCollectionToDTO(&collections[i]))
+		}
+		return writeJSON(w, responses)
+	case outputCSV:
+		rows := make([][]string, 0, len(collections))
+		for _, collection := range collections {
+			rows = append(rows, []string{
+				fmt.Sprint(collection.ChainID),
+				collection.Address,
+				collection.Standard,
+				collection.Name,
+				collection.Symbol,
+				totalSupply(collection),
+				collection.ContractURI,
+				collection.RoyaltyReceiver,
+				formatBasisPoints(collection.RoyaltyBasisPoints),
+				fmt.Sprint(collection.TokenCount),
+				collection.RegisteredAt.Format(time.RFC3339),
+				collection.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		return writeCSV(w, []string{"chain_id", "address", "standard", "name", "symbol", "total_supply", "contract_uri", "royalty_receiver", "royalty_basis_points", "token_count", "registered_at", "updated_at"}, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHAIN\tADDRESS\tSTANDARD\tNAME\tSYMBOL\tSUPPLY\tTRACKED")
+		for _, collection := range collections {
+			supply := totalSupply(collection)
+			if supply == "" {
+				supply = "-"
+			}
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\n",
+				collection.ChainID,
+				collection.Address,
+				collection.Standard,
+				collection.Name,
+				collection.Symbol,
+				supply,
+				collection.TokenCount)
+		}
+		return tw.Flush()
+	}
+}
+
+// totalSupply returns the total supply of a collection, or an empty string if it is unknown
+func totalSupply(collection models.Collection) string {
+	if collection.TotalSupply == nil {
+		return ""
+	}
+	return collection.TotalSupply.String()
+}
+
+// printRoyaltyReport writes the royalty configuration of a collection to w in the given output format
+func printRoyaltyReport(w io.Writer, format string, report *services.RoyaltyReport) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, report)
+	case outputCSV:
+		rows := make([][]string, 0, len(report.Overrides)+len(report.Failed))
+		for _, royalty := range report.Overrides {
+			rows = append(rows, []string{royalty.TokenID.String(), royalty.Receiver, fmt.Sprint(royalty.BasisPoints), ""})
+		}
+		for _, failure := range report.Failed {
+			rows = append(rows, []string{failure.TokenID.String(), "", "", failure.Error})
+		}
+		return writeCSV(w, []string{"token_id", "receiver", "basis_points", "error"}, rows)
+	default:
+		if report.BasisPoints == nil {
+			_, err := fmt.Fprintf(w, "No royalties stored for %s\n", report.ContractAddress)
+			return err
+		}
+		fmt.Fprintf(w, "Royalty of %s: %s to %s (%d token(s), %d override(s), %d failed)\n",
+			report.ContractAddress, formatRoyaltyRate(*report.BasisPoints), report.Receiver, report.Tokens, len(report.Overrides), len(report.Failed))
+		if len(report.Overrides) == 0 && len(report.Failed) == 0 {
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "TOKEN ID\tRECEIVER\tRATE")
+		for _, royalty := range report.Overrides {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", royalty.TokenID, royalty.Receiver, formatRoyaltyRate(royalty.BasisPoints))
+		}
+		for _, failure := range report.Failed {
+			fmt.Fprintf(tw, "%s\t-\tfailed: %s\n", failure.TokenID, failure.Error)
+		}
+		return tw.Flush()
+	}
+}
+
+// formatRoyaltyRate formats basis points as a percentage such as 2.5%
+func formatRoyaltyRate(basisPoints uint32) string {
+	return strconv.FormatFloat(float64(basisPoints)/100, 'f', -1, 64) + "%"
+}
+
+// formatBasisPoints formats optional basis points, or an empty string if unset
+func formatBasisPoints(basisPoints *uint32) string {
+	if basisPoints == nil {
+		return ""
+	}
+	return fmt.Sprint(*basisPoints)
+}
+
+// printBalances writes ERC-1155 balances to w in the given output format
+func printBalances(w io.Writer, format string, balances []models.TokenBalance) error {
+	switch format {
+	case outputJSON:
+		responses := make([]dto.BalanceResponse, 0, len(balances))
+		for i := range balances {
+			responses = append(responses, handlers.ConvertBalanceToDTO(&balances[i]))
+		}
+		return writeJSON(w, responses)
+	case outputCSV:
+		rows := make([][]string, 0, len(balances))
+		for _, balance := range balances {
+			rows = append(rows, []string{
+				fmt.Sprint(balance.ChainID),
+				balance.ContractAddress,
+				balance.TokenID.String(),
+				balance.Holder,
+				balance.Balance.String(),
+				fmt.Sprint(balance.BlockNumber),
+				balance.CreatedAt.Format(time.RFC3339),
+				balance.UpdatedAt.Format(time.RFC3339),
+			})
+		}
+		return writeCSV(w, []string{"chain_id", "contract_address", "token_id", "holder", "balance", "block_number", "created_at", "updated_at"}, rows)
+	default:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "CHAIN\tCONTRACT\tTOKEN ID\tHOLDER\tBALANCE\tBLOCK\tUPDATED")
+		for _, balance := range balances {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%d\t%s\n",
+				balance.ChainID,
+				balance.ContractAddress,
+				balance.TokenID,
+				balance.Holder,
+				balance.Balance,
+				balance.BlockNumber,
+				balance.UpdatedAt.Format("2006-01-02 15:04:05"))
+		}
+		return tw.Flush()
+	}
+}
+
+// printBalanceRefreshResult writes the summary of a balance refresh to w in the given output format
+func printBalanceRefreshResult(w io.Writer, format string, result *services.BalanceRefreshResult) error {
+	switch format {
+	case outputJSON:
+		return writeJSON(w, result)
+	case outputCSV:
+		return writeCSV(w, []string{"contract_address", "token_id", "block_number", "discovered", "holders", "removed"}, [][]string{{
+			result.ContractAddress,
+			result.TokenID.String(),
+			fmt.Sprint(result.BlockNumber),
+			fmt.Sprint(result.Discovered),
+			fmt.Sprint(result.Holders),
+			fmt.Sprint(result.Removed),
+		}})
+	default:
+		_, err := fmt.Fprintf(w, "Refreshed token ID %s of %s at block %d: %d holder(s), %d discovered, %d removed\n",
+			result.TokenID, result.ContractAddress, result.BlockNumber, result.Holders, result.Discovered, result.Removed)
+		return err
+	}
+}
+
+// writeJSON writes v to w as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// writeCSV writes a header and rows to w as CSV
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
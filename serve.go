@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/handlers"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take to finish after
+// a shutdown signal is received
+const shutdownTimeout = 10 * time.Second
+
+// runServe starts the REST API server and blocks until SIGINT or SIGTERM
+func runServe(ctx context.Context, args []string) error {
+	defaultAddr := os.Getenv("API_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = ":8000"
+	}
+
+	fs, conn := newFlagSet("serve")
+	addr := fs.String("addr", defaultAddr, "address the API server listens on (env API_ADDR)")
+	fs.DurationVar(&conn.healthInterval, "health-interval", envDuration("RPC_HEALTH_INTERVAL", ethereum.DefaultHealthCheckInterval),
+		"how often RPC endpoints are probed in the background, 0 to disable (env RPC_HEALTH_INTERVAL)")
+	if err := parseFlags(fs, conn, args); err != nil {
+		return err
+	}
+
+	nftService, cleanup, err := conn.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	nftHandler := handlers.NewNFTHandler(nftService)
+	server := &http.Server{
+		Addr:    *addr,
+		Handler: handlers.NewRouter(nftHandler),
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("API server listening on %s (Swagger UI at /swagger/index.html)", *addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			return fmt.Errorf("API server failed: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down API server...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down API server: %v", err)
+	}
+
+	log.Println("API server stopped")
+	return nil
+}
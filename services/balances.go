@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+)
+
+// balanceLogBatchSize is the number of blocks scanned per log query when
+// discovering the holders of an ERC-1155 token
+const balanceLogBatchSize = 2000
+
+// BalanceRefreshResult summarizes a refresh of the holders of an ERC-1155 token
+type BalanceRefreshResult struct {
+	ContractAddress string         `json:"contract_address"`
+	TokenID         models.TokenID `json:"token_id"`
+	BlockNumber     uint64         `json:"block_number"`
+	Discovered      int            `json:"discovered"`
+	Holders         int            `json:"holders"`
+	Removed         int            `json:"removed"`
+}
+
+// GetAndStoreBalance reads the balance a holder has of an ERC-1155 token at
+// the given block and stores it. A zero balance removes the stored holder.
+// Reads at a block older than the stored one are rejected.
+func (s *NFTService) GetAndStoreBalance(ctx context.Context, contractAddress string, tokenID models.TokenID, holder string, block ethereum.BlockRef) (*models.TokenBalance, error) {
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contractAddress, err = s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	holder, err = s.resolveAddress(ctx, holder)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireERC1155(ctx, contractAddress); err != nil {
+		return nil, err
+	}
+
+	value, blockNumber, err := s.chain.GetBalanceOf(ctx, contractAddress, holder, tokenID.Big(), block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance from blockchain: %w", chainError(err))
+	}
+
+	log.Printf("Retrieved balance %s of %s for token ID %s of %s at block %d", value, holder, tokenID, contractAddress, blockNumber)
+
+	balance, err := s.storeBalance(db, contractAddress, tokenID, holder, value, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return balance, nil
+}
+
+// GetBalances retrieves the stored holders of an ERC-1155 contract, largest
+// balance first. tokenID and holder narrow the result if set.
+func (s *NFTService) GetBalances(ctx context.Context, contractAddress string, tokenID *models.TokenID, holder string) ([]models.TokenBalance, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := db.Where("chain_id = ? AND contract_address = ?", s.chain.ChainID(), contractAddress)
+	if tokenID != nil {
+		query = query.Where("token_id = ?", *tokenID)
+	}
+	if holder != "" {
+		holder, err = s.resolveAddress(ctx, holder)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("holder = ?", holder)
+	}
+
+	var balances []models.TokenBalance
+	err = query.Order("token_id, balance DESC, holder").Find(&balances).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances: %v", err)
+	}
+
+	return balances, nil
+}
+
+// RefreshBalances re-reads the balances of every stored holder of an ERC-1155
+// token with balanceOfBatch, batchSize pairs per call. If fromBlock is set,
+// the TransferSingle and TransferBatch events of the contract from that block
+// up to the read block are scanned first and their recipients are read too,
+// which discovers holders that are not stored yet. Holders whose balance
+// dropped to zero are removed.
+func (s *NFTService) RefreshBalances(ctx context.Context, contractAddress string, tokenID models.TokenID, fromBlock uint64, block ethereum.BlockRef, batchSize int) (*BalanceRefreshResult, error) {
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contractAddress, err = s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireERC1155(ctx, contractAddress); err != nil {
+		return nil, err
+	}
+
+	// Resolve the block once so that discovery and reads see the same state
+	blockNumber, err := s.chain.ResolveBlock(ctx, block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve block: %w", chainError(err))
+	}
+	pinned := ethereum.BlockAt(blockNumber)
+	if block.Hash != nil {
+		pinned = block
+	}
+
+	stored, err := s.GetBalances(ctx, contractAddress, &tokenID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make([]string, 0, len(stored))
+	known := make(map[string]bool, len(stored))
+	for _, balance := range stored {
+		holders = append(holders, balance.Holder)
+		known[balance.Holder] = true
+	}
+
+	result := &BalanceRefreshResult{
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		BlockNumber:     blockNumber,
+	}
+
+	if fromBlock > 0 {
+		if fromBlock > blockNumber {
+			return nil, fmt.Errorf("from block %d is after block %d", fromBlock, blockNumber)
+		}
+		for start := fromBlock; start <= blockNumber; start += balanceLogBatchSize {
+			end := start + balanceLogBatchSize - 1
+			if end > blockNumber {
+				end = blockNumber
+			}
+
+			transfers, err := s.chain.FilterBalanceTransfers(ctx, contractAddress, start, end)
+			if err != nil {
+				return nil, fmt.Errorf("failed to discover holders: %w", chainError(err))
+			}
+			for _, transfer := range transfers {
+				holder := transfer.To.Hex()
+				if transfer.TokenID.Cmp(tokenID.Big()) != 0 || transfer.To == (common.Address{}) || known[holder] {
+					continue
+				}
+				known[holder] = true
+				holders = append(holders, holder)
+				result.Discovered++
+			}
+		}
+	}
+
+	if len(holders) == 0 {
+		return nil, fmt.Errorf("holders of token ID %s of %s %w in database", tokenID, contractAddress, ErrNotFound)
+	}
+
+	tokenIDs := make([]*big.Int, len(holders))
+	for i := range tokenIDs {
+		tokenIDs[i] = tokenID.Big()
+	}
+	values, _, err := s.chain.GetBalancesOf(ctx, contractAddress, holders, tokenIDs, pinned, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances from blockchain: %w", chainError(err))
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for i, holder := range holders {
+			balance, err := s.storeBalance(tx, contractAddress, tokenID, holder, values[i], blockNumber)
+			if err != nil {
+				return err
+			}
+			switch {
+			case !balance.Balance.IsZero():
+				result.Holders++
+			case i < len(stored):
+				result.Removed++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Refreshed %d holder(s) of token ID %s of %s at block %d", result.Holders, tokenID, contractAddress, blockNumber)
+	return result, nil
+}
+
+// storeBalance stores the balance of a holder read at blockNumber, removing
+// the holder if the balance is zero. Reads older than the stored balance are
+// rejected with an error wrapping ErrStaleBlock.
+func (s *NFTService) storeBalance(db *gorm.DB, contractAddress string, tokenID models.TokenID, holder string, value *big.Int, blockNumber uint64) (*models.TokenBalance, error) {
+	amount, err := models.NewAmount(value)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := models.TokenBalance{
+		ChainID:         s.chain.ChainID(),
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		Holder:          holder,
+		Balance:         amount,
+		BlockNumber:     blockNumber,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var existing models.TokenBalance
+		err := tx.Where("chain_id = ? AND contract_address = ? AND token_id = ? AND holder = ?", balance.ChainID, contractAddress, tokenID, holder).
+			First(&existing).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return err
+		}
+		stored := err == nil
+
+		if stored && blockNumber < existing.BlockNumber {
+			return fmt.Errorf("%w: block %d is older than the stored block %d of the balance of %s", ErrStaleBlock, blockNumber, existing.BlockNumber, holder)
+		}
+
+		switch {
+		case amount.IsZero() && stored:
+			return tx.Delete(&existing).Error
+		case amount.IsZero():
+			return nil
+		case stored:
+			balance.CreatedAt = existing.CreatedAt
+		}
+		return tx.Save(&balance).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store balance: %w", err)
+	}
+
+	return &balance, nil
+}
+
+// requireERC1155 rejects contracts that do not advertise ERC-1155 through
+// ERC-165, which ERC-1155 makes mandatory
+func (s *NFTService) requireERC1155(ctx context.Context, contractAddress string) error {
+	contract, err := s.contractInfo(ctx, contractAddress, false)
+	if err != nil {
+		return err
+	}
+	if contract.Standard != models.StandardERC1155 {
+		return fmt.Errorf("%w: %s is detected as %s", ErrNotERC1155, contractAddress, contract.Standard)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-cli-eth/ethereum"
+)
+
+// SetClientPool sets the pool of clients ForChain selects chains from
+func (s *NFTService) SetClientPool(pool *ethereum.ClientPool) {
+	s.pool = pool
+}
+
+// Chain returns the configuration of the chain the service reads from
+func (s *NFTService) Chain() ethereum.ChainConfig {
+	return s.chain.Chain()
+}
+
+// Chains returns the configured chains, the default one first. Without a
+// client pool only the chain of the service is returned.
+func (s *NFTService) Chains() []ethereum.ChainConfig {
+	if s.pool == nil {
+		return []ethereum.ChainConfig{s.Chain()}
+	}
+	return s.pool.Registry().Chains()
+}
+
+// ForChain returns a service reading from and storing records of the chain
+// selected by a chain ID or name. An empty selector returns the service
+// itself. Chains that are not configured are rejected with an error wrapping
+// ErrUnknownChain; chains that cannot be reached with ErrChainUnavailable.
+func (s *NFTService) ForChain(ctx context.Context, chain string) (*NFTService, error) {
+	if chain == "" {
+		return s, nil
+	}
+	if s.pool == nil {
+		registry, err := ethereum.NewChainRegistry([]ethereum.ChainConfig{s.Chain()})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := registry.Lookup(chain); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	ethClient, err := s.pool.Client(ctx, chain)
+	if err != nil {
+		if errors.Is(err, ErrUnknownChain) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrChainUnavailable, err)
+	}
+	if ethClient == s.chain {
+		return s, nil
+	}
+
+	chained := *s
+	chained.chain = ethClient
+	return &chained, nil
+}
+
+// RPCStatus returns the endpoint status of every configured chain, the
+// default one first. Without a client pool only the chain of the service is
+// reported.
+func (s *NFTService) RPCStatus() []ethereum.ChainStatus {
+	if s.pool == nil {
+		return []ethereum.ChainStatus{{
+			Chain:     s.Chain(),
+			Connected: true,
+			Healthy:   s.chain.Healthy(),
+			Endpoints: s.chain.EndpointStats(),
+		}}
+	}
+	return s.pool.Status()
+}
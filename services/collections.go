@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RegisterCollection registers a contract as a collection, reading its name,
+// symbol, contractURI and, for ERC-721 Enumerable contracts, totalSupply.
+// Registering a collection again refreshes that data.
+func (s *NFTService) RegisterCollection(ctx context.Context, contractAddress string) (*models.Collection, error) {
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contractAddress, err = s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	contract, err := s.contractInfo(ctx, contractAddress, false)
+	if err != nil {
+		return nil, err
+	}
+	if contract.Standard == models.StandardOther {
+		return nil, fmt.Errorf("%w: %s does not advertise ERC-721 or ERC-1155 through ERC-165", ErrNotERC721, contractAddress)
+	}
+
+	info, err := s.chain.GetCollectionInfo(ctx, common.HexToAddress(contractAddress), contract.ERC721Enumerable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection from blockchain: %w", chainError(err))
+	}
+
+	collection := models.Collection{
+		ChainID:      s.chain.ChainID(),
+		Address:      contractAddress,
+		Standard:     contract.Standard,
+		Name:         info.Name,
+		Symbol:       info.Symbol,
+		ContractURI:  info.ContractURI,
+		RegisteredAt: time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if info.TotalSupply != nil {
+		totalSupply, err := models.NewAmount(info.TotalSupply)
+		if err != nil {
+			return nil, fmt.Errorf("invalid total supply: %v", err)
+		}
+		collection.TotalSupply = &totalSupply
+	}
+
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chain_id"}, {Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"standard", "name", "symbol", "total_supply", "contract_uri", "updated_at"}),
+	}).Create(&collection).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save collection: %v", err)
+	}
+
+	log.Printf("Registered collection %s (%s) at %s", collection.Name, collection.Symbol, contractAddress)
+	return s.GetCollection(ctx, contractAddress)
+}
+
+// GetCollection retrieves a registered collection with its tracked token count
+func (s *NFTService) GetCollection(ctx context.Context, contractAddress string) (*models.Collection, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var collection models.Collection
+	err = db.Where("chain_id = ? AND address = ?", s.chain.ChainID(), contractAddress).First(&collection).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("collection %s %w in database", contractAddress, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get collection: %v", err)
+	}
+
+	collections := []models.Collection{collection}
+	if err := s.countTokens(ctx, collections); err != nil {
+		return nil, err
+	}
+	return &collections[0], nil
+}
+
+// GetCollections retrieves all registered collections with their tracked
+// token counts, ordered by name
+func (s *NFTService) GetCollections(ctx context.Context) ([]models.Collection, error) {
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var collections []models.Collection
+	err = db.Where("chain_id = ?", s.chain.ChainID()).Order("name, address").Find(&collections).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %v", err)
+	}
+
+	if err := s.countTokens(ctx, collections); err != nil {
+		return nil, err
+	}
+	return collections, nil
+}
+
+// countTokens sets the number of tracked tokens of every collection: stored
+// NFTs that are not burned for ERC-721 contracts and token IDs with stored
+// holders for ERC-1155 contracts
+func (s *NFTService) countTokens(ctx context.Context, collections []models.Collection) error {
+	if len(collections) == 0 {
+		return nil
+	}
+
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return err
+	}
+	chainID := s.chain.ChainID()
+	addresses := make([]string, 0, len(collections))
+	for _, collection := range collections {
+		addresses = append(addresses, collection.Address)
+	}
+
+	type tokenCount struct {
+		ContractAddress string
+		Count           int64
+	}
+	var nftCounts, balanceCounts []tokenCount
+
+	err = db.Model(&models.NFT{}).
+		Select("contract_address, COUNT(*) AS count").
+		Where("chain_id = ? AND contract_address IN ? AND owner <> ?", chainID, addresses, models.BurnedOwner).
+		Group("contract_address").
+		Scan(&nftCounts).Error
+	if err != nil {
+		return fmt.Errorf("failed to count tracked tokens: %v", err)
+	}
+	err = db.Model(&models.TokenBalance{}).
+		Select("contract_address, COUNT(DISTINCT token_id) AS count").
+		Where("chain_id = ? AND contract_address IN ?", chainID, addresses).
+		Group("contract_address").
+		Scan(&balanceCounts).Error
+	if err != nil {
+		return fmt.Errorf("failed to count tracked tokens: %v", err)
+	}
+
+	counts := make(map[string]int64, len(collections))
+	for _, count := range append(nftCounts, balanceCounts...) {
+		counts[count.ContractAddress] += count.Count
+	}
+	for i := range collections {
+		collections[i].TokenCount = counts[collections[i].Address]
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InspectContract returns the interfaces a contract advertises through
+// ERC-165. They are probed on first use and stored; refresh probes them again.
+func (s *NFTService) InspectContract(ctx context.Context, contractAddress string, refresh bool) (*models.Contract, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	return s.contractInfo(ctx, contractAddress, refresh)
+}
+
+// contractInfo returns the stored interfaces of a contract, probing and
+// storing them if they are not stored yet or refresh is set. Without a
+// database they are probed every time. Addresses without code are rejected
+// with an error wrapping ErrNotERC721.
+func (s *NFTService) contractInfo(ctx context.Context, contractAddress string, refresh bool) (*models.Contract, error) {
+	chainID := s.chain.ChainID()
+
+	if !refresh && s.db != nil {
+		var contract models.Contract
+		err := s.db.WithContext(ctx).Where("chain_id = ? AND address = ?", chainID, contractAddress).First(&contract).Error
+		if err == nil {
+			return &contract, nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to get contract: %v", err)
+		}
+	}
+
+	address := common.HexToAddress(contractAddress)
+	hasCode, err := s.chain.HasCode(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify contract: %w", chainError(err))
+	}
+	if !hasCode {
+		return nil, fmt.Errorf("%w: no contract deployed at %s", ErrNotERC721, contractAddress)
+	}
+
+	detected, err := s.chain.DetectInterfaces(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect interfaces: %w", chainError(err))
+	}
+
+	contract := models.Contract{
+		ChainID:          chainID,
+		Address:          contractAddress,
+		Standard:         standardOf(detected),
+		ERC165:           detected.ERC165,
+		ERC721:           detected.ERC721,
+		ERC721Metadata:   detected.ERC721Metadata,
+		ERC721Enumerable: detected.ERC721Enumerable,
+		ERC1155:          detected.ERC1155,
+		ERC2981:          detected.ERC2981,
+		DetectedAt:       time.Now(),
+	}
+	if s.db != nil {
+		err = s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&contract).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to save contract: %v", err)
+		}
+	}
+
+	log.Printf("Detected %s contract at %s", contract.Standard, contractAddress)
+	return &contract, nil
+}
+
+// requireERC721 rejects contracts that advertise another standard through
+// ERC-165. Contracts without ERC-165 are accepted since early ERC-721
+// contracts predate it; their ownerOf answer tells whether they are ERC-721.
+func (s *NFTService) requireERC721(ctx context.Context, contractAddress string) error {
+	contract, err := s.contractInfo(ctx, contractAddress, false)
+	if err != nil {
+		return err
+	}
+
+	switch contract.Standard {
+	case models.StandardERC721, models.StandardUnknown:
+		return nil
+	case models.StandardERC1155:
+		return fmt.Errorf("%w: %s is an ERC-1155 contract, use the balance operations", ErrNotERC721, contractAddress)
+	default:
+		return fmt.Errorf("%w: %s does not advertise ERC-721 or ERC-1155 through ERC-165", ErrNotERC721, contractAddress)
+	}
+}
+
+// standardOf derives the token standard of a contract from its interfaces
+func standardOf(detected ethereum.Interfaces) string {
+	switch {
+	case detected.ERC721:
+		return models.StandardERC721
+	case detected.ERC1155:
+		return models.StandardERC1155
+	case detected.ERC165:
+		return models.StandardOther
+	default:
+		return models.StandardUnknown
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultENSCacheTTL is how long ENS lookups are cached by default
+const DefaultENSCacheTTL = 24 * time.Hour
+
+// SetENSCacheTTL sets how long ENS lookups are cached. A zero or negative TTL
+// disables the cache.
+func (s *NFTService) SetENSCacheTTL(ttl time.Duration) {
+	s.ensCacheTTL = ttl
+}
+
+// resolveAddress returns the checksummed form of an address given either as
+// a hex address or as an ENS name. Names that do not resolve are rejected
+// with an error wrapping ErrInvalidAddress.
+func (s *NFTService) resolveAddress(ctx context.Context, input string) (string, error) {
+	if !ethereum.IsENSName(input) {
+		return normalizeAddress(input)
+	}
+
+	name, err := ethereum.NormalizeENSName(input)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	if address, ok := s.cachedENS(ctx, models.ENSForward, name); ok {
+		return address, nil
+	}
+
+	resolved, err := s.chain.ResolveName(ctx, name)
+	if errors.Is(err, ethereum.ErrENSNotFound) {
+		return "", fmt.Errorf("%w %q: %w", ErrInvalidAddress, input, err)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve ENS name: %w", chainError(err))
+	}
+
+	address := resolved.Hex()
+	s.cacheENS(ctx, models.ENSForward, name, address)
+	log.Printf("Resolved %s to %s", name, address)
+	return address, nil
+}
+
+// lookupOwnerName returns the verified primary ENS name of an owner, or an
+// empty string if it has none, the token is burned or the lookup failed.
+// Lookups are best effort: failures are logged, and addresses without a name
+// are cached as well.
+func (s *NFTService) lookupOwnerName(ctx context.Context, owner string) string {
+	if owner == models.BurnedOwner {
+		return ""
+	}
+	if name, ok := s.cachedENS(ctx, models.ENSReverse, owner); ok {
+		return name
+	}
+
+	name, err := s.chain.LookupAddress(ctx, common.HexToAddress(owner))
+	if err != nil {
+		if !errors.Is(err, ethereum.ErrENSNotFound) {
+			log.Printf("Failed to look up ENS name of %s: %v", owner, err)
+			return ""
+		}
+		name = ""
+	}
+
+	s.cacheENS(ctx, models.ENSReverse, owner, name)
+	return name
+}
+
+// cachedENS returns the cached result of an ENS lookup if it has not expired
+func (s *NFTService) cachedENS(ctx context.Context, direction, query string) (string, bool) {
+	if s.ensCacheTTL <= 0 || s.db == nil {
+		return "", false
+	}
+
+	var entry models.ENSName
+	err := s.db.WithContext(ctx).
+		Where("chain_id = ? AND direction = ? AND query = ? AND expires_at > ?", s.chain.ChainID(), direction, query, time.Now()).
+		First(&entry).Error
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			log.Printf("Failed to read ENS cache: %v", err)
+		}
+		return "", false
+	}
+
+	return entry.Result, true
+}
+
+// cacheENS stores the result of an ENS lookup for the cache TTL. Failures are
+// logged since the cache is only an optimization.
+func (s *NFTService) cacheENS(ctx context.Context, direction, query, result string) {
+	if s.ensCacheTTL <= 0 || s.db == nil {
+		return
+	}
+
+	entry := models.ENSName{
+		ChainID:   s.chain.ChainID(),
+		Direction: direction,
+		Query:     query,
+		Result:    result,
+		ExpiresAt: time.Now().Add(s.ensCacheTTL),
+		UpdatedAt: time.Now(),
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&entry).Error
+	if err != nil {
+		log.Printf("Failed to write ENS cache: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"go-cli-eth/database"
+	"go-cli-eth/ethereum"
+	"go-cli-eth/metadata"
+)
+
+var (
+	// ErrUnknownChain is returned when a requested chain is not configured
+	ErrUnknownChain = ethereum.ErrUnknownChain
+
+	// ErrNotFound is returned when a requested record is not stored
+	ErrNotFound = database.ErrNotFound
+
+	// ErrInvalidAddress is returned when a contract address is malformed
+	ErrInvalidAddress = ethereum.ErrInvalidAddress
+
+	// ErrInvalidTokenRange is returned when a token range to refresh is empty
+	// or too large
+	ErrInvalidTokenRange = errors.New("invalid token range")
+
+	// ErrStaleBlock is returned when an owner read at a block older than the
+	// stored one would overwrite the newer snapshot
+	ErrStaleBlock = database.ErrStaleBlock
+
+	// ErrTokenBurned is returned when a stored token no longer exists on chain.
+	// The stored NFT is kept with models.BurnedOwner as its owner.
+	ErrTokenBurned = errors.New("token has been burned")
+
+	// ErrNotERC721 is returned when a contract does not answer ownerOf like an
+	// ERC-721 contract or the address has no code
+	ErrNotERC721 = errors.New("contract is not ERC-721")
+
+	// ErrNotERC1155 is returned when a balance is requested from a contract
+	// that does not advertise ERC-1155
+	ErrNotERC1155 = errors.New("contract is not ERC-1155")
+
+	// ErrNotERC2981 is returned when royalties are requested from a contract
+	// that does not advertise ERC-2981
+	ErrNotERC2981 = errors.New("contract is not ERC-2981")
+
+	// ErrCallReverted is returned when ownerOf reverted for a reason other than
+	// a nonexistent token
+	ErrCallReverted = errors.New("call reverted")
+
+	// ErrInvalidMetadata is returned when a token's metadata URI is empty or
+	// unsupported, or the document it points to is not a JSON object
+	ErrInvalidMetadata = metadata.ErrInvalidMetadata
+
+	// ErrMetadataUnavailable is returned when a metadata document could not
+	// be fetched
+	ErrMetadataUnavailable = metadata.ErrUnavailable
+
+	// ErrRateLimited is returned when the RPC provider rejected a request
+	// because of a rate limit
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrChainUnavailable is returned when the chain could not be reached
+	ErrChainUnavailable = errors.New("chain unavailable")
+
+	// ErrNoDatabase is returned by operations on records other than NFTs
+	// when the repository of the service has no database
+	ErrNoDatabase = errors.New("no database")
+)
+
+// chainError wraps an error of a chain read with the sentinel describing it.
+// Nonexistent token reverts are returned unchanged so that callers can check
+// them with ethereum.IsNonexistentToken.
+func chainError(err error) error {
+	switch {
+	case ethereum.IsNonexistentToken(err):
+		return err
+	case errors.Is(err, ethereum.ErrEmptyResult):
+		return fmt.Errorf("%w: %w", ErrNotERC721, err)
+	case ethereum.IsRevert(err):
+		return fmt.Errorf("%w: %w", ErrCallReverted, err)
+	case ethereum.IsRateLimited(err):
+		return fmt.Errorf("%w: %w", ErrRateLimited, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrChainUnavailable, err)
+	}
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-cli-eth/metadata"
+	"go-cli-eth/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetMetadataFetcher replaces the fetcher used to retrieve token metadata,
+// e.g. to use another IPFS gateway
+func (s *NFTService) SetMetadataFetcher(fetcher *metadata.Fetcher) {
+	s.metadataFetcher = fetcher
+}
+
+// GetMetadata returns the metadata of a token. It is fetched through the
+// token's tokenURI (ERC-721) or uri (ERC-1155) on first use and stored;
+// refresh fetches it again.
+func (s *NFTService) GetMetadata(ctx context.Context, contractAddress string, tokenID models.TokenID, refresh bool) (*models.NFTMetadata, error) {
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	contractAddress, err = s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	chainID := s.chain.ChainID()
+
+	if !refresh {
+		var stored models.NFTMetadata
+		err := whereNFT(db, chainID, contractAddress, tokenID).First(&stored).Error
+		if err == nil {
+			return &stored, nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("failed to get metadata: %v", err)
+		}
+	}
+
+	uri, err := s.metadataURI(ctx, contractAddress, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := s.metadataFetcher.Fetch(ctx, uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch metadata of token ID %s from %s: %w", tokenID, uri, err)
+	}
+	doc, err := metadata.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse metadata of token ID %s: %w", tokenID, err)
+	}
+
+	meta := models.NFTMetadata{
+		ChainID:         chainID,
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		URI:             uri,
+		Raw:             string(raw),
+		Name:            doc.Name,
+		Description:     doc.Description,
+		Image:           doc.Image,
+		Attributes:      doc.Attributes,
+		FetchedAt:       time.Now(),
+	}
+	err = db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&meta).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save metadata: %v", err)
+	}
+
+	log.Printf("Fetched metadata of token ID %s of %s from %s", tokenID, contractAddress, uri)
+	return &meta, nil
+}
+
+// metadataURI reads the metadata URI of a token with the function of the
+// contract's standard: uri for ERC-1155 contracts, tokenURI otherwise
+func (s *NFTService) metadataURI(ctx context.Context, contractAddress string, tokenID models.TokenID) (string, error) {
+	contract, err := s.contractInfo(ctx, contractAddress, false)
+	if err != nil {
+		return "", err
+	}
+
+	var uri string
+	if contract.Standard == models.StandardERC1155 {
+		uri, err = s.chain.GetURI(ctx, contractAddress, tokenID.Big())
+	} else {
+		if err := s.requireERC721(ctx, contractAddress); err != nil {
+			return "", err
+		}
+		uri, err = s.chain.GetTokenURI(ctx, contractAddress, tokenID.Big())
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get metadata URI from blockchain: %w", chainError(err))
+	}
+	if uri == "" {
+		return "", fmt.Errorf("%w: token ID %s of %s has an empty metadata URI", ErrInvalidMetadata, tokenID, contractAddress)
+	}
+	return uri, nil
+}
@@ -1,132 +1,367 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"time"
 
-	"go-cli-eth/database"
 	"go-cli-eth/ethereum"
+	"go-cli-eth/metadata"
 	"go-cli-eth/models"
 
+	"github.com/ethereum/go-ethereum/common"
 	"gorm.io/gorm"
 )
 
-// NFTService handles NFT operations
+// Repository stores NFTs together with their ownership history, see
+// database.Repository
+type Repository interface {
+	GetNFT(ctx context.Context, chainID uint64, contractAddress string, tokenID models.TokenID) (*models.NFT, error)
+	ListNFTs(ctx context.Context, chainID uint64, contractAddress string) ([]models.NFT, error)
+	UpsertNFT(ctx context.Context, nft *models.NFT) (created bool, changed bool, err error)
+	UpdateOwner(ctx context.Context, chainID uint64, contractAddress string, tokenID models.TokenID, owner string, blockNumber uint64) (*models.NFT, string, error)
+}
+
+// gormRepository is a repository backed by a GORM database, which also holds
+// the records other than NFTs
+type gormRepository interface {
+	DB() *gorm.DB
+}
+
+// ChainReader is the subset of the Ethereum client used by the service
+type ChainReader interface {
+	ChainID() uint64
+	Chain() ethereum.ChainConfig
+	Healthy() bool
+	EndpointStats() []ethereum.EndpointStats
+	SetMulticallAddress(address string) error
+	SetRPCBatchLimit(limit int)
+	ResolveBlock(ctx context.Context, block ethereum.BlockRef) (uint64, error)
+	HasCode(ctx context.Context, address common.Address) (bool, error)
+	DetectInterfaces(ctx context.Context, address common.Address) (ethereum.Interfaces, error)
+	GetOwnerOf(ctx context.Context, contractAddress string, tokenID *big.Int, block ethereum.BlockRef) (string, uint64, error)
+	GetOwnersOf(ctx context.Context, contractAddress string, tokenIDs []*big.Int, block ethereum.BlockRef, batchSize int) ([]ethereum.OwnerResult, uint64, error)
+	GetBalanceOf(ctx context.Context, contractAddress, holder string, tokenID *big.Int, block ethereum.BlockRef) (*big.Int, uint64, error)
+	GetBalancesOf(ctx context.Context, contractAddress string, holders []string, tokenIDs []*big.Int, block ethereum.BlockRef, batchSize int) ([]*big.Int, uint64, error)
+	FilterBalanceTransfers(ctx context.Context, contractAddress string, fromBlock, toBlock uint64) ([]ethereum.BalanceTransfer, error)
+	GetCollectionInfo(ctx context.Context, address common.Address, enumerable bool) (ethereum.CollectionInfo, error)
+	GetRoyaltyInfo(ctx context.Context, contractAddress string, tokenID *big.Int) (ethereum.Royalty, error)
+	GetTokenURI(ctx context.Context, contractAddress string, tokenID *big.Int) (string, error)
+	GetURI(ctx context.Context, contractAddress string, tokenID *big.Int) (string, error)
+	ResolveName(ctx context.Context, name string) (common.Address, error)
+	LookupAddress(ctx context.Context, address common.Address) (string, error)
+}
+
+// NFTService handles NFT operations. NFTs are stored through a repository
+// and read from a chain. Other records (ownership history, caches, balances,
+// collections, metadata and royalties) are kept in the database of the
+// repository; with a repository without one, such as a fake in tests,
+// operations on those records return ErrNoDatabase and caches are skipped.
 type NFTService struct {
-	ethClient *ethereum.EthereumClient
+	repo            Repository
+	db              *gorm.DB
+	chain           ChainReader
+	pool            *ethereum.ClientPool
+	ensCacheTTL     time.Duration
+	metadataFetcher *metadata.Fetcher
+}
+
+// NewNFTService creates a new NFT service storing NFTs in repo and reading
+// them from chain. If repo has a DB method, as database.Repository does, the
+// other records are kept in that database.
+func NewNFTService(repo Repository, chain ChainReader) *NFTService {
+	s := &NFTService{
+		repo:            repo,
+		chain:           chain,
+		ensCacheTTL:     DefaultENSCacheTTL,
+		metadataFetcher: metadata.NewFetcher(metadata.Config{}),
+	}
+	if store, ok := repo.(gormRepository); ok {
+		s.db = store.DB()
+	}
+	return s
 }
 
-// NewNFTService creates a new NFT service
-func NewNFTService(ethClient *ethereum.EthereumClient) *NFTService {
-	return &NFTService{
-		ethClient: ethClient,
+// withDB returns the database of the repository bound to ctx, or
+// ErrNoDatabase if the repository has none
+func (s *NFTService) withDB(ctx context.Context) (*gorm.DB, error) {
+	if s.db == nil {
+		return nil, ErrNoDatabase
 	}
+	return s.db.WithContext(ctx), nil
 }
 
-// GetAndStoreOwner retrieves owner from blockchain and stores in database
-func (s *NFTService) GetAndStoreOwner(contractAddress string, tokenID uint) (*models.NFT, error) {
+// SetMulticallAddress overrides the Multicall3 address used by batched refreshes
+func (s *NFTService) SetMulticallAddress(address string) error {
+	return s.chain.SetMulticallAddress(address)
+}
+
+// SetRPCBatchLimit sets the maximum number of requests per JSON-RPC batch used
+// by batched refreshes on chains without Multicall3
+func (s *NFTService) SetRPCBatchLimit(limit int) {
+	s.chain.SetRPCBatchLimit(limit)
+}
+
+// GetAndStoreOwner retrieves owner from blockchain at the given block and stores in database
+func (s *NFTService) GetAndStoreOwner(ctx context.Context, contractAddress string, tokenID models.TokenID, block ethereum.BlockRef) (*models.NFT, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireERC721(ctx, contractAddress); err != nil {
+		return nil, err
+	}
+	chainID := s.chain.ChainID()
+
 	// Get owner from blockchain
-	owner, err := s.ethClient.GetOwnerOf(contractAddress, tokenID)
+	owner, blockNumber, err := s.fetchOwner(ctx, contractAddress, tokenID, block)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get owner from blockchain: %v", err)
+		return nil, s.handleNonexistent(ctx, contractAddress, tokenID, blockNumber, err)
 	}
 
-	log.Printf("Retrieved owner %s for token ID %d", owner, tokenID)
+	log.Printf("Retrieved owner %s for token ID %s of %s at block %d", owner, tokenID, contractAddress, blockNumber)
 
 	// Check if NFT already exists in database
-	var existingNFT models.NFT
-	db := database.GetDB()
-
-	err = db.Where("token_id = ?", tokenID).First(&existingNFT).Error
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, fmt.Errorf("failed to check existing NFT: %v", err)
+	existingNFT, err := s.repo.GetNFT(ctx, chainID, contractAddress, tokenID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
 	}
 
 	// If NFT exists, return existing record
-	if err != gorm.ErrRecordNotFound {
-		log.Printf("NFT with token ID %d already exists in database", tokenID)
-		return &existingNFT, nil
+	if err == nil {
+		log.Printf("NFT with token ID %s of %s already exists in database", tokenID, contractAddress)
+		existingNFT.OwnerENS = s.lookupOwnerName(ctx, existingNFT.Owner)
+		s.lookupRoyalty(ctx, existingNFT)
+		return existingNFT, nil
 	}
 
-	// Create new NFT record
+	// Save to database together with the first history entry
 	nft := models.NFT{
-		TokenID:   tokenID,
-		Owner:     owner,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ChainID:         chainID,
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		Owner:           owner,
+		BlockNumber:     blockNumber,
 	}
-
-	// Save to database
-	err = db.Create(&nft).Error
-	if err != nil {
-		return nil, fmt.Errorf("failed to save NFT to database: %v", err)
+	if _, _, err := s.repo.UpsertNFT(ctx, &nft); err != nil {
+		return nil, err
 	}
 
-	log.Printf("Successfully stored NFT with token ID %d", tokenID)
+	log.Printf("Successfully stored NFT with token ID %s of %s", tokenID, contractAddress)
+	nft.OwnerENS = s.lookupOwnerName(ctx, nft.Owner)
+	s.lookupRoyalty(ctx, &nft)
 	return &nft, nil
 }
 
-// UpdateOwner updates the owner of an existing NFT
-func (s *NFTService) UpdateOwner(contractAddress string, tokenID uint) (*models.NFT, error) {
+// UpdateOwner updates the owner of an existing NFT with the owner at the given block.
+// Reads at a block older than the one already stored are rejected.
+func (s *NFTService) UpdateOwner(ctx context.Context, contractAddress string, tokenID models.TokenID, block ethereum.BlockRef) (*models.NFT, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireERC721(ctx, contractAddress); err != nil {
+		return nil, err
+	}
+	chainID := s.chain.ChainID()
+
 	// Get current owner from blockchain
-	owner, err := s.ethClient.GetOwnerOf(contractAddress, tokenID)
+	owner, blockNumber, err := s.fetchOwner(ctx, contractAddress, tokenID, block)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get owner from blockchain: %v", err)
+		return nil, s.handleNonexistent(ctx, contractAddress, tokenID, blockNumber, err)
 	}
 
-	log.Printf("Retrieved updated owner %s for token ID %d", owner, tokenID)
+	log.Printf("Retrieved updated owner %s for token ID %s of %s at block %d", owner, tokenID, contractAddress, blockNumber)
 
-	// Update in database
-	db := database.GetDB()
-	var nft models.NFT
+	// Update the owner, block and timestamp, appending to the history if it
+	// changed. A newer snapshot is never overwritten with an older one.
+	nft, previousOwner, err := s.repo.UpdateOwner(ctx, chainID, contractAddress, tokenID, owner, blockNumber)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			return nil, fmt.Errorf("NFT with token ID %s of %s %w in database", tokenID, contractAddress, ErrNotFound)
+		case errors.Is(err, ErrStaleBlock):
+			return nil, fmt.Errorf("%w of token ID %s of %s", err, tokenID, contractAddress)
+		}
+		return nil, err
+	}
+	if previousOwner != owner {
+		log.Printf("Owner of token ID %s of %s changed from %s to %s", tokenID, contractAddress, previousOwner, owner)
+	}
 
-	err = db.Where("token_id = ?", tokenID).First(&nft).Error
+	log.Printf("Successfully updated NFT with token ID %s of %s", tokenID, contractAddress)
+	nft.OwnerENS = s.lookupOwnerName(ctx, nft.Owner)
+	s.lookupRoyalty(ctx, nft)
+	return nft, nil
+}
+
+// GetNFTByTokenID retrieves an NFT of the given contract by token ID from
+// database along with its stored royalty. The royalty is not read from the
+// chain; owner reads and royalty refreshes store it.
+func (s *NFTService) GetNFTByTokenID(ctx context.Context, contractAddress string, tokenID models.TokenID) (*models.NFT, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("NFT with token ID %d not found in database", tokenID)
+		return nil, err
+	}
+	nft, err := s.repo.GetNFT(ctx, s.chain.ChainID(), contractAddress, tokenID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("NFT with token ID %s of %s %w", tokenID, contractAddress, ErrNotFound)
+		}
+		return nil, err
+	}
+
+	nfts := []models.NFT{*nft}
+	if err := s.storedRoyalties(ctx, nfts); err != nil {
+		return nil, err
+	}
+	nfts[0].OwnerENS = s.lookupOwnerName(ctx, nfts[0].Owner)
+	return &nfts[0], nil
+}
+
+// GetAllNFTs retrieves all NFTs on the connected chain from database.
+// If contractAddress is not empty only NFTs of that contract are returned.
+func (s *NFTService) GetAllNFTs(ctx context.Context, contractAddress string) ([]models.NFT, error) {
+	if contractAddress != "" {
+		normalized, err := s.resolveAddress(ctx, contractAddress)
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to find NFT: %v", err)
+		contractAddress = normalized
 	}
 
-	// Update the owner and timestamp
-	nft.Owner = owner
-	nft.UpdatedAt = time.Now()
+	nfts, err := s.repo.ListNFTs(ctx, s.chain.ChainID(), contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storedRoyalties(ctx, nfts); err != nil {
+		return nil, err
+	}
+	return nfts, nil
+}
 
-	err = db.Save(&nft).Error
+// GetOwnershipHistory retrieves the recorded ownership changes of an NFT, newest first
+func (s *NFTService) GetOwnershipHistory(ctx context.Context, contractAddress string, tokenID models.TokenID) ([]models.OwnershipHistory, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update NFT: %v", err)
+		return nil, err
 	}
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var history []models.OwnershipHistory
 
-	log.Printf("Successfully updated NFT with token ID %d", tokenID)
-	return &nft, nil
+	err = whereNFT(db, s.chain.ChainID(), contractAddress, tokenID).
+		Order("observed_at DESC, id DESC").
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ownership history: %v", err)
+	}
+
+	return history, nil
 }
 
-// GetNFTByTokenID retrieves an NFT by token ID from database
-func (s *NFTService) GetNFTByTokenID(tokenID uint) (*models.NFT, error) {
-	db := database.GetDB()
-	var nft models.NFT
+// GetOwnerAt retrieves the ownership record that was current at the given time
+func (s *NFTService) GetOwnerAt(ctx context.Context, contractAddress string, tokenID models.TokenID, at time.Time) (*models.OwnershipHistory, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var entry models.OwnershipHistory
 
-	err := db.Where("token_id = ?", tokenID).First(&nft).Error
+	err = whereNFT(db, s.chain.ChainID(), contractAddress, tokenID).
+		Where("observed_at <= ?", at).
+		Order("observed_at DESC, id DESC").
+		First(&entry).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("NFT with token ID %d not found", tokenID)
+			return nil, fmt.Errorf("owner of token ID %s of %s at %s %w", tokenID, contractAddress, at.Format(time.RFC3339), ErrNotFound)
 		}
-		return nil, fmt.Errorf("failed to get NFT: %v", err)
+		return nil, fmt.Errorf("failed to get ownership history: %v", err)
 	}
 
-	return &nft, nil
+	return &entry, nil
 }
 
-// GetAllNFTs retrieves all NFTs from database
-func (s *NFTService) GetAllNFTs() ([]models.NFT, error) {
-	db := database.GetDB()
-	var nfts []models.NFT
+// fetchOwner reads the owner of a token at the given block along with the
+// concrete block number it was read at. The block number is also returned if
+// the ownerOf call reverted.
+func (s *NFTService) fetchOwner(ctx context.Context, contractAddress string, tokenID models.TokenID, block ethereum.BlockRef) (string, uint64, error) {
+	owner, blockNumber, err := s.chain.GetOwnerOf(ctx, contractAddress, tokenID.Big(), block)
+	if err != nil {
+		return "", blockNumber, fmt.Errorf("failed to get owner from blockchain: %w", chainError(err))
+	}
 
-	err := db.Find(&nfts).Error
+	return owner, blockNumber, nil
+}
+
+// handleNonexistent inspects an error returned by fetchOwner. If the token
+// does not exist at blockNumber but is stored with an owner read at or before
+// that block, it has been burned since: the stored NFT is marked as burned and
+// an error wrapping ErrTokenBurned is returned. Any other error is returned
+// unchanged.
+func (s *NFTService) handleNonexistent(ctx context.Context, contractAddress string, tokenID models.TokenID, blockNumber uint64, err error) error {
+	if !ethereum.IsNonexistentToken(err) {
+		return err
+	}
+
+	burned, changed, burnErr := s.burnStoredNFT(ctx, contractAddress, tokenID, blockNumber)
+	if burnErr != nil {
+		return burnErr
+	}
+	if !burned {
+		return err
+	}
+
+	if changed {
+		log.Printf("Token ID %s of %s no longer exists at block %d, marked as burned", tokenID, contractAddress, blockNumber)
+	}
+	return fmt.Errorf("token ID %s of %s: %w", tokenID, contractAddress, ErrTokenBurned)
+}
+
+// burnStoredNFT marks a stored NFT as burned at blockNumber, which appends the
+// burn to its ownership history. burned reports whether the NFT is stored and
+// burned afterwards; it is false if the NFT is not stored or its owner was
+// read at a newer block. changed reports whether the NFT had not been marked
+// as burned before.
+func (s *NFTService) burnStoredNFT(ctx context.Context, contractAddress string, tokenID models.TokenID, blockNumber uint64) (burned bool, changed bool, err error) {
+	// the token may simply not have been minted yet at an older block
+	_, previousOwner, err := s.repo.UpdateOwner(ctx, s.chain.ChainID(), contractAddress, tokenID, models.BurnedOwner, blockNumber)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrStaleBlock) {
+		return false, false, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get NFTs: %v", err)
+		return false, false, fmt.Errorf("failed to mark NFT as burned: %v", err)
 	}
 
-	return nfts, nil
+	return true, previousOwner != models.BurnedOwner, nil
+}
+
+// whereNFT scopes a query to a single NFT identified by chain, contract and token
+func whereNFT(db *gorm.DB, chainID uint64, contractAddress string, tokenID models.TokenID) *gorm.DB {
+	return db.Where("chain_id = ? AND contract_address = ? AND token_id = ?", chainID, contractAddress, tokenID)
+}
+
+// normalizeAddress returns the checksummed form of an address so that lookups
+// are independent of the casing used by the caller. Malformed addresses are
+// rejected and mixed-case addresses with a wrong EIP-55 checksum are logged,
+// since they are likely mistyped.
+func normalizeAddress(address string) (string, error) {
+	parsed, err := ethereum.ParseAddress(address)
+	if err != nil {
+		return "", err
+	}
+	if !ethereum.HasValidChecksum(address) {
+		log.Printf("Warning: %s does not match its EIP-55 checksum %s", address, parsed.Hex())
+	}
+	return parsed.Hex(), nil
 }
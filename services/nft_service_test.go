@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	testContract = "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"
+	alice        = "0x1111111111111111111111111111111111111111"
+	bob          = "0x2222222222222222222222222222222222222222"
+)
+
+// fakeRepository stores NFTs in memory
+type fakeRepository struct {
+	nfts map[string]models.NFT
+}
+
+func newFakeRepository() *fakeRepository {
+	return &fakeRepository{nfts: make(map[string]models.NFT)}
+}
+
+func nftKey(chainID uint64, contractAddress string, tokenID models.TokenID) string {
+	return fmt.Sprintf("%d/%s/%s", chainID, contractAddress, tokenID)
+}
+
+func (r *fakeRepository) GetNFT(ctx context.Context, chainID uint64, contractAddress string, tokenID models.TokenID) (*models.NFT, error) {
+	nft, ok := r.nfts[nftKey(chainID, contractAddress, tokenID)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &nft, nil
+}
+
+func (r *fakeRepository) ListNFTs(ctx context.Context, chainID uint64, contractAddress string) ([]models.NFT, error) {
+	var nfts []models.NFT
+	for _, nft := range r.nfts {
+		if nft.ChainID == chainID && (contractAddress == "" || nft.ContractAddress == contractAddress) {
+			nfts = append(nfts, nft)
+		}
+	}
+	return nfts, nil
+}
+
+func (r *fakeRepository) UpsertNFT(ctx context.Context, nft *models.NFT) (bool, bool, error) {
+	key := nftKey(nft.ChainID, nft.ContractAddress, nft.TokenID)
+	stored, ok := r.nfts[key]
+	if !ok {
+		r.nfts[key] = *nft
+		return true, false, nil
+	}
+	if nft.BlockNumber < stored.BlockNumber {
+		*nft = stored
+		return false, false, nil
+	}
+	changed := stored.Owner != nft.Owner
+	r.nfts[key] = *nft
+	return false, changed, nil
+}
+
+func (r *fakeRepository) UpdateOwner(ctx context.Context, chainID uint64, contractAddress string, tokenID models.TokenID, owner string, blockNumber uint64) (*models.NFT, string, error) {
+	key := nftKey(chainID, contractAddress, tokenID)
+	nft, ok := r.nfts[key]
+	if !ok {
+		return nil, "", ErrNotFound
+	}
+	if blockNumber < nft.BlockNumber {
+		return nil, "", fmt.Errorf("%w: block %d is older than the stored block %d", ErrStaleBlock, blockNumber, nft.BlockNumber)
+	}
+	previousOwner := nft.Owner
+	nft.Owner = owner
+	nft.BlockNumber = blockNumber
+	r.nfts[key] = nft
+	return &nft, previousOwner, nil
+}
+
+// fakeChain serves the owners of an ERC-721 contract at a fixed block. Tokens
+// without an owner do not exist. If royalty is set the contract advertises
+// ERC-2981 and every token has that royalty.
+type fakeChain struct {
+	ChainReader
+	block  uint64
+	owners map[string]string
+
+	royalty      *ethereum.Royalty
+	royaltyCalls int
+}
+
+func (c *fakeChain) ChainID() uint64 { return 1 }
+
+func (c *fakeChain) HasCode(ctx context.Context, address common.Address) (bool, error) {
+	return true, nil
+}
+
+func (c *fakeChain) DetectInterfaces(ctx context.Context, address common.Address) (ethereum.Interfaces, error) {
+	return ethereum.Interfaces{ERC165: true, ERC721: true, ERC2981: c.royalty != nil}, nil
+}
+
+func (c *fakeChain) GetRoyaltyInfo(ctx context.Context, contractAddress string, tokenID *big.Int) (ethereum.Royalty, error) {
+	c.royaltyCalls++
+	return *c.royalty, nil
+}
+
+func (c *fakeChain) LookupAddress(ctx context.Context, address common.Address) (string, error) {
+	return "", ethereum.ErrENSNotFound
+}
+
+func (c *fakeChain) GetOwnerOf(ctx context.Context, contractAddress string, tokenID *big.Int, block ethereum.BlockRef) (string, uint64, error) {
+	owner, ok := c.owners[tokenID.String()]
+	if !ok {
+		return "", c.block, &ethereum.NonexistentTokenError{TokenID: tokenID}
+	}
+	return owner, c.block, nil
+}
+
+func (c *fakeChain) GetOwnersOf(ctx context.Context, contractAddress string, tokenIDs []*big.Int, block ethereum.BlockRef, batchSize int) ([]ethereum.OwnerResult, uint64, error) {
+	results := make([]ethereum.OwnerResult, 0, len(tokenIDs))
+	for _, tokenID := range tokenIDs {
+		owner, _, err := c.GetOwnerOf(ctx, contractAddress, tokenID, block)
+		results = append(results, ethereum.OwnerResult{TokenID: tokenID, Owner: owner, Err: err})
+	}
+	return results, c.block, nil
+}
+
+func newTestService(block uint64, owners map[string]string) (*NFTService, *fakeRepository, *fakeChain) {
+	repo := newFakeRepository()
+	chain := &fakeChain{block: block, owners: owners}
+	return NewNFTService(repo, chain), repo, chain
+}
+
+func TestGetAndStoreOwner(t *testing.T) {
+	s, repo, chain := newTestService(10, map[string]string{"1": alice})
+	ctx := context.Background()
+	tokenID := models.TokenIDFromUint64(1)
+
+	nft, err := s.GetAndStoreOwner(ctx, testContract, tokenID, ethereum.BlockRef{})
+	if err != nil {
+		t.Fatalf("GetAndStoreOwner() error: %v", err)
+	}
+	if nft.Owner != alice || nft.BlockNumber != 10 {
+		t.Errorf("GetAndStoreOwner() = %s at %d, want %s at 10", nft.Owner, nft.BlockNumber, alice)
+	}
+	if _, err := repo.GetNFT(ctx, 1, testContract, tokenID); err != nil {
+		t.Errorf("NFT not stored: %v", err)
+	}
+
+	// a stored NFT is returned as is
+	chain.owners["1"] = bob
+	chain.block = 11
+	nft, err = s.GetAndStoreOwner(ctx, testContract, tokenID, ethereum.BlockRef{})
+	if err != nil {
+		t.Fatalf("GetAndStoreOwner() error: %v", err)
+	}
+	if nft.Owner != alice || nft.BlockNumber != 10 {
+		t.Errorf("GetAndStoreOwner() of a stored NFT = %s at %d, want %s at 10", nft.Owner, nft.BlockNumber, alice)
+	}
+}
+
+func TestUpdateOwner(t *testing.T) {
+	s, repo, chain := newTestService(10, map[string]string{"1": bob})
+	ctx := context.Background()
+	tokenID := models.TokenIDFromUint64(1)
+
+	if _, err := s.UpdateOwner(ctx, testContract, tokenID, ethereum.BlockRef{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UpdateOwner() of a missing NFT error = %v, want ErrNotFound", err)
+	}
+
+	repo.nfts[nftKey(1, testContract, tokenID)] = models.NFT{ChainID: 1, ContractAddress: testContract, TokenID: tokenID, Owner: alice, BlockNumber: 12}
+	if _, err := s.UpdateOwner(ctx, testContract, tokenID, ethereum.BlockRef{}); !errors.Is(err, ErrStaleBlock) {
+		t.Fatalf("UpdateOwner() at an older block error = %v, want ErrStaleBlock", err)
+	}
+
+	chain.block = 15
+	nft, err := s.UpdateOwner(ctx, testContract, tokenID, ethereum.BlockRef{})
+	if err != nil {
+		t.Fatalf("UpdateOwner() error: %v", err)
+	}
+	if nft.Owner != bob || nft.BlockNumber != 15 {
+		t.Errorf("UpdateOwner() = %s at %d, want %s at 15", nft.Owner, nft.BlockNumber, bob)
+	}
+}
+
+func TestUpdateOwnerBurned(t *testing.T) {
+	s, repo, _ := newTestService(10, map[string]string{})
+	ctx := context.Background()
+	tokenID := models.TokenIDFromUint64(1)
+
+	// a token that was never stored simply does not exist
+	_, err := s.UpdateOwner(ctx, testContract, tokenID, ethereum.BlockRef{})
+	if !ethereum.IsNonexistentToken(err) || errors.Is(err, ErrTokenBurned) {
+		t.Fatalf("UpdateOwner() of an unknown token error = %v, want nonexistent token", err)
+	}
+
+	repo.nfts[nftKey(1, testContract, tokenID)] = models.NFT{ChainID: 1, ContractAddress: testContract, TokenID: tokenID, Owner: alice, BlockNumber: 5}
+	if _, err := s.UpdateOwner(ctx, testContract, tokenID, ethereum.BlockRef{}); !errors.Is(err, ErrTokenBurned) {
+		t.Fatalf("UpdateOwner() of a burned token error = %v, want ErrTokenBurned", err)
+	}
+	if nft := repo.nfts[nftKey(1, testContract, tokenID)]; !nft.IsBurned() || nft.BlockNumber != 10 {
+		t.Errorf("stored NFT = %s at %d, want burned at 10", nft.Owner, nft.BlockNumber)
+	}
+}
+
+func TestRefreshRange(t *testing.T) {
+	s, repo, _ := newTestService(20, map[string]string{"1": alice, "2": bob, "3": alice})
+	ctx := context.Background()
+
+	for id, owner := range map[uint64]string{2: alice, 3: alice, 4: bob} {
+		tokenID := models.TokenIDFromUint64(id)
+		repo.nfts[nftKey(1, testContract, tokenID)] = models.NFT{ChainID: 1, ContractAddress: testContract, TokenID: tokenID, Owner: owner, BlockNumber: 10}
+	}
+
+	result, err := s.RefreshRange(ctx, testContract, models.TokenIDFromUint64(1), models.TokenIDFromUint64(5), ethereum.BlockRef{}, 10)
+	if err != nil {
+		t.Fatalf("RefreshRange() error: %v", err)
+	}
+	// 1 is new, 2 changed owner, 3 kept its owner, 4 was burned and 5 never existed
+	if result.Created != 1 || result.Updated != 1 || result.Unchanged != 1 || result.Burned != 1 || len(result.Failed) != 1 {
+		t.Errorf("RefreshRange() = %+v, want 1 created, updated, unchanged, burned and failed", result)
+	}
+	if result.BlockNumber != 20 {
+		t.Errorf("block number = %d, want 20", result.BlockNumber)
+	}
+}
+
+func TestWithoutDatabase(t *testing.T) {
+	s, _, _ := newTestService(10, map[string]string{"1": alice})
+	ctx := context.Background()
+	tokenID := models.TokenIDFromUint64(1)
+
+	if _, err := s.GetOwnershipHistory(ctx, testContract, tokenID); !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("GetOwnershipHistory() error = %v, want ErrNoDatabase", err)
+	}
+	if _, err := s.GetBalances(ctx, testContract, nil, ""); !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("GetBalances() error = %v, want ErrNoDatabase", err)
+	}
+	if _, err := s.GetCollections(ctx); !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("GetCollections() error = %v, want ErrNoDatabase", err)
+	}
+	if _, err := s.GetMetadata(ctx, testContract, tokenID, false); !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("GetMetadata() error = %v, want ErrNoDatabase", err)
+	}
+	if _, err := s.GetRoyalties(ctx, testContract, false); !errors.Is(err, ErrNoDatabase) {
+		t.Errorf("GetRoyalties() error = %v, want ErrNoDatabase", err)
+	}
+
+	// NFT operations do not need the database
+	if _, err := s.GetAndStoreOwner(ctx, testContract, tokenID, ethereum.BlockRef{}); err != nil {
+		t.Errorf("GetAndStoreOwner() error: %v", err)
+	}
+	if _, err := s.GetAllNFTs(ctx, testContract); err != nil {
+		t.Errorf("GetAllNFTs() error: %v", err)
+	}
+}
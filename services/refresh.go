@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+)
+
+// maxRefreshTokens bounds the number of tokens a single refresh may cover
+const maxRefreshTokens = 100000
+
+// RefreshFailure describes a token whose owner could not be read
+type RefreshFailure struct {
+	TokenID models.TokenID `json:"token_id"`
+	Error   string         `json:"error"`
+}
+
+// RefreshResult summarizes a batched ownership refresh
+type RefreshResult struct {
+	ContractAddress string           `json:"contract_address"`
+	BlockNumber     uint64           `json:"block_number"`
+	Created         int              `json:"created"`
+	Updated         int              `json:"updated"`
+	Unchanged       int              `json:"unchanged"`
+	Burned          int              `json:"burned"`
+	Failed          []RefreshFailure `json:"failed"`
+}
+
+// RefreshRange reads the owners of every token ID from from to to (inclusive)
+// in batches of batchSize ownerOf calls and stores them. Tokens that are not
+// stored yet are created.
+func (s *NFTService) RefreshRange(ctx context.Context, contractAddress string, from, to models.TokenID, block ethereum.BlockRef, batchSize int) (*RefreshResult, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if to.Cmp(from) < 0 {
+		return nil, fmt.Errorf("%w %s-%s", ErrInvalidTokenRange, from, to)
+	}
+
+	count := new(big.Int).Sub(to.Big(), from.Big())
+	if count.Cmp(big.NewInt(maxRefreshTokens)) >= 0 {
+		return nil, fmt.Errorf("%w %s-%s: exceeds the limit of %d tokens", ErrInvalidTokenRange, from, to, maxRefreshTokens)
+	}
+
+	tokenIDs := make([]*big.Int, 0, count.Int64()+1)
+	for id := from.Big(); id.Cmp(to.Big()) <= 0; id = new(big.Int).Add(id, big.NewInt(1)) {
+		tokenIDs = append(tokenIDs, id)
+	}
+
+	return s.refreshTokens(ctx, contractAddress, tokenIDs, block, batchSize)
+}
+
+// RefreshCollection reads the owners of every stored token of a contract in
+// batches of batchSize ownerOf calls and updates them
+func (s *NFTService) RefreshCollection(ctx context.Context, contractAddress string, block ethereum.BlockRef, batchSize int) (*RefreshResult, error) {
+	contractAddress, err := s.resolveAddress(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	nfts, err := s.GetAllNFTs(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(nfts) == 0 {
+		return nil, fmt.Errorf("NFTs of %s %w in database", contractAddress, ErrNotFound)
+	}
+
+	tokenIDs := make([]*big.Int, 0, len(nfts))
+	for _, nft := range nfts {
+		tokenIDs = append(tokenIDs, nft.TokenID.Big())
+	}
+
+	return s.refreshTokens(ctx, contractAddress, tokenIDs, block, batchSize)
+}
+
+// refreshTokens reads the owners of tokenIDs in batches and stores them
+func (s *NFTService) refreshTokens(ctx context.Context, contractAddress string, tokenIDs []*big.Int, block ethereum.BlockRef, batchSize int) (*RefreshResult, error) {
+	if err := s.requireERC721(ctx, contractAddress); err != nil {
+		return nil, err
+	}
+
+	owners, blockNumber, err := s.chain.GetOwnersOf(ctx, contractAddress, tokenIDs, block, batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get owners from blockchain: %w", chainError(err))
+	}
+
+	log.Printf("Retrieved %d owner(s) of %s at block %d", len(owners), contractAddress, blockNumber)
+
+	result := &RefreshResult{
+		ContractAddress: contractAddress,
+		BlockNumber:     blockNumber,
+		Failed:          []RefreshFailure{},
+	}
+
+	chainID := s.chain.ChainID()
+
+	for _, owner := range owners {
+		tokenID, err := models.NewTokenID(owner.TokenID)
+		if err != nil {
+			return nil, err
+		}
+
+		if owner.Err != nil {
+			// stored tokens that no longer exist have been burned
+			if ethereum.IsNonexistentToken(owner.Err) {
+				burned, changed, err := s.burnStoredNFT(ctx, contractAddress, tokenID, blockNumber)
+				if err != nil {
+					return nil, fmt.Errorf("failed to store owner of token ID %s: %v", tokenID, err)
+				}
+				if changed {
+					result.Burned++
+					continue
+				}
+				if burned {
+					result.Unchanged++
+					continue
+				}
+			}
+			result.Failed = append(result.Failed, RefreshFailure{TokenID: tokenID, Error: owner.Err.Error()})
+			continue
+		}
+
+		nft := models.NFT{
+			ChainID:         chainID,
+			ContractAddress: contractAddress,
+			TokenID:         tokenID,
+			Owner:           owner.Owner,
+			BlockNumber:     blockNumber,
+		}
+		created, changed, err := s.repo.UpsertNFT(ctx, &nft)
+		if err != nil {
+			return nil, fmt.Errorf("failed to store owner of token ID %s: %w", tokenID, err)
+		}
+		switch {
+		case created:
+			result.Created++
+		case changed:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+	}
+
+	log.Printf("Refreshed %s at block %d: %d created, %d updated, %d unchanged, %d burned, %d failed",
+		contractAddress, blockNumber, result.Created, result.Updated, result.Unchanged, result.Burned, len(result.Failed))
+	return result, nil
+}
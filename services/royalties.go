@@ -0,0 +1,278 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"go-cli-eth/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoyaltyReport summarizes the stored ERC-2981 royalties of the tracked
+// tokens of a collection. Receiver and BasisPoints are the royalty most
+// tokens share; Overrides lists the tokens whose royalty differs from it.
+type RoyaltyReport struct {
+	ContractAddress string           `json:"contract_address"`
+	Receiver        string           `json:"receiver,omitempty"`
+	BasisPoints     *uint32          `json:"basis_points,omitempty"`
+	Tokens          int              `json:"tokens"`
+	Overrides       []models.Royalty `json:"overrides"`
+	Failed          []RefreshFailure `json:"failed,omitempty"`
+}
+
+// GetRoyalties reports the royalty configuration of a registered collection
+// from the stored royalties. If refresh is set royaltyInfo is read for every
+// tracked token of the collection first, one call per token; tokens whose
+// read fails are reported without failing the refresh.
+func (s *NFTService) GetRoyalties(ctx context.Context, contractAddress string, refresh bool) (*RoyaltyReport, error) {
+	collection, err := s.GetCollection(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	contractAddress = collection.Address
+
+	var failed []RefreshFailure
+	if refresh {
+		if failed, err = s.refreshRoyalties(ctx, contractAddress); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var royalties []models.Royalty
+	err = db.
+		Where("chain_id = ? AND contract_address = ?", s.chain.ChainID(), contractAddress).
+		Order("token_id").
+		Find(&royalties).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get royalties: %v", err)
+	}
+
+	report := &RoyaltyReport{
+		ContractAddress: contractAddress,
+		Tokens:          len(royalties),
+		Overrides:       []models.Royalty{},
+		Failed:          failed,
+	}
+	if len(royalties) == 0 {
+		return report, nil
+	}
+
+	shared := commonRoyalty(royalties)
+	report.Receiver = shared.Receiver
+	report.BasisPoints = &shared.BasisPoints
+	for _, royalty := range royalties {
+		if royalty.Receiver != shared.Receiver || royalty.BasisPoints != shared.BasisPoints {
+			report.Overrides = append(report.Overrides, royalty)
+		}
+	}
+
+	if refresh {
+		err = db.Model(&models.Collection{}).
+			Where("chain_id = ? AND address = ?", s.chain.ChainID(), contractAddress).
+			Updates(map[string]interface{}{
+				"royalty_receiver":     shared.Receiver,
+				"royalty_basis_points": shared.BasisPoints,
+				"updated_at":           time.Now(),
+			}).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to save collection royalty: %v", err)
+		}
+	}
+
+	return report, nil
+}
+
+// refreshRoyalties reads and stores the royalty of every tracked token of a
+// contract and returns the tokens whose read failed
+func (s *NFTService) refreshRoyalties(ctx context.Context, contractAddress string) ([]RefreshFailure, error) {
+	if err := s.requireERC2981(ctx, contractAddress); err != nil {
+		return nil, err
+	}
+
+	tokenIDs, err := s.trackedTokenIDs(ctx, contractAddress)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokenIDs) == 0 {
+		return nil, fmt.Errorf("tokens of %s %w in database", contractAddress, ErrNotFound)
+	}
+
+	failed := []RefreshFailure{}
+	for _, tokenID := range tokenIDs {
+		if _, err := s.fetchRoyalty(ctx, contractAddress, tokenID); err != nil {
+			failed = append(failed, RefreshFailure{TokenID: tokenID, Error: err.Error()})
+		}
+	}
+
+	log.Printf("Refreshed royalties of %d token(s) of %s, %d failed", len(tokenIDs)-len(failed), contractAddress, len(failed))
+	return failed, nil
+}
+
+// fetchRoyalty reads the royalty of a token from the chain and stores it
+func (s *NFTService) fetchRoyalty(ctx context.Context, contractAddress string, tokenID models.TokenID) (*models.Royalty, error) {
+	info, err := s.chain.GetRoyaltyInfo(ctx, contractAddress, tokenID.Big())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get royalty from blockchain: %w", chainError(err))
+	}
+
+	royalty := models.Royalty{
+		ChainID:         s.chain.ChainID(),
+		ContractAddress: contractAddress,
+		TokenID:         tokenID,
+		Receiver:        info.Receiver.Hex(),
+		BasisPoints:     info.BasisPoints,
+		FetchedAt:       time.Now(),
+	}
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&royalty).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save royalty: %v", err)
+	}
+	return &royalty, nil
+}
+
+// lookupRoyalty fills in the royalty of a single NFT whose owner was just
+// read, reading and storing it if it is not stored yet and the contract
+// advertises ERC-2981. Lookups are best effort: failures are logged and leave
+// the royalty empty, as does a missing database.
+func (s *NFTService) lookupRoyalty(ctx context.Context, nft *models.NFT) {
+	if nft.IsBurned() || s.db == nil {
+		return
+	}
+
+	var royalty models.Royalty
+	err := whereNFT(s.db.WithContext(ctx), nft.ChainID, nft.ContractAddress, nft.TokenID).First(&royalty).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		log.Printf("Failed to get royalty of token ID %s of %s: %v", nft.TokenID, nft.ContractAddress, err)
+		return
+	}
+
+	if err == gorm.ErrRecordNotFound {
+		contract, err := s.contractInfo(ctx, nft.ContractAddress, false)
+		if err != nil || !contract.ERC2981 {
+			return
+		}
+		fetched, err := s.fetchRoyalty(ctx, nft.ContractAddress, nft.TokenID)
+		if err != nil {
+			log.Printf("Failed to get royalty of token ID %s of %s: %v", nft.TokenID, nft.ContractAddress, err)
+			return
+		}
+		royalty = *fetched
+	}
+
+	nft.RoyaltyReceiver = royalty.Receiver
+	nft.RoyaltyBasisPoints = &royalty.BasisPoints
+}
+
+// storedRoyalties fills in the stored royalties of nfts without reading any
+// from the chain. Without a database nfts are left as is.
+func (s *NFTService) storedRoyalties(ctx context.Context, nfts []models.NFT) error {
+	if len(nfts) == 0 || s.db == nil {
+		return nil
+	}
+
+	contracts := make(map[string]bool)
+	addresses := make([]string, 0)
+	for _, nft := range nfts {
+		if !contracts[nft.ContractAddress] {
+			contracts[nft.ContractAddress] = true
+			addresses = append(addresses, nft.ContractAddress)
+		}
+	}
+
+	var royalties []models.Royalty
+	err := s.db.WithContext(ctx).
+		Where("chain_id = ? AND contract_address IN ?", s.chain.ChainID(), addresses).
+		Find(&royalties).Error
+	if err != nil {
+		return fmt.Errorf("failed to get royalties: %v", err)
+	}
+
+	byToken := make(map[string]models.Royalty, len(royalties))
+	for _, royalty := range royalties {
+		byToken[royalty.ContractAddress+"/"+royalty.TokenID.String()] = royalty
+	}
+	for i := range nfts {
+		royalty, ok := byToken[nfts[i].ContractAddress+"/"+nfts[i].TokenID.String()]
+		if !ok || nfts[i].IsBurned() {
+			continue
+		}
+		nfts[i].RoyaltyReceiver = royalty.Receiver
+		nfts[i].RoyaltyBasisPoints = &royalty.BasisPoints
+	}
+	return nil
+}
+
+// requireERC2981 rejects contracts that do not advertise ERC-2981
+func (s *NFTService) requireERC2981(ctx context.Context, contractAddress string) error {
+	contract, err := s.contractInfo(ctx, contractAddress, false)
+	if err != nil {
+		return err
+	}
+	if !contract.ERC2981 {
+		return fmt.Errorf("%w: %s does not advertise ERC-2981 through ERC-165", ErrNotERC2981, contractAddress)
+	}
+	return nil
+}
+
+// trackedTokenIDs returns the token IDs of a contract that are tracked: stored
+// NFTs that are not burned and ERC-1155 tokens with stored holders
+func (s *NFTService) trackedTokenIDs(ctx context.Context, contractAddress string) ([]models.TokenID, error) {
+	db, err := s.withDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chainID := s.chain.ChainID()
+
+	var nftIDs, balanceIDs []models.TokenID
+	err = db.Model(&models.NFT{}).
+		Where("chain_id = ? AND contract_address = ? AND owner <> ?", chainID, contractAddress, models.BurnedOwner).
+		Order("token_id").
+		Pluck("token_id", &nftIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked tokens: %v", err)
+	}
+	err = db.Model(&models.TokenBalance{}).
+		Where("chain_id = ? AND contract_address = ?", chainID, contractAddress).
+		Distinct("token_id").
+		Order("token_id").
+		Pluck("token_id", &balanceIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tracked tokens: %v", err)
+	}
+
+	return append(nftIDs, balanceIDs...), nil
+}
+
+// commonRoyalty returns the royalty shared by most tokens. Ties go to the
+// royalty of the lowest token ID since royalties are ordered by token ID.
+func commonRoyalty(royalties []models.Royalty) models.Royalty {
+	type key struct {
+		receiver    string
+		basisPoints uint32
+	}
+
+	counts := make(map[key]int)
+	for _, royalty := range royalties {
+		counts[key{royalty.Receiver, royalty.BasisPoints}]++
+	}
+
+	best := royalties[0]
+	for _, royalty := range royalties {
+		if counts[key{royalty.Receiver, royalty.BasisPoints}] > counts[key{best.Receiver, best.BasisPoints}] {
+			best = royalty
+		}
+	}
+	return best
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go-cli-eth/database"
+	"go-cli-eth/ethereum"
+	"go-cli-eth/models"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newDatabaseService creates a service storing everything in a fresh SQLite
+// database
+func newDatabaseService(t *testing.T, chain *fakeChain) (*NFTService, *gorm.DB) {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "services.db")), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	err = db.AutoMigrate(&models.NFT{}, &models.OwnershipHistory{}, &models.Contract{}, &models.Royalty{}, &models.ENSName{})
+	if err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	repo := database.NewRepository(db)
+	t.Cleanup(func() { repo.Close() })
+	return NewNFTService(repo, chain), db
+}
+
+func TestGetNFTByTokenIDUsesStoredRoyalty(t *testing.T) {
+	chain := &fakeChain{
+		block:   10,
+		owners:  map[string]string{"1": alice},
+		royalty: &ethereum.Royalty{Receiver: common.HexToAddress(bob), BasisPoints: 500},
+	}
+	s, db := newDatabaseService(t, chain)
+	ctx := context.Background()
+	tokenID := models.TokenIDFromUint64(1)
+
+	nft := models.NFT{ChainID: 1, ContractAddress: testContract, TokenID: tokenID, Owner: alice, BlockNumber: 10}
+	if _, _, err := s.repo.UpsertNFT(ctx, &nft); err != nil {
+		t.Fatalf("UpsertNFT() error: %v", err)
+	}
+
+	// showing a stored NFT neither reads nor stores its royalty
+	shown, err := s.GetNFTByTokenID(ctx, testContract, tokenID)
+	if err != nil {
+		t.Fatalf("GetNFTByTokenID() error: %v", err)
+	}
+	if shown.RoyaltyBasisPoints != nil || chain.royaltyCalls != 0 {
+		t.Errorf("GetNFTByTokenID() read the royalty from the chain (%d calls)", chain.royaltyCalls)
+	}
+	var stored int64
+	db.Model(&models.Royalty{}).Count(&stored)
+	if stored != 0 {
+		t.Errorf("GetNFTByTokenID() stored %d royalties, want 0", stored)
+	}
+
+	// reading the owner reads and stores the royalty
+	if _, err := s.GetAndStoreOwner(ctx, testContract, tokenID, ethereum.BlockRef{}); err != nil {
+		t.Fatalf("GetAndStoreOwner() error: %v", err)
+	}
+	if chain.royaltyCalls != 1 {
+		t.Fatalf("GetAndStoreOwner() made %d royalty calls, want 1", chain.royaltyCalls)
+	}
+
+	shown, err = s.GetNFTByTokenID(ctx, testContract, tokenID)
+	if err != nil {
+		t.Fatalf("GetNFTByTokenID() error: %v", err)
+	}
+	if shown.RoyaltyReceiver != common.HexToAddress(bob).Hex() || shown.RoyaltyBasisPoints == nil || *shown.RoyaltyBasisPoints != 500 {
+		t.Errorf("GetNFTByTokenID() royalty = %s %v, want the stored royalty", shown.RoyaltyReceiver, shown.RoyaltyBasisPoints)
+	}
+	if chain.royaltyCalls != 1 {
+		t.Errorf("GetNFTByTokenID() made royalty calls, want the stored royalty only")
+	}
+}